@@ -0,0 +1,190 @@
+// Package calculator 는 신체 정보로부터 기초대사량(BMR), 활동대사량(TDEE)과
+// 하루 칼로리/탄단지 목표를 계산한다.
+package calculator
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// 성별
+const (
+	SexMale int32 = iota + 1
+	SexFemale
+)
+
+// BMR 계산식
+const (
+	FormulaMifflinStJeor int32 = iota + 1
+	FormulaHarrisBenedict
+)
+
+// 활동 수준
+const (
+	ActivitySedentary int32 = iota + 1
+	ActivityLight
+	ActivityModerate
+	ActivityActive
+	ActivityVeryActive
+)
+
+// 체지방 1kg을 빼거나 찌우는 데 필요한 칼로리
+const KcalPerKgFat = 7700.0
+
+// 건강을 해치지 않기 위한 하루 최소 섭취 칼로리
+const (
+	minCalorieMale   = 1500.0
+	minCalorieFemale = 1200.0
+)
+
+// 계산식을 쓸 수 있는 나이
+const (
+	minAge = 1
+	maxAge = 120
+)
+
+var ErrInvalidBody = errors.New("invalid body information")
+
+var activityFactors = map[int32]float64{
+	ActivitySedentary:  1.2,
+	ActivityLight:      1.375,
+	ActivityModerate:   1.55,
+	ActivityActive:     1.725,
+	ActivityVeryActive: 1.9,
+}
+
+type Body struct {
+	Sex    int32
+	Age    int
+	Height float64 // cm
+	Weight float64 // kg
+}
+
+func (b Body) isValid() bool {
+	return (b.Sex == SexMale || b.Sex == SexFemale) && b.Age >= minAge && b.Age <= maxAge && b.Height > 0 && b.Weight > 0
+}
+
+// Age 는 birthDate 에 태어난 사람의 now 기준 만 나이를 구한다.
+func Age(birthDate, now time.Time) int {
+	age := now.Year() - birthDate.Year()
+	if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
+		age--
+	}
+
+	return age
+}
+
+// BMR 은 선택한 계산식으로 하루 기초대사량(kcal)을 구한다.
+// 신체 정보가 잘못되었거나 계산한 값이 0 이하이면 ErrInvalidBody 를 돌려준다.
+func BMR(formula int32, body Body) (float64, error) {
+	if !body.isValid() {
+		return 0, ErrInvalidBody
+	}
+
+	age := float64(body.Age)
+
+	var bmr float64
+	switch formula {
+	case FormulaMifflinStJeor:
+		bmr = 10*body.Weight + 6.25*body.Height - 5*age
+		if body.Sex == SexMale {
+			bmr += 5
+		} else {
+			bmr -= 161
+		}
+	case FormulaHarrisBenedict:
+		// 1984년 Roza, Shizgal 개정식
+		if body.Sex == SexMale {
+			bmr = 88.362 + 13.397*body.Weight + 4.799*body.Height - 5.677*age
+		} else {
+			bmr = 447.593 + 9.247*body.Weight + 3.098*body.Height - 4.330*age
+		}
+	default:
+		return 0, errors.New("unknown bmr formula")
+	}
+
+	if bmr <= 0 {
+		return 0, ErrInvalidBody
+	}
+
+	return bmr, nil
+}
+
+// TDEE 는 기초대사량에 활동 수준을 반영한 하루 총 소비 칼로리(kcal)를 구한다.
+func TDEE(bmr float64, activityLevel int32) (float64, error) {
+	factor, ok := activityFactors[activityLevel]
+	if !ok {
+		return 0, errors.New("unknown activity level")
+	}
+
+	return bmr * factor, nil
+}
+
+// DailyCalorie 는 일주일에 goalRate(kg, 감량이면 음수) 만큼 체중을 바꾸기 위한 하루 섭취 칼로리를 구한다.
+// 너무 적게 먹지 않도록 성별에 따른 최소 칼로리 아래로는 내려가지 않는다.
+func DailyCalorie(tdee, goalRate float64, sex int32) float64 {
+	calorie := tdee + goalRate*KcalPerKgFat/7
+
+	minCalorie := minCalorieFemale
+	if sex == SexMale {
+		minCalorie = minCalorieMale
+	}
+
+	return math.Max(calorie, minCalorie)
+}
+
+type Macros struct {
+	Carbohydrate float64 // g
+	Protein      float64 // g
+	Fat          float64 // g
+}
+
+// 다이어트 중 근손실을 막기 위한 체중 1kg당 단백질(g)과 전체 칼로리 중 지방의 비율
+const (
+	proteinPerKg = 1.6
+	fatRatio     = 0.25
+)
+
+// MacroTargets 는 하루 섭취 칼로리를 탄단지(g)로 나눈다.
+// 단백질은 체중에 비례해서, 지방은 칼로리 비율로 먼저 정하고 남은 칼로리를 탄수화물로 채운다.
+func MacroTargets(calorie, weight float64) Macros {
+	protein := weight * proteinPerKg
+	fat := calorie * fatRatio / 9
+	carbohydrate := math.Max((calorie-protein*4-fat*9)/4, 0)
+
+	return Macros{Carbohydrate: carbohydrate, Protein: protein, Fat: fat}
+}
+
+// 운동 강도. 같은 운동이라도 강도에 따라 MET를 조절한다.
+const (
+	IntensityLight int32 = iota + 1
+	IntensityModerate
+	IntensityVigorous
+)
+
+var intensityFactors = map[int32]float64{
+	IntensityLight:    0.8,
+	IntensityModerate: 1.0,
+	IntensityVigorous: 1.2,
+}
+
+func IsValidIntensity(intensity int32) bool {
+	_, ok := intensityFactors[intensity]
+	return ok
+}
+
+// ActivityMet 은 운동의 기준 MET에 강도를 반영한 MET를 구한다. 강도를 모르면 보통 강도로 본다.
+func ActivityMet(met float64, intensity int32) float64 {
+	if factor, ok := intensityFactors[intensity]; ok {
+		return met * factor
+	}
+
+	return met
+}
+
+// BurnedCalorie 는 체중이 weight(kg)인 사람이 MET가 met 인 운동을 duration 동안 해서 소비한 칼로리(kcal)를 구한다.
+// 1 MET는 체중 1kg당 한 시간에 1kcal를 소비하는 것으로 본다.
+func BurnedCalorie(met, weight float64, duration time.Duration) float64 {
+	return met * weight * duration.Hours()
+}
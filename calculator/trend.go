@@ -0,0 +1,78 @@
+package calculator
+
+import "time"
+
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// MovingAverage 는 각 측정값을 그 시점까지 window 기간 안에 측정된 값들의 평균으로 바꾼다.
+// points 는 시간 순서로 정렬되어 있어야 한다.
+func MovingAverage(points []Point, window time.Duration) []Point {
+	averages := make([]Point, len(points))
+
+	start, sum := 0, 0.0
+	for idx, point := range points {
+		sum += point.Value
+		for !points[start].Time.Add(window).After(point.Time) {
+			sum -= points[start].Value
+			start++
+		}
+
+		averages[idx] = Point{Time: point.Time, Value: sum / float64(idx-start+1)}
+	}
+
+	return averages
+}
+
+// WeeklyChange 는 최소제곱법으로 구한 기울기를 주 단위 변화량으로 돌려준다.
+// 측정값이 두 개 미만이거나 모두 같은 시점이면 변화량을 구할 수 없다.
+func WeeklyChange(points []Point) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+
+	origin := points[0].Time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, point := range points {
+		x := point.Time.Sub(origin).Hours() / 24
+		sumX += x
+		sumY += point.Value
+		sumXY += x * point.Value
+		sumXX += x * x
+	}
+
+	n := float64(len(points))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, false
+	}
+
+	perDay := (n*sumXY - sumX*sumY) / denominator
+
+	return perDay * 7, true
+}
+
+// 이보다 오래 걸리는 목표는 예상하지 않는다. 변화량이 아주 작으면 의미 없이 먼 날짜가 나온다.
+const maxProjectionDays = 5 * 365
+
+// ProjectGoalDate 는 지금의 주간 변화량이 유지될 때 current 에서 goal 에 도달하는 날짜를 예상한다.
+// 목표와 반대 방향으로 변하고 있거나 maxProjectionDays 보다 오래 걸리면 도달할 수 없다고 본다.
+func ProjectGoalDate(now time.Time, current, goal, weeklyChange float64) (time.Time, bool) {
+	remaining := goal - current
+	if remaining == 0 {
+		return now, true
+	}
+
+	if weeklyChange == 0 || (remaining > 0) != (weeklyChange > 0) {
+		return time.Time{}, false
+	}
+
+	days := remaining / weeklyChange * 7
+	if days > maxProjectionDays {
+		return time.Time{}, false
+	}
+
+	return now.Add(time.Duration(days * 24 * float64(time.Hour))), true
+}
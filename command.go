@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/kernelgarden/diet/importer"
+	"github.com/kernelgarden/diet/models"
+	"os"
+	"strconv"
+)
+
+// 서버 대신 실행할 수 있는 관리용 명령
+var commands = map[string]func(args []string) error{
+	"import-mfds":   importMFDS,
+	"import-fdc":    importFDC,
+	"import-off":    importOFF,
+	"import-health": importHealth,
+	"import-diary":  importDiary,
+	"grant-admin":   grantAdmin,
+}
+
+func usage() string {
+	return "usage: diet [import-mfds|import-fdc|import-off [-dry-run] <file> | import-health -user <id> [-dry-run] [-weight-unit kg|lb] <file> | " +
+		"import-diary -user <id> -format myfitnesspal|csv [-columns <mapping>] [-create-foods] [-dry-run] <file> | grant-admin <email>]"
+}
+
+// RunCommand 는 args[0] 이름의 관리용 명령을 실행한다.
+func RunCommand(args []string) error {
+	command, ok := commands[args[0]]
+	if !ok {
+		return errors.New(usage())
+	}
+
+	return command(args[1:])
+}
+
+// importFile 은 파일 하나를 import 하고 결과를 JSON 으로 출력한다.
+func importFile(name string, args []string, run func(im *importer.Importer, path string) error) error {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "저장하지 않고 검증 결과만 출력")
+	if err := flags.Parse(args); err != nil {
+		return err
+	} else if flags.NArg() != 1 {
+		return errors.New(usage())
+	}
+
+	im := importer.New(*dryRun)
+	if err := run(im, flags.Arg(0)); err != nil {
+		return fmt.Errorf("%s stopped after %d rows: %v", name, im.Summary.Total, err)
+	}
+
+	if err := im.Finish(); err != nil {
+		return err
+	}
+
+	return printJSON(im.Summary)
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+// importMFDS 는 식품의약품안전처 식품영양성분 DB 파일을 가져온다.
+// 식품코드로 같은 food를 찾으므로 새 판을 내려받아 다시 실행하면 바뀐 값만 갱신된다.
+func importMFDS(args []string) error {
+	return importFile("import-mfds", args, importer.ImportMFDSFile)
+}
+
+// importFDC 는 USDA FoodData Central 의 bulk JSON 파일(Foundation, SR Legacy, Branded)을 가져온다.
+// fdcId 로 같은 food를 찾으므로 다시 실행하면 새로 만들지 않고 갱신된다.
+func importFDC(args []string) error {
+	return importFile("import-fdc", args, importer.ImportFDCFile)
+}
+
+// importOFF 는 Open Food Facts 의 JSONL 또는 CSV export 를 가져온다. 상품의 바코드도 함께 저장한다.
+func importOFF(args []string) error {
+	return importFile("import-off", args, importer.ImportOFFFile)
+}
+
+// importHealth 는 Apple 건강 export.xml, Google 테이크아웃 피트니스 JSON, Fitbit 계정 archive JSON 과 CSV 에서
+// -user 로 정한 user의 체성분과 운동 기록을 가져온다. 이미 가져왔거나 겹치는 기록은 건너뛴다.
+func importHealth(args []string) error {
+	flags := flag.NewFlagSet("import-health", flag.ContinueOnError)
+	user := flags.String("user", "", "기록을 가져올 user의 ID")
+	dryRun := flags.Bool("dry-run", false, "저장하지 않고 검증 결과만 출력")
+	weightUnit := flags.String("weight-unit", "", "Fitbit 처럼 파일에 단위가 없는 몸무게의 단위 (kg, lb)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	} else if flags.NArg() != 1 || (*weightUnit != "" && !importer.IsValidWeightUnit(*weightUnit)) {
+		return errors.New(usage())
+	}
+
+	userId, err := strconv.ParseInt(*user, 10, 64)
+	if err != nil {
+		return errors.New(usage())
+	}
+
+	im := importer.NewHealth(userId, *dryRun)
+	im.WeightUnit = *weightUnit
+	if err := importer.ImportHealthFile(im, flags.Arg(0)); err != nil {
+		return fmt.Errorf("import-health stopped after %d records: %v", im.Summary.Total, err)
+	}
+
+	return printJSON(im.Summary)
+}
+
+// importDiary 는 MyFitnessPal 이나 다른 앱에서 내보낸 식단 기록 CSV 를 -user 로 정한 user의 식사 기록으로 가져온다.
+// csv 형식은 -columns 로 값을 읽을 column을 정한다 (예: Date=Day,Food=Item,Calorie=kcal).
+// catalog에 없는 food는 -create-foods 일 때만 새로 만든다.
+func importDiary(args []string) error {
+	flags := flag.NewFlagSet("import-diary", flag.ContinueOnError)
+	user := flags.String("user", "", "기록을 가져올 user의 ID")
+	format := flags.String("format", "", "파일 형식 (myfitnesspal, csv)")
+	mapping := flags.String("columns", "", "csv 형식에서 값을 읽을 column")
+	createFoods := flags.Bool("create-foods", false, "catalog에 없는 food를 기록의 영양소로 새로 만듦")
+	dryRun := flags.Bool("dry-run", false, "저장하지 않고 검증 결과만 출력")
+	if err := flags.Parse(args); err != nil {
+		return err
+	} else if flags.NArg() != 1 {
+		return errors.New(usage())
+	}
+
+	userId, err := strconv.ParseInt(*user, 10, 64)
+	if err != nil {
+		return errors.New(usage())
+	}
+
+	var source string
+	var columns importer.DiaryColumns
+	switch *format {
+	case "myfitnesspal":
+		source, columns = importer.SourceMyFitnessPal, importer.MyFitnessPalColumns
+	case "csv":
+		if columns, err = importer.ParseDiaryColumns(*mapping); err != nil {
+			return err
+		}
+		source = importer.SourceDiaryCSV
+	default:
+		return errors.New(usage())
+	}
+
+	im := importer.NewDiary(userId, source, *createFoods, *dryRun)
+	if err := importer.ImportDiaryFile(im, flags.Arg(0), columns); err != nil {
+		return fmt.Errorf("import-diary stopped after %d rows: %v", im.Summary.Total, err)
+	}
+
+	if err := im.Finish(); err != nil {
+		return err
+	}
+
+	return printJSON(im.Result())
+}
+
+// grantAdmin 은 가입한 user를 admin으로 만든다. 처음 admin을 정할 때 사용하고, 이후로는 admin이 API로 권한을 부여한다.
+func grantAdmin(args []string) error {
+	if len(args) != 1 {
+		return errors.New(usage())
+	}
+
+	user, err := models.User{}.GetByEmail(args[0])
+	if err != nil {
+		return err
+	} else if user == nil {
+		return fmt.Errorf("grant-admin: user %s not found", args[0])
+	}
+
+	user.Role = models.RoleAdmin
+	if err := user.Update(); err != nil {
+		return err
+	}
+
+	return printJSON(user)
+}
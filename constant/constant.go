@@ -2,6 +2,7 @@ package constant
 
 var CtxDbName = "db_ctx"
 var CtxConfig = "global_config"
+var CtxUserId = "user_id"
 
 type Config struct {
 	Database struct {
@@ -14,6 +15,16 @@ type Config struct {
 		Logger   string
 	}
 
+	Auth struct {
+		Secret      string
+		ExpireHours int
+	}
+
+	// Driver 는 food 검색 색인을 둘 곳이다. (memory: 서버 메모리(기본값), sql: DB의 search_document 테이블)
+	Search struct {
+		Driver string
+	}
+
 	Behaviorlog struct {
 		Kafka string
 	}
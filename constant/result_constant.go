@@ -10,6 +10,12 @@ const (
 )
 
 type FailResp struct {
-	FailCode int    `json:"failCode"`
-	Message  string `json:"message"`
+	FailCode int          `json:"failCode"`
+	Message  string       `json:"message"`
+	Fields   []FieldError `json:"fields,omitempty"` // 값이 잘못된 field와 이유
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+	"github.com/labstack/echo"
+	"github.com/pangpanglabs/echoswagger"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+)
+
+// 사람이 할 수 있는 운동의 MET는 대략 이 범위 안에 있다.
+const maxActivityMet = 25
+
+type ActivityApiController struct {
+	Permission Permission
+}
+
+func (a ActivityApiController) Init(g echoswagger.ApiGroup) {
+	g.POST("", a.Create, RequireRole(a.Permission.Write)).
+		SetSecurity("Authorization").
+		AddParamBody(ActivityInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "생성된 운동 종류를 반환합니다.", models.Activity{}, nil)
+
+	g.GET("/:id", a.GetById).
+		AddParamPath("", "id", "조회할 운동 종류의 ID").
+		AddResponse(http.StatusOK, "조회할 운동 종류를 반환합니다.", models.Activity{}, nil)
+	g.GET("/page", a.GetPage).
+		AddParamQueryNested(ActivityGetPageInput{}).
+		AddResponse(http.StatusOK, "운동 종류 페이지를 이름 순서로 반환합니다.", ActivityGetPageOutput{}, nil)
+
+	g.PUT("/:id", a.Update, RequireRole(a.Permission.Write)).
+		SetSecurity("Authorization").
+		AddParamPath("", "id", "변경할 운동 종류의 ID").
+		AddParamBody(ActivityInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "", nil, nil)
+
+	g.DELETE("", a.Delete, RequireRole(a.Permission.Delete)).
+		SetSecurity("Authorization").
+		AddParamQueryNested(ActivityDeleteInput{}).
+		AddResponse(http.StatusOK, "", nil, nil)
+}
+
+type ActivityInput struct {
+	Name string  `json:"Name" swagger:"desc(운동 이름(변경할 때 보내지 않으면 적용X)),required"`
+	Met  float64 `json:"Met" swagger:"desc(보통 강도로 했을 때의 MET(변경할 때 보내지 않으면 적용X)),required"`
+}
+
+func (ActivityApiController) Create(ctx echo.Context) error {
+	var input ActivityInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if input.Name == "" || utf8.RuneCountInString(input.Name) > 64 || input.Met <= 0 || input.Met > maxActivityMet {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	newActivity := models.Activity{Name: input.Name, Met: input.Met}
+	if _, err := newActivity.Create(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, newActivity)
+}
+
+func (ActivityApiController) GetById(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	activity, err := models.Activity{}.Get(id)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if activity == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	return Success(ctx, activity)
+}
+
+type ActivityGetPageInput struct {
+	Limit  int `query:"limit" swagger:"desc(조회할 운동 종류의 개수),required"`
+	Offset int `query:"offset" swagger:"desc(조회를 시작할 offset),required"`
+}
+type ActivityGetPageOutput struct {
+	ActivityList []*models.Activity `json:"ActivityList"`
+}
+
+func (ActivityApiController) GetPage(ctx echo.Context) error {
+	var input ActivityGetPageInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	activityList, err := models.Activity{}.GetAll(input.Offset, input.Limit)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, ActivityGetPageOutput{ActivityList: activityList})
+}
+
+func (ActivityApiController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	var input ActivityInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	activity, err := models.Activity{}.Get(id)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if activity == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	if utf8.RuneCountInString(input.Name) > 64 || input.Met < 0 || input.Met > maxActivityMet {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+	if input.Name != "" {
+		activity.Name = input.Name
+	}
+	// 이미 기록한 운동의 소비 칼로리는 기록할 때의 MET로 계산한 그대로 둔다.
+	if input.Met > 0 {
+		activity.Met = input.Met
+	}
+
+	if err = activity.Update(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, nil)
+}
+
+type ActivityDeleteInput struct {
+	Id int64 `query:"id" swagger:"desc(삭제할 운동 종류의 ID),required"`
+}
+
+func (ActivityApiController) Delete(ctx echo.Context) error {
+	var input ActivityDeleteInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if err := (models.Activity{}).Delete(input.Id); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, nil)
+}
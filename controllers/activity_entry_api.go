@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/calculator"
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+	"github.com/labstack/echo"
+	"github.com/pangpanglabs/echoswagger"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 한 번에 기록할 수 있는 최대 운동 시간(분)
+const maxActivityMinutes = 24 * 60
+
+type ActivityEntryApiController struct {
+}
+
+func (a ActivityEntryApiController) Init(g echoswagger.ApiGroup) {
+	g.SetSecurity("Authorization")
+
+	g.POST("", a.Create, RequireLogin).
+		AddParamBody(ActivityEntryCreateInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "소비 칼로리를 계산한 운동 기록을 반환합니다.", models.ActivityEntryJSON{}, nil)
+
+	g.GET("/:id", a.GetById, RequireLogin).
+		AddParamPath("", "id", "조회할 운동 기록의 ID").
+		AddResponse(http.StatusOK, "조회할 운동 기록을 반환합니다.", models.ActivityEntryJSON{}, nil)
+	g.GET("/page", a.GetPage, RequireLogin).
+		AddParamQueryNested(ActivityEntryGetPageInput{}).
+		AddResponse(http.StatusOK, "기간 내의 운동 기록 페이지와 소비 칼로리 합계를 반환합니다.", ActivityEntryGetPageOutput{}, nil)
+
+	g.PUT("/:id", a.Update, RequireLogin).
+		AddParamPath("", "id", "변경할 운동 기록의 ID").
+		AddParamBody(ActivityEntryUpdateInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "", nil, nil)
+
+	g.DELETE("", a.Delete, RequireLogin).
+		AddParamQueryNested(ActivityEntryDeleteInput{}).
+		AddResponse(http.StatusOK, "", nil, nil)
+}
+
+// getOwnActivityEntry 는 로그인한 user의 운동 기록만 돌려준다.
+// 기록을 돌려주지 못한 경우에는 이미 실패 응답을 보냈으므로 함께 돌려준 error를 그대로 반환하면 된다.
+func getOwnActivityEntry(ctx echo.Context, id int64) (*models.ActivityEntry, error) {
+	entry, err := models.ActivityEntry{}.Get(id)
+	if err != nil {
+		return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if entry == nil {
+		return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	} else if entry.UserId != CurrentUserId(ctx) {
+		return nil, Fail(ctx, http.StatusForbidden, factory.NewFailResp(constant.NeedPermission))
+	}
+
+	return entry, nil
+}
+
+// setActivityCalorie 는 entry.PerformedAt 에 가장 가까운 때의 몸무게로 entry의 소비 칼로리를 계산한다.
+// 몸무게를 모르면 계산할 수 없으므로 체성분이나 profile을 먼저 기록해야 한다.
+// 실패하면 이미 실패 응답을 보냈으므로 false와 함께 돌려준 error를 그대로 반환하면 된다.
+func setActivityCalorie(ctx echo.Context, entry *models.ActivityEntry, activityId int64) (bool, error) {
+	activity, err := models.Activity{}.Get(activityId)
+	if err != nil {
+		return false, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if activity == nil {
+		return false, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	weight, err := models.BodyWeightAt(entry.UserId, entry.PerformedAt)
+	if err != nil {
+		return false, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if weight <= 0 {
+		return false, Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	entry.SetCalorie(*activity, weight)
+
+	return true, nil
+}
+
+type ActivityEntryCreateInput struct {
+	ActivityId  int64     `json:"ActivityId" swagger:"desc(운동 종류의 ID),required"`
+	Minutes     float64   `json:"Minutes" swagger:"desc(운동한 시간(분)),required"`
+	Intensity   int32     `json:"Intensity" swagger:"desc(운동 강도 (1: 가볍게, 2: 보통, 3: 격하게)(보내지 않으면 보통)),allowEmpty"`
+	PerformedAt time.Time `json:"PerformedAt" swagger:"desc(운동한 시간(보내지 않으면 현재 시간)),allowEmpty"`
+}
+
+func (ActivityEntryApiController) Create(ctx echo.Context) error {
+	var input ActivityEntryCreateInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if input.Intensity == 0 {
+		input.Intensity = calculator.IntensityModerate
+	}
+
+	if input.Minutes <= 0 || input.Minutes > maxActivityMinutes || !calculator.IsValidIntensity(input.Intensity) {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	if input.PerformedAt.IsZero() {
+		input.PerformedAt = time.Now()
+	}
+
+	newEntry := models.ActivityEntry{UserId: CurrentUserId(ctx), Minutes: input.Minutes, Intensity: input.Intensity,
+		PerformedAt: input.PerformedAt}
+	if ok, err := setActivityCalorie(ctx, &newEntry, input.ActivityId); !ok {
+		return err
+	}
+
+	if _, err := newEntry.Create(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	result, err := newEntry.ToJSON()
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, result)
+}
+
+func (ActivityEntryApiController) GetById(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	entry, err := getOwnActivityEntry(ctx, id)
+	if entry == nil {
+		return err
+	}
+
+	result, err := entry.ToJSON()
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, result)
+}
+
+type ActivityEntryGetPageInput struct {
+	From   string `query:"from" swagger:"desc(조회를 시작할 날짜(2006-01-02)),required"`
+	To     string `query:"to" swagger:"desc(조회를 끝낼 날짜(2006-01-02), 이 날짜를 포함),required"`
+	Limit  int    `query:"limit" swagger:"desc(조회할 운동 기록의 개수),required"`
+	Offset int    `query:"offset" swagger:"desc(조회를 시작할 offset),required"`
+}
+type ActivityEntryGetPageOutput struct {
+	EntryList []models.ActivityEntryJSON `json:"EntryList"`
+	Burned    float64                    `json:"Burned"`
+}
+
+func (ActivityEntryApiController) GetPage(ctx echo.Context) error {
+	var input ActivityEntryGetPageInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	from, err := ParseDate(input.From)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	to, err := ParseDate(input.To)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	entries, err := models.ActivityEntry{}.GetByUser(CurrentUserId(ctx), from, to.AddDate(0, 0, 1), input.Offset, input.Limit)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	result := ActivityEntryGetPageOutput{EntryList: make([]models.ActivityEntryJSON, 0, len(entries))}
+	for _, entry := range entries {
+		entryJSON, err := entry.ToJSON()
+		if err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		}
+
+		result.EntryList = append(result.EntryList, *entryJSON)
+		result.Burned += entry.Calorie
+	}
+
+	return Success(ctx, result)
+}
+
+type ActivityEntryUpdateInput struct {
+	ActivityId  int64     `json:"ActivityId" swagger:"desc(변경할 운동 종류 ID(보내지 않으면 적용X)),allowEmpty"`
+	Minutes     float64   `json:"Minutes" swagger:"desc(변경할 운동 시간(분)(보내지 않으면 적용X)),allowEmpty"`
+	Intensity   int32     `json:"Intensity" swagger:"desc(변경할 운동 강도(보내지 않으면 적용X)),allowEmpty"`
+	PerformedAt time.Time `json:"PerformedAt" swagger:"desc(변경할 운동한 시간(보내지 않으면 적용X)),allowEmpty"`
+}
+
+func (ActivityEntryApiController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	var input ActivityEntryUpdateInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	entry, err := getOwnActivityEntry(ctx, id)
+	if entry == nil {
+		return err
+	}
+
+	if input.Minutes < 0 || input.Minutes > maxActivityMinutes ||
+		(input.Intensity != 0 && !calculator.IsValidIntensity(input.Intensity)) {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	// 운동 종류, 시간, 강도 중 하나라도 바뀌면 소비 칼로리를 다시 계산한다.
+	// 운동한 시간만 바뀌면 그때의 몸무게로 다시 계산하고, catalog에 없는 운동은 그대로 둔다.
+	performedAtChanged := !input.PerformedAt.IsZero() && !input.PerformedAt.Equal(entry.PerformedAt)
+	if !input.PerformedAt.IsZero() {
+		entry.PerformedAt = input.PerformedAt
+	}
+	if input.ActivityId != 0 || input.Minutes > 0 || input.Intensity != 0 || (performedAtChanged && entry.ActivityId != 0) {
+		activityId := entry.ActivityId
+		if input.ActivityId != 0 {
+			activityId = input.ActivityId
+		}
+		if input.Minutes > 0 {
+			entry.Minutes = input.Minutes
+		}
+		if input.Intensity != 0 {
+			entry.Intensity = input.Intensity
+		}
+		if activityId == 0 {
+			// catalog에 없는 운동으로 가져온 기록은 운동 종류를 골라야 소비 칼로리를 다시 계산할 수 있다.
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+		}
+
+		if ok, err := setActivityCalorie(ctx, entry, activityId); !ok {
+			return err
+		}
+	}
+
+	if err = entry.Update(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, nil)
+}
+
+type ActivityEntryDeleteInput struct {
+	Id int64 `query:"id" swagger:"desc(삭제할 운동 기록의 ID),required"`
+}
+
+func (ActivityEntryApiController) Delete(ctx echo.Context) error {
+	var input ActivityEntryDeleteInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	entry, err := getOwnActivityEntry(ctx, input.Id)
+	if entry == nil {
+		return err
+	}
+
+	if err = (models.ActivityEntry{}).Delete(entry.Id); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, nil)
+}
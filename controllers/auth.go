@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+	"github.com/labstack/echo"
+	"net/http"
+	"strings"
+)
+
+const bearerPrefix = "Bearer "
+
+// Permission 은 route group 별로 데이터를 변경할 때 필요한 role을 정한다.
+type Permission struct {
+	Write  int32
+	Delete int32
+}
+
+// Authenticate 는 Authorization 헤더의 토큰을 검증해 요청한 user의 ID를 context에 담는다.
+// 토큰이 없으면 익명 요청으로 그대로 통과시키고, 잘못된 토큰이면 거절한다.
+func Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		header := ctx.Request().Header.Get(echo.HeaderAuthorization)
+		if header == "" {
+			return next(ctx)
+		}
+
+		if !strings.HasPrefix(header, bearerPrefix) {
+			return Fail(ctx, http.StatusUnauthorized, factory.NewFailResp(constant.NeedPermission))
+		}
+
+		userId, err := factory.ParseToken(strings.TrimPrefix(header, bearerPrefix))
+		if err != nil {
+			return Fail(ctx, http.StatusUnauthorized, factory.NewFailResp(constant.NeedPermission))
+		}
+
+		ctx.Set(constant.CtxUserId, userId)
+
+		return next(ctx)
+	}
+}
+
+// RequireLogin 은 익명 요청을 거절한다.
+func RequireLogin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		if CurrentUserId(ctx) == 0 {
+			return Fail(ctx, http.StatusUnauthorized, factory.NewFailResp(constant.NeedPermission))
+		}
+
+		return next(ctx)
+	}
+}
+
+// hasRole 은 로그인한 user가 role 이상의 권한을 가지고 있는지 확인한다. RequireRole 과 같이 role이 0 이면 false 이다.
+func hasRole(ctx echo.Context, role int32) (bool, error) {
+	user, err := models.User{}.Get(CurrentUserId(ctx))
+	if err != nil || user == nil {
+		return false, err
+	}
+
+	return models.IsValidRole(role) && user.HasRole(role), nil
+}
+
+// RequireRole 은 로그인한 user가 role 이상의 권한을 가지고 있는지 확인한다.
+// Permission 을 정하지 않아서 role이 0 이면 누구도 통과시키지 않는다.
+func RequireRole(role int32) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			userId := CurrentUserId(ctx)
+			if userId == 0 {
+				return Fail(ctx, http.StatusUnauthorized, factory.NewFailResp(constant.NeedPermission))
+			}
+
+			user, err := models.User{}.Get(userId)
+			if err != nil {
+				return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+			} else if user == nil || !models.IsValidRole(role) || !user.HasRole(role) {
+				return Fail(ctx, http.StatusForbidden, factory.NewFailResp(constant.NeedPermission))
+			}
+
+			return next(ctx)
+		}
+	}
+}
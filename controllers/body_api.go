@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/calculator"
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+	"github.com/labstack/echo"
+	"github.com/pangpanglabs/echoswagger"
+	"net/http"
+	"time"
+)
+
+// 추세를 계산할 때 쓰는 이동 평균 기간
+const movingAverageWindow = 7 * 24 * time.Hour
+
+type BodyApiController struct {
+}
+
+func (b BodyApiController) Init(g echoswagger.ApiGroup) {
+	g.SetSecurity("Authorization")
+
+	g.POST("", b.Create, RequireLogin).
+		AddParamBody(BodyCreateInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "생성된 체성분 기록을 반환합니다.", models.BodyMeasurement{}, nil)
+
+	g.GET("/list", b.GetList, RequireLogin).
+		AddParamQueryNested(BodyGetListInput{}).
+		AddResponse(http.StatusOK, "기간 내의 체성분 기록을 반환합니다.", BodyGetListOutput{}, nil)
+	g.GET("/trend", b.GetTrend, RequireLogin).
+		AddParamQueryNested(BodyGetListInput{}).
+		AddResponse(http.StatusOK, "기간 내의 몸무게 추세와 목표 도달 예상일을 반환합니다.", BodyTrendOutput{}, nil)
+
+	g.DELETE("", b.Delete, RequireLogin).
+		AddParamQueryNested(BodyDeleteInput{}).
+		AddResponse(http.StatusOK, "", nil, nil)
+}
+
+type BodyCreateInput struct {
+	Weight     float64   `json:"Weight" swagger:"desc(몸무게(kg)),required"`
+	BodyFat    float64   `json:"BodyFat" swagger:"desc(체지방률(%)),allowEmpty"`
+	Waist      float64   `json:"Waist" swagger:"desc(허리 둘레(cm)),allowEmpty"`
+	MuscleMass float64   `json:"MuscleMass" swagger:"desc(골격근량(kg)),allowEmpty"`
+	MeasuredAt time.Time `json:"MeasuredAt" swagger:"desc(측정 시간(보내지 않으면 현재 시간)),allowEmpty"`
+}
+
+func (BodyApiController) Create(ctx echo.Context) error {
+	var input BodyCreateInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if input.Weight <= 0 || input.BodyFat < 0 || input.BodyFat >= 100 || input.Waist < 0 || input.MuscleMass < 0 {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	if input.MeasuredAt.IsZero() {
+		input.MeasuredAt = time.Now()
+	}
+
+	newMeasurement := models.BodyMeasurement{UserId: CurrentUserId(ctx), Weight: input.Weight, BodyFat: input.BodyFat,
+		Waist: input.Waist, MuscleMass: input.MuscleMass, MeasuredAt: input.MeasuredAt}
+	if _, err := newMeasurement.Create(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, newMeasurement)
+}
+
+type BodyGetListInput struct {
+	From string `query:"from" swagger:"desc(조회를 시작할 날짜(2006-01-02)),required"`
+	To   string `query:"to" swagger:"desc(조회를 끝낼 날짜(2006-01-02), 이 날짜를 포함),required"`
+}
+type BodyGetListOutput struct {
+	MeasurementList []*models.BodyMeasurement `json:"MeasurementList"`
+}
+
+func getMeasurements(ctx echo.Context) ([]*models.BodyMeasurement, error) {
+	var input BodyGetListInput
+	if err := ctx.Bind(&input); err != nil {
+		return nil, Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	from, err := ParseDate(input.From)
+	if err != nil {
+		return nil, Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	to, err := ParseDate(input.To)
+	if err != nil {
+		return nil, Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	measurements, err := models.BodyMeasurement{}.GetByUser(CurrentUserId(ctx), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return measurements, nil
+}
+
+func (BodyApiController) GetList(ctx echo.Context) error {
+	measurements, err := getMeasurements(ctx)
+	if measurements == nil {
+		return err
+	}
+
+	result := BodyGetListOutput{MeasurementList: measurements}
+
+	return Success(ctx, result)
+}
+
+type BodyTrendPoint struct {
+	MeasuredAt    time.Time `json:"MeasuredAt"`
+	Weight        float64   `json:"Weight"`
+	MovingAverage float64   `json:"MovingAverage"`
+}
+type BodyTrendOutput struct {
+	Points            []BodyTrendPoint `json:"Points"`
+	WeeklyChange      float64          `json:"WeeklyChange"`
+	GoalWeight        float64          `json:"GoalWeight"`
+	ProjectedGoalDate string           `json:"ProjectedGoalDate,omitempty"`
+}
+
+func (BodyApiController) GetTrend(ctx echo.Context) error {
+	measurements, err := getMeasurements(ctx)
+	if measurements == nil {
+		return err
+	}
+
+	points := make([]calculator.Point, 0, len(measurements))
+	for _, measurement := range measurements {
+		if measurement.Weight > 0 {
+			points = append(points, calculator.Point{Time: measurement.MeasuredAt, Value: measurement.Weight})
+		}
+	}
+
+	averages := calculator.MovingAverage(points, movingAverageWindow)
+
+	trendPoints := make([]BodyTrendPoint, len(points))
+	for idx, point := range points {
+		trendPoints[idx] = BodyTrendPoint{MeasuredAt: point.Time, Weight: point.Value, MovingAverage: averages[idx].Value}
+	}
+
+	// 하루하루의 변동이 추세를 흔들지 않도록 이동 평균으로 변화량을 구한다.
+	weeklyChange, hasChange := calculator.WeeklyChange(averages)
+	result := BodyTrendOutput{Points: trendPoints, WeeklyChange: weeklyChange}
+
+	profile, err := models.Profile{}.GetByUser(CurrentUserId(ctx))
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	if profile != nil && profile.GoalWeight > 0 && hasChange {
+		result.GoalWeight = profile.GoalWeight
+
+		last := averages[len(averages)-1]
+		if goalDate, ok := calculator.ProjectGoalDate(last.Time, last.Value, profile.GoalWeight, weeklyChange); ok {
+			result.ProjectedGoalDate = goalDate.Format(DateLayout)
+		}
+	}
+
+	return Success(ctx, result)
+}
+
+type BodyDeleteInput struct {
+	Id int64 `query:"id" swagger:"desc(삭제할 체성분 기록의 ID),required"`
+}
+
+func (BodyApiController) Delete(ctx echo.Context) error {
+	var input BodyDeleteInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	measurement, err := models.BodyMeasurement{}.Get(input.Id)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if measurement == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	} else if measurement.UserId != CurrentUserId(ctx) {
+		return Fail(ctx, http.StatusForbidden, factory.NewFailResp(constant.NeedPermission))
+	}
+
+	if err = (models.BodyMeasurement{}).Delete(measurement.Id); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, nil)
+}
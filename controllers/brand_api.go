@@ -5,6 +5,7 @@ import (
 	"github.com/kernelgarden/diet/constant"
 	"github.com/kernelgarden/diet/factory"
 	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/validator"
 	"github.com/labstack/echo"
 	"github.com/pangpanglabs/echoswagger"
 	"net/http"
@@ -12,13 +13,15 @@ import (
 )
 
 type BrandApiController struct {
+	Permission Permission
 }
 
 func (b BrandApiController) Init(g echoswagger.ApiGroup) {
-	g.POST("", b.Create).
+	g.POST("", b.Create, RequireRole(b.Permission.Write)).
+		SetSecurity("Authorization").
 		AddParamQueryNested(BrandCreateInput{}).
 		AddResponse(http.StatusOK, "생성된 brand의 정보를 반환합니다.", models.Brand{}, nil)
-	g.GET("/dummy", b.CreateDummy)
+	g.GET("/dummy", b.CreateDummy, RequireRole(b.Permission.Write))
 
 	g.GET("/:id", b.GetById).
 		AddParamQueryNested(BrandGetByIdInput{}).
@@ -30,11 +33,13 @@ func (b BrandApiController) Init(g echoswagger.ApiGroup) {
 		AddParamQueryNested(BrandGetPageInput{}).
 		AddResponse(http.StatusOK, "조회할 brand 정보들의 페이지를 반환합니다.", BrandGetPageOutput{}, nil)
 
-	g.PUT("/:id", b.Update).
+	g.PUT("/:id", b.Update, RequireRole(b.Permission.Write)).
+		SetSecurity("Authorization").
 		AddParamQueryNested(BrandUpdateInput{}).
 		AddResponse(http.StatusOK, "", nil, nil)
 
-	g.DELETE("", b.Delete).
+	g.DELETE("", b.Delete, RequireRole(b.Permission.Delete)).
+		SetSecurity("Authorization").
 		AddParamQueryNested(BrandDeleteInput{}).
 		AddResponse(http.StatusOK, "", nil, nil)
 
@@ -43,8 +48,6 @@ func (b BrandApiController) Init(g echoswagger.ApiGroup) {
 		AddResponse(http.StatusOK, "해당 brand의 food 리스트를 반환합니다.", BrandGetFoodsOutput{}, nil)
 }
 
-// TODO: add validator
-
 type BrandGetByIdInput struct {
 	Id int64 `query:"id" swagger:"desc(조회할 brand의 ID),required"`
 }
@@ -160,7 +163,7 @@ func (BrandApiController) GetFoods(ctx echo.Context) error {
 
 type BrandCreateInput struct {
 	Name       string `json:"Name" swagger:"desc(등록할 이름),required"`
-	ImgUrl     string `json:"ImgUrl" swagger:"desc(등록할 이미지 주소),required"`
+	ImgUrl     string `json:"ImgUrl" swagger:"desc(등록할 이미지 주소(없으면 비워둔다)),allowEmpty"`
 	CategoryId int64  `json:"CategoryId" swagger:"desc(등록할 카테고리 ID),required"`
 }
 
@@ -171,6 +174,10 @@ func (BrandApiController) Create(ctx echo.Context) error {
 	}
 
 	newBrand := models.Brand{Name: input.Name, ImgSrc: input.ImgUrl, CategoryId: input.CategoryId}
+	if errs := validator.Brand(newBrand); len(errs) > 0 {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFieldFailResp(errs))
+	}
+
 	_, err := newBrand.Create()
 	if err != nil {
 		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
@@ -194,6 +201,8 @@ func (BrandApiController) Delete(ctx echo.Context) error {
 		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
 	}
 
+	logReindexError(ctx, models.ReindexBrand(input.Id))
+
 	return Success(ctx, nil)
 }
 
@@ -233,10 +242,16 @@ func (BrandApiController) Update(ctx echo.Context) error {
 		brand.CategoryId = input.CategoryId
 	}
 
+	if errs := validator.Brand(*brand); len(errs) > 0 {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFieldFailResp(errs))
+	}
+
 	if err = brand.Update(); err != nil {
 		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
 	}
 
+	logReindexError(ctx, models.ReindexBrand(brand.Id))
+
 	return Success(ctx, nil)
 }
 
@@ -11,10 +11,12 @@ import (
 )
 
 type CategoryApiController struct {
+	Permission Permission
 }
 
 func (c CategoryApiController) Init(g echoswagger.ApiGroup) {
-	g.POST("", c.Create).
+	g.POST("", c.Create, RequireRole(c.Permission.Write)).
+		SetSecurity("Authorization").
 		AddParamQueryNested(CategoryCreateInput{}).
 		AddResponse(http.StatusOK, "생성된 category의 정보를 반환합니다.", models.Category{}, nil)
 
@@ -28,11 +30,13 @@ func (c CategoryApiController) Init(g echoswagger.ApiGroup) {
 		AddParamQueryNested(CategoryGetPageInput{}).
 		AddResponse(http.StatusOK, "조회할 category 정보들의 페이지를 반환합니다.", CategoryGetPageOutput{}, nil)
 
-	g.PUT("/:id", c.Update).
+	g.PUT("/:id", c.Update, RequireRole(c.Permission.Write)).
+		SetSecurity("Authorization").
 		AddParamQueryNested(CategoryUpdateInput{}).
 		AddResponse(http.StatusOK, "", nil, nil)
 
-	g.DELETE("", c.Delete).
+	g.DELETE("", c.Delete, RequireRole(c.Permission.Delete)).
+		SetSecurity("Authorization").
 		AddParamQueryNested(CategoryDeleteInput{}).
 		AddResponse(http.StatusOK, "", nil, nil)
 
@@ -189,6 +193,8 @@ func (CategoryApiController) Delete(ctx echo.Context) error {
 		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
 	}
 
+	logReindexError(ctx, models.ReindexCategory(input.Id))
+
 	return Success(ctx, nil)
 }
 
@@ -222,5 +228,7 @@ func (CategoryApiController) Update(ctx echo.Context) error {
 		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
 	}
 
+	logReindexError(ctx, models.ReindexCategory(category.Id))
+
 	return Success(ctx, nil)
 }
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+	"github.com/labstack/echo"
+	"github.com/pangpanglabs/echoswagger"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type DrinkingApiController struct {
+}
+
+func (d DrinkingApiController) Init(g echoswagger.ApiGroup) {
+	g.SetSecurity("Authorization")
+
+	g.POST("", d.Create, RequireLogin).
+		AddParamBody(DrinkingCreateInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "생성된 술자리를 반환합니다.", models.DrinkingSessionJSON{}, nil)
+
+	g.GET("/:id", d.GetById, RequireLogin).
+		AddParamPath("", "id", "조회할 술자리의 ID").
+		AddResponse(http.StatusOK, "술자리에서 먹은 술과 안주, 알코올 칼로리를 나눈 합계를 반환합니다.", models.DrinkingSessionJSON{}, nil)
+	g.GET("/page", d.GetPage, RequireLogin).
+		AddParamQueryNested(DrinkingGetPageInput{}).
+		AddResponse(http.StatusOK, "기간 내에 시작한 술자리 페이지를 반환합니다.", DrinkingGetPageOutput{}, nil)
+
+	g.PUT("/:id", d.Update, RequireLogin).
+		AddParamPath("", "id", "변경할 술자리의 ID").
+		AddParamBody(DrinkingUpdateInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "", nil, nil)
+
+	g.DELETE("", d.Delete, RequireLogin).
+		AddParamQueryNested(DrinkingDeleteInput{}).
+		AddResponse(http.StatusOK, "", nil, nil)
+}
+
+// getOwnDrinkingSession 은 로그인한 user의 술자리만 돌려준다.
+// 술자리를 돌려주지 못한 경우에는 이미 실패 응답을 보냈으므로 함께 돌려준 error를 그대로 반환하면 된다.
+func getOwnDrinkingSession(ctx echo.Context, id int64) (*models.DrinkingSession, error) {
+	session, err := models.DrinkingSession{}.Get(id)
+	if err != nil {
+		return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if session == nil {
+		return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	} else if session.UserId != CurrentUserId(ctx) {
+		return nil, Fail(ctx, http.StatusForbidden, factory.NewFailResp(constant.NeedPermission))
+	}
+
+	return session, nil
+}
+
+type DrinkingCreateInput struct {
+	Name      string    `json:"Name" swagger:"desc(술자리 이름),allowEmpty"`
+	StartedAt time.Time `json:"StartedAt" swagger:"desc(시작한 시간(보내지 않으면 현재 시간)),allowEmpty"`
+}
+
+func (DrinkingApiController) Create(ctx echo.Context) error {
+	var input DrinkingCreateInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if len([]rune(input.Name)) > 64 {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	if input.StartedAt.IsZero() {
+		input.StartedAt = time.Now()
+	}
+
+	newSession := models.DrinkingSession{UserId: CurrentUserId(ctx), Name: input.Name, StartedAt: input.StartedAt}
+	if _, err := newSession.Create(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	result, err := newSession.ToJSON()
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, result)
+}
+
+func (DrinkingApiController) GetById(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	session, err := getOwnDrinkingSession(ctx, id)
+	if session == nil {
+		return err
+	}
+
+	result, err := session.ToJSON()
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, result)
+}
+
+type DrinkingGetPageInput struct {
+	From   string `query:"from" swagger:"desc(조회를 시작할 날짜(2006-01-02)),required"`
+	To     string `query:"to" swagger:"desc(조회를 끝낼 날짜(2006-01-02), 이 날짜를 포함),required"`
+	Limit  int    `query:"limit" swagger:"desc(조회할 술자리의 개수),required"`
+	Offset int    `query:"offset" swagger:"desc(조회를 시작할 offset),required"`
+}
+type DrinkingGetPageOutput struct {
+	SessionList []models.DrinkingSessionJSON `json:"SessionList"`
+	Total       models.Intake                `json:"Total"`
+}
+
+func (DrinkingApiController) GetPage(ctx echo.Context) error {
+	var input DrinkingGetPageInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	from, err := ParseDate(input.From)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	to, err := ParseDate(input.To)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	sessions, err := models.DrinkingSession{}.GetByUser(CurrentUserId(ctx), from, to.AddDate(0, 0, 1), input.Offset, input.Limit)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	result := DrinkingGetPageOutput{SessionList: make([]models.DrinkingSessionJSON, 0, len(sessions))}
+	for _, session := range sessions {
+		sessionJSON, err := session.ToJSON()
+		if err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		}
+
+		result.SessionList = append(result.SessionList, *sessionJSON)
+		result.Total = result.Total.Add(sessionJSON.Total)
+	}
+
+	return Success(ctx, result)
+}
+
+type DrinkingUpdateInput struct {
+	Name      string    `json:"Name" swagger:"desc(변경할 이름(보내지 않으면 적용X)),allowEmpty"`
+	StartedAt time.Time `json:"StartedAt" swagger:"desc(변경할 시작 시간(보내지 않으면 적용X)),allowEmpty"`
+	EndedAt   time.Time `json:"EndedAt" swagger:"desc(끝난 시간(보내지 않으면 적용X)),allowEmpty"`
+}
+
+func (DrinkingApiController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	var input DrinkingUpdateInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	session, err := getOwnDrinkingSession(ctx, id)
+	if session == nil {
+		return err
+	}
+
+	if input.Name != "" {
+		if len([]rune(input.Name)) > 64 {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+		}
+		session.Name = input.Name
+	}
+	if !input.StartedAt.IsZero() {
+		session.StartedAt = input.StartedAt
+	}
+	if !input.EndedAt.IsZero() {
+		session.EndedAt = input.EndedAt
+	}
+	if !session.EndedAt.IsZero() && session.EndedAt.Before(session.StartedAt) {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	if err = session.Update(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, nil)
+}
+
+type DrinkingDeleteInput struct {
+	Id int64 `query:"id" swagger:"desc(삭제할 술자리의 ID(식사 기록은 지우지 않음)),required"`
+}
+
+func (DrinkingApiController) Delete(ctx echo.Context) error {
+	var input DrinkingDeleteInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	session, err := getOwnDrinkingSession(ctx, input.Id)
+	if session == nil {
+		return err
+	}
+
+	if err = session.Delete(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, nil)
+}
@@ -5,21 +5,40 @@ import (
 	"github.com/go-xorm/xorm"
 	"github.com/kernelgarden/diet/constant"
 	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/gtin"
+	"github.com/kernelgarden/diet/importer"
 	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/search"
+	"github.com/kernelgarden/diet/units"
+	"github.com/kernelgarden/diet/validator"
 	"github.com/labstack/echo"
 	"github.com/pangpanglabs/echoswagger"
 	"net/http"
 	"strconv"
+	"time"
+	"unicode/utf8"
 )
 
 type FoodApiController struct {
+	Permission Permission
 }
 
 func (f FoodApiController) Init(g echoswagger.ApiGroup) {
-	g.POST("", f.Create).
-		AddParamQueryNested(FoodCreateInput{}).
+	g.POST("", f.Create, RequireRole(f.Permission.Write)).
+		SetSecurity("Authorization").
+		AddParamBody(FoodCreateInput{}, "body", "", true).
 		AddResponse(http.StatusOK, "생성된 food의 정보를 반환합니다.", models.FoodJSON{}, nil)
 
+	g.GET("/search", f.Search).
+		AddParamQueryNested(FoodSearchInput{}).
+		AddResponse(http.StatusOK, "검색어와 조건에 맞는 food를 관련도 순서로 반환합니다.", FoodSearchOutput{}, nil)
+	g.GET("/suggest", f.Suggest).
+		AddParamQueryNested(FoodSuggestInput{}).
+		AddResponse(http.StatusOK, "입력 중인 글자로 시작하는 food를 반환합니다. 로그인하면 최근에 자주 먹은 food를 먼저 보여줍니다.",
+			FoodSuggestOutput{}, nil)
+	g.GET("/barcode/:gtin", f.GetByBarcode).
+		AddParamPath("", "gtin", "스캔한 상품 바코드 (EAN-13, UPC-A)").
+		AddResponse(http.StatusOK, "바코드에 해당하는 food의 정보를 반환합니다.", models.FoodJSON{}, nil)
 	g.GET("/:id", f.GetById).
 		AddParamQueryNested(FoodGetByIdInput{}).
 		AddResponse(http.StatusOK, "조회할 food의 정보를 반환합니다.", models.FoodJSON{}, nil)
@@ -30,13 +49,34 @@ func (f FoodApiController) Init(g echoswagger.ApiGroup) {
 		AddParamQueryNested(FoodGetPageInput{}).
 		AddResponse(http.StatusOK, "조회할 food 정보들의 페이지를 반환합니다.", FoodGetPageOutput{}, nil)
 
-	g.PUT("/:id", f.Update).
-		AddParamQueryNested(FoodUpdateInput{}).
+	g.PUT("/:id", f.Update, RequireRole(f.Permission.Write)).
+		SetSecurity("Authorization").
+		AddParamBody(FoodUpdateInput{}, "body", "", true).
 		AddResponse(http.StatusOK, "", nil, nil)
 
-	g.DELETE("", f.Delete).
+	g.DELETE("", f.Delete, RequireRole(f.Permission.Delete)).
+		SetSecurity("Authorization").
 		AddParamQueryNested(BrandDeleteInput{}).
 		AddResponse(http.StatusOK, "", nil, nil)
+
+	g.POST("/import", f.Import, RequireRole(f.Permission.Write)).
+		SetSecurity("Authorization").
+		SetRequestContentType("multipart/form-data").
+		AddParamQueryNested(FoodImportInput{}).
+		AddParamFile("file", "가져올 CSV 파일 (GET /api/foods/export 와 같은 형식)", true).
+		AddResponse(http.StatusOK, "가져온 결과와 줄 별 에러를 반환합니다.", importer.Summary{}, nil)
+	g.GET("/export", f.Export).
+		SetResponseContentType("text/csv").
+		AddResponse(http.StatusOK, "모든 food를 CSV 로 반환합니다.", nil, nil)
+
+	g.POST("/:id/servings", f.CreateServingSize, RequireRole(f.Permission.Write)).
+		SetSecurity("Authorization").
+		AddParamBody(ServingSizeInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "생성된 serving size를 반환합니다.", models.ServingSize{}, nil)
+	g.DELETE("/:id/servings", f.DeleteServingSize, RequireRole(f.Permission.Write)).
+		SetSecurity("Authorization").
+		AddParamQueryNested(ServingSizeDeleteInput{}).
+		AddResponse(http.StatusOK, "", nil, nil)
 }
 
 type FoodGetByIdInput struct {
@@ -65,6 +105,29 @@ func (FoodApiController) GetById(ctx echo.Context) error {
 	return Success(ctx, result)
 }
 
+func (FoodApiController) GetByBarcode(ctx echo.Context) error {
+	barcode, err := gtin.Normalize(ctx.Param("gtin"))
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	food, err := models.Food{}.GetByBarcode(barcode)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if food == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	result, err := food.ToJSON()
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if result == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	return Success(ctx, result)
+}
+
 type FoodGetListInput struct {
 	IdList []int64 `json:"IdList" swagger:"desc(조회할 food의 ID 리스트),required"`
 }
@@ -134,11 +197,111 @@ func (FoodApiController) GetPage(ctx echo.Context) error {
 	return Success(ctx, result)
 }
 
+// 한 번에 검색할 수 있는 최대 food 개수
+const maxSearchLimit = 100
+
+type FoodSearchInput struct {
+	Q          string  `query:"q" swagger:"desc(검색어 (food, brand, category 이름)),allowEmpty"`
+	CategoryId int64   `query:"categoryId" swagger:"desc(category ID로 거르기),allowEmpty"`
+	BrandId    int64   `query:"brandId" swagger:"desc(brand ID로 거르기),allowEmpty"`
+	MinCalorie float64 `query:"minCalorie" swagger:"desc(food의 Unit 100 당 최소 칼로리(kcal)),allowEmpty"`
+	MaxCalorie float64 `query:"maxCalorie" swagger:"desc(food의 Unit 100 당 최대 칼로리(kcal)),allowEmpty"`
+	Limit      int     `query:"limit" swagger:"desc(조회할 food의 개수(최대 100)),required"`
+	Offset     int     `query:"offset" swagger:"desc(조회를 시작할 offset),required"`
+}
+type FoodSearchOutput struct {
+	Total    int               `json:"Total"`
+	FoodList []models.FoodJSON `json:"FoodList"`
+}
+
+func (FoodApiController) Search(ctx echo.Context) error {
+	var input FoodSearchInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if input.Limit <= 0 || input.Limit > maxSearchLimit || input.Offset < 0 || input.MinCalorie < 0 || input.MaxCalorie < 0 {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	found, err := factory.SearchIndex().Search(search.Query{Text: input.Q, CategoryId: input.CategoryId, BrandId: input.BrandId,
+		MinCalorie: input.MinCalorie, MaxCalorie: input.MaxCalorie, Offset: input.Offset, Limit: input.Limit})
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	foodList := make([]models.FoodJSON, 0, len(found.Hits))
+	for _, hit := range found.Hits {
+		food, err := models.Food{}.Get(hit.Id)
+		if err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		} else if food == nil {
+			// 색인이 갱신되기 전에 삭제된 food는 건너뛴다.
+			continue
+		}
+
+		foodJSON, err := food.ToJSON()
+		if err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		} else if foodJSON == nil {
+			continue
+		}
+
+		foodList = append(foodList, *foodJSON)
+	}
+
+	result := FoodSearchOutput{Total: found.Total, FoodList: foodList}
+
+	return Success(ctx, result)
+}
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+	// 자동 완성에서 먼저 보여줄 food를 찾는 기간
+	suggestBoostPeriod = 90 * 24 * time.Hour
+)
+
+type FoodSuggestInput struct {
+	Prefix string `query:"prefix" swagger:"desc(입력 중인 food 이름 (초성도 가능)),required"`
+	Limit  int    `query:"limit" swagger:"desc(조회할 food의 개수(보내지 않으면 10, 최대 50)),allowEmpty"`
+}
+type FoodSuggestOutput struct {
+	SuggestionList []search.Suggestion `json:"SuggestionList"`
+}
+
+func (FoodApiController) Suggest(ctx echo.Context) error {
+	var input FoodSuggestInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if input.Limit == 0 {
+		input.Limit = defaultSuggestLimit
+	} else if input.Limit < 0 || input.Limit > maxSuggestLimit {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	var boosts map[int64]search.Boost
+	if userId := CurrentUserId(ctx); userId != 0 {
+		var err error
+		boosts, err = models.FoodBoosts(userId, time.Now().Add(-suggestBoostPeriod))
+		if err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		}
+	}
+
+	result := FoodSuggestOutput{SuggestionList: factory.Suggester().Suggest(input.Prefix, input.Limit, boosts)}
+
+	return Success(ctx, result)
+}
+
 type FoodCreateInput struct {
 	CategoryId int64   `json:"CategoryId" swagger:"desc(생성할 food의 categoryId),required"`
 	BrandId    int64   `json:"BrandId" swagger:"desc(생성할 food의 brandId),allowEmpty"`
 	Name       string  `json:"Name" swagger:"desc(생성할 food의 이름),required"`
 	Weight     float64 `json:"Weight" swagger:"desc(생성할 food의 가중치),required"`
+	Barcode    string  `json:"Barcode" swagger:"desc(생성할 food의 상품 바코드 (EAN-13, UPC-A)),allowEmpty"`
 
 	Carbohydrate   float32 `json:"Carbohydrate" swagger:"desc(생성할 food의 탄수화물(g)),required"`
 	Protein        float32 `json:"Protein" swagger:"desc(생성할 food의 단백질(g)),required"`
@@ -147,7 +310,17 @@ type FoodCreateInput struct {
 	TransFat       float32 `json:"TransFat" swagger:"desc(생성할 food의 트랜스지방(g)),required"`
 	PerUnit        int32   `json:"PerUnit" swagger:"desc(생성할 food의 양(Unit)),required"`
 	Calorie        int64   `json:"Calorie" swagger:"desc(생성할 food의 칼로리(kcal)),required"`
-	Unit		   int32   `json:"Unit" swagger:"desc(생성할 food의 단위),required"`
+	Unit           units.Unit `json:"Unit" swagger:"desc(생성할 food의 단위 (1: g, 2: mg, 3: kg, 4: oz, 5: lb, 6: ml, 7: l, 8: cup, 9: tbsp, 10: tsp, 11: 개, 12: 인분)),required"`
+	Density        float64    `json:"Density" swagger:"desc(생성할 food의 밀도(g/ml), 질량과 부피를 바꿀 때 사용),allowEmpty"`
+	Abv            float64    `json:"Abv" swagger:"desc(생성할 food의 알코올 도수(%)(술이 아니면 보내지 않음)),allowEmpty"`
+
+	Sodium         *float32           `json:"Sodium" swagger:"desc(생성할 food의 나트륨(mg)(모르면 보내지 않음)),allowEmpty"`
+	Sugars         *float32           `json:"Sugars" swagger:"desc(생성할 food의 당류(g)(모르면 보내지 않음)),allowEmpty"`
+	DietaryFiber   *float32           `json:"DietaryFiber" swagger:"desc(생성할 food의 식이섬유(g)(모르면 보내지 않음)),allowEmpty"`
+	Cholesterol    *float32           `json:"Cholesterol" swagger:"desc(생성할 food의 콜레스테롤(mg)(모르면 보내지 않음)),allowEmpty"`
+	Alcohol        *float32           `json:"Alcohol" swagger:"desc(생성할 food의 알코올(g)(보내지 않으면 도수로 계산)),allowEmpty"`
+	Micronutrients map[string]float32 `json:"Micronutrients" swagger:"desc(생성할 food의 비타민/무기질 (vitamin_c: 10 처럼 코드별 함량)),allowEmpty"`
+	ServingSizes   []ServingSizeInput `json:"ServingSizes" swagger:"desc(생성할 food의 1회 분량 목록),allowEmpty"`
 }
 
 func (FoodApiController) Create(ctx echo.Context) error {
@@ -156,9 +329,37 @@ func (FoodApiController) Create(ctx echo.Context) error {
 		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
 	}
 
-	newFood := models.Food{CategoryId: input.CategoryId, BrandId: input.BrandId, Name: input.Name, Weight: input.Weight}
+	newFood := models.Food{CategoryId: input.CategoryId, BrandId: input.BrandId, Name: input.Name, Weight: input.Weight,
+		Density: input.Density, Abv: input.Abv}
 	newNutrient := models.Nutrient{Carbohydrate: input.Carbohydrate, Protein: input.Protein, SaturatedFat: input.SaturatedFat,
-		UnSaturatedFat: input.UnSaturatedFat, TransFat: input.TransFat, PerUnit: input.PerUnit, Calorie: input.Calorie, Unit: input.Unit}
+		UnSaturatedFat: input.UnSaturatedFat, TransFat: input.TransFat, PerUnit: input.PerUnit, Calorie: input.Calorie, Unit: input.Unit,
+		Sodium: input.Sodium, Sugars: input.Sugars, DietaryFiber: input.DietaryFiber, Cholesterol: input.Cholesterol,
+		Alcohol: input.Alcohol}
+
+	errs := validator.Food(newFood)
+	if input.Barcode != "" {
+		var err error
+		if newFood.Barcode, err = gtin.Normalize(input.Barcode); err != nil {
+			errs = append(errs, constant.FieldError{Field: "Barcode", Message: "올바르지 않은 바코드입니다."})
+		} else if owner, err := (models.Food{}).GetByBarcode(newFood.Barcode); err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		} else if owner != nil {
+			errs = append(errs, constant.FieldError{Field: "Barcode", Message: "이미 다른 food에 등록된 바코드입니다."})
+		}
+	}
+	errs = append(errs, validator.Nutrient(newFood, newNutrient)...)
+	errs = append(errs, validator.Micronutrients(input.Micronutrients)...)
+	for _, servingSize := range input.ServingSizes {
+		if !servingSize.isValid() {
+			errs = append(errs, constant.FieldError{Field: "ServingSizes", Message: "serving size의 이름과 무게가 필요합니다."})
+		}
+	}
+	if len(errs) > 0 {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFieldFailResp(errs))
+	}
+
+	// 도수만 알면 알코올 양을 계산해서 저장한다. 계산할 수 있는지는 위에서 확인했다.
+	newNutrient.FillAlcohol(newFood)
 
 	err := factory.Transaction(func(session *xorm.Session) error {
 		if _, err := newFood.CreateWithSes(session); err != nil {
@@ -171,12 +372,25 @@ func (FoodApiController) Create(ctx echo.Context) error {
 			return errors.New("nutrient insert 실패")
 		}
 
+		if err := models.SetMicronutrientsWithSes(session, newNutrient.Id, input.Micronutrients); err != nil {
+			return errors.New("micronutrient insert 실패")
+		}
+
+		for _, servingSize := range input.ServingSizes {
+			newServingSize := models.ServingSize{FoodId: newFood.Id, Label: servingSize.Label, Grams: servingSize.Grams}
+			if _, err := newServingSize.CreateWithSes(session); err != nil {
+				return errors.New("serving size insert 실패")
+			}
+		}
+
 		return nil
 	})
 	if err != nil {
 		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
 	}
 
+	logReindexError(ctx, models.ReindexFood(newFood.Id))
+
 	var category *models.Category
 	var brand *models.Brand
 
@@ -204,6 +418,16 @@ func (FoodApiController) Create(ctx echo.Context) error {
 		result = models.FoodJSON{}.NewFoodJSON(newFood, newNutrient, *brand, *category)
 	}
 
+	result.Micronutrients, err = models.Micronutrient{}.GetByNutrientId(newNutrient.Id)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	result.ServingSizes, err = models.ServingSize{}.GetByFoodId(newFood.Id)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
 
 	return Success(ctx, result)
 }
@@ -212,17 +436,36 @@ type FoodDeleteInput struct {
 	Id int64 `query:"id"`
 }
 
+// rejectRecipeFood 는 recipe의 food이면 실패 응답을 보내고 true 를 돌려준다.
+// recipe의 food는 재료로 계산하므로 recipe API 로만 바꾸거나 지울 수 있다.
+func rejectRecipeFood(ctx echo.Context, foodId int64) (bool, error) {
+	recipe, err := models.Recipe{}.GetByFoodId(foodId)
+	if err != nil {
+		return true, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if recipe != nil {
+		return true, Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	return false, nil
+}
+
 func (FoodApiController) Delete(ctx echo.Context) error {
 	var input FoodDeleteInput
 	if err := ctx.Bind(&input); err != nil {
 		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
 	}
 
+	if rejected, err := rejectRecipeFood(ctx, input.Id); rejected {
+		return err
+	}
+
 	err := models.Food{}.Delete(input.Id)
 	if err != nil {
 		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
 	}
 
+	logReindexError(ctx, models.ReindexFood(input.Id))
+
 	return Success(ctx, nil)
 }
 
@@ -231,6 +474,7 @@ type FoodUpdateInput struct {
 	BrandId    int64   `json:"BrandId" swagger:"desc(변경할 brandId(보내지 않으면 적용X),allowEmpty"`
 	Name       string  `json:"Name" swagger:"desc(변경할 이름(보내지 않으면 적용X),allowEmpty"`
 	Weight     float64 `json:"Weight" swagger:"desc(변경할 가중치(보내지 않으면 적용X)),allowEmpty"`
+	Barcode    string  `json:"Barcode" swagger:"desc(변경할 상품 바코드(보내지 않으면 적용X)),allowEmpty"`
 
 	Carbohydrate   float32 `json:"Carbohydrate" swagger:"desc(생성할 food의 탄수화물(g)),allowEmpty"`
 	Protein        float32 `json:"Protein" swagger:"desc(생성할 food의 단백질(g)),allowEmpty"`
@@ -239,7 +483,17 @@ type FoodUpdateInput struct {
 	TransFat       float32 `json:"TransFat" swagger:"desc(생성할 food의 트랜스지방(g)),allowEmpty"`
 	PerUnit        int32   `json:"PerUnit" swagger:"desc(생성할 food의 양(Unit)),allowEmpty"`
 	Calorie        int64   `json:"Calorie" swagger:"desc(생성할 food의 칼로리(kcal)),allowEmpty"`
-	Unit		   int32   `json:"Unit" swagger:"desc(생성할 food의 단위),allowEmpty"`
+	Unit           units.Unit `json:"Unit" swagger:"desc(생성할 food의 단위),allowEmpty"`
+	Density        float64    `json:"Density" swagger:"desc(변경할 밀도(g/ml)(보내지 않으면 적용X)),allowEmpty"`
+	Abv            *float64   `json:"Abv" swagger:"desc(변경할 알코올 도수(%)(보내지 않으면 적용X, 0 이면 술이 아닌 것으로 바꿈)),allowEmpty"`
+
+	Sodium         *float32           `json:"Sodium" swagger:"desc(변경할 나트륨(mg)(보내지 않으면 적용X)),allowEmpty"`
+	Sugars         *float32           `json:"Sugars" swagger:"desc(변경할 당류(g)(보내지 않으면 적용X)),allowEmpty"`
+	DietaryFiber   *float32           `json:"DietaryFiber" swagger:"desc(변경할 식이섬유(g)(보내지 않으면 적용X)),allowEmpty"`
+	Cholesterol    *float32           `json:"Cholesterol" swagger:"desc(변경할 콜레스테롤(mg)(보내지 않으면 적용X)),allowEmpty"`
+	Alcohol        *float32           `json:"Alcohol" swagger:"desc(변경할 알코올(g)(보내지 않고 도수를 바꾸면 도수로 계산)),allowEmpty"`
+	Micronutrients map[string]float32 `json:"Micronutrients" swagger:"desc(변경할 비타민/무기질(보낸 항목만 적용)),allowEmpty"`
+	Clear          []string           `json:"Clear" swagger:"desc(값을 지워서 모르는 값(null)으로 바꿀 항목 (Sodium, Sugars, DietaryFiber, Cholesterol, vitamin_c 같은 비타민/무기질 코드)),allowEmpty"`
 }
 
 func (FoodApiController) Update(ctx echo.Context) error {
@@ -260,6 +514,10 @@ func (FoodApiController) Update(ctx echo.Context) error {
 		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
 	}
 
+	if rejected, err := rejectRecipeFood(ctx, food.Id); rejected {
+		return err
+	}
+
 	if input.CategoryId != 0 {
 		food.CategoryId = input.CategoryId
 	}
@@ -272,26 +530,46 @@ func (FoodApiController) Update(ctx echo.Context) error {
 	if input.Weight != 0 {
 		food.Weight = input.Weight
 	}
+	if input.Density != 0 {
+		food.Density = input.Density
+	}
+	if input.Abv != nil {
+		food.Abv = *input.Abv
+	}
+
+	errs := validator.Food(*food)
+	if input.Barcode != "" {
+		if food.Barcode, err = gtin.Normalize(input.Barcode); err != nil {
+			errs = append(errs, constant.FieldError{Field: "Barcode", Message: "올바르지 않은 바코드입니다."})
+		} else if owner, err := (models.Food{}).GetByBarcode(food.Barcode); err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		} else if owner != nil && owner.Id != food.Id {
+			errs = append(errs, constant.FieldError{Field: "Barcode", Message: "이미 다른 food에 등록된 바코드입니다."})
+		}
+	}
 
 	var nutrient *models.Nutrient
-	nutrient, err = models.Nutrient{}.Get(id)
+	nutrient, err = models.Nutrient{}.GetByFoodId(food.Id)
 	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if nutrient == nil {
 		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
 	}
 
-	if input.Carbohydrate > 0 {
+	// 음수를 보내면 적용한 뒤에 검증에서 알려준다.
+	if input.Carbohydrate != 0 {
 		nutrient.Carbohydrate = input.Carbohydrate
 	}
-	if input.Protein > 0 {
+	if input.Protein != 0 {
 		nutrient.Protein = input.Protein
 	}
-	if input.SaturatedFat > 0 {
+	if input.SaturatedFat != 0 {
 		nutrient.SaturatedFat = input.SaturatedFat
 	}
-	if input.UnSaturatedFat > 0 {
+	if input.UnSaturatedFat != 0 {
 		nutrient.UnSaturatedFat = input.UnSaturatedFat
 	}
-	if input.TransFat > 0 {
+	if input.TransFat != 0 {
 		nutrient.TransFat = input.TransFat
 	}
 	if input.PerUnit != 0 {
@@ -300,25 +578,172 @@ func (FoodApiController) Update(ctx echo.Context) error {
 	if input.Calorie != 0 {
 		nutrient.Calorie = input.Calorie
 	}
-	if input.Unit != 0 {
+	if input.Unit != 0 && input.Unit != nutrient.Unit {
+		// 기록한 양은 nutrient의 Unit 기준이므로 기록이 있는 food의 단위를 바꾸면 양이 틀려진다.
+		used, err := food.HasQuantities()
+		if err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		} else if used {
+			errs = append(errs, constant.FieldError{Field: "Unit", Message: "식사 기록이나 레시피에 쓰인 food는 단위를 바꿀 수 없습니다."})
+		}
 		nutrient.Unit = input.Unit
 	}
+	if input.Sodium != nil {
+		nutrient.Sodium = input.Sodium
+	}
+	if input.Sugars != nil {
+		nutrient.Sugars = input.Sugars
+	}
+	if input.DietaryFiber != nil {
+		nutrient.DietaryFiber = input.DietaryFiber
+	}
+	if input.Cholesterol != nil {
+		nutrient.Cholesterol = input.Cholesterol
+	}
+	clearCodes := make([]string, 0)
+	for _, name := range input.Clear {
+		switch name {
+		case "Sodium":
+			nutrient.Sodium = nil
+		case "Sugars":
+			nutrient.Sugars = nil
+		case "DietaryFiber":
+			nutrient.DietaryFiber = nil
+		case "Cholesterol":
+			nutrient.Cholesterol = nil
+		default:
+			if models.IsValidMicronutrient(name) {
+				clearCodes = append(clearCodes, name)
+			} else {
+				errs = append(errs, constant.FieldError{Field: "Clear", Message: name + " 은 지울 수 없는 항목입니다."})
+			}
+		}
+	}
+	if input.Alcohol != nil {
+		nutrient.Alcohol = input.Alcohol
+	} else if input.Abv != nil || ((input.PerUnit != 0 || input.Unit != 0) && food.Abv > 0) {
+		// 도수나 기준 양이 바뀌었으면 알코올 양을 검증하면서 다시 계산하고, 도수를 0 으로 바꾸면 지운다.
+		nutrient.Alcohol = nil
+	}
+
+	// 예전에 등록한 값이 맞지 않더라도 nutrient를 바꾸지 않으면 그대로 둔다.
+	nutrientChanged := input.Carbohydrate != 0 || input.Protein != 0 || input.SaturatedFat != 0 ||
+		input.UnSaturatedFat != 0 || input.TransFat != 0 || input.PerUnit != 0 || input.Calorie != 0 ||
+		input.Unit != 0 || input.Sodium != nil || input.Sugars != nil || input.DietaryFiber != nil ||
+		input.Cholesterol != nil || input.Alcohol != nil || input.Abv != nil || input.Density != 0 || len(input.Clear) > 0
+	if nutrientChanged {
+		errs = append(errs, validator.Nutrient(*food, *nutrient)...)
+	}
+	errs = append(errs, validator.Micronutrients(input.Micronutrients)...)
+	if len(errs) > 0 {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFieldFailResp(errs))
+	}
+
+	if nutrientChanged {
+		// 도수로 알코올 양을 계산할 수 있는지는 위에서 확인했다.
+		nutrient.FillAlcohol(*food)
+	}
 
 	err = factory.Transaction(func(session *xorm.Session) error {
-		if err = food.UpdateWithSes(session); err != nil {
+		// 도수를 0 으로 바꾼 것도 저장되도록 바꾸지 않은 값까지 모두 저장한다.
+		if err = food.ReplaceWithSes(session); err != nil {
 			return errors.New("food update 실패")
 		}
 
-		if err = nutrient.UpdateWithSes(session); err != nil {
+		// 지운 항목이 null 로 저장되도록 바꾸지 않은 값까지 모두 저장한다.
+		if err = nutrient.ReplaceWithSes(session); err != nil {
 			return errors.New("nutrient update 실패")
 		}
 
+		if err = models.DeleteMicronutrientsWithSes(session, nutrient.Id, clearCodes); err != nil {
+			return errors.New("micronutrient delete 실패")
+		}
+
+		if err = models.SetMicronutrientsWithSes(session, nutrient.Id, input.Micronutrients); err != nil {
+			return errors.New("micronutrient update 실패")
+		}
+
 		return nil
 	})
 	if err != nil {
 		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
 	}
 
+	logReindexError(ctx, models.ReindexFood(food.Id))
+
+	return Success(ctx, nil)
+}
+
+type ServingSizeInput struct {
+	Label string  `json:"Label" swagger:"desc(1회 분량의 이름 (1조각, 1공기 등)),required"`
+	Grams float64 `json:"Grams" swagger:"desc(1회 분량의 무게(g)),required"`
+}
+
+func (s ServingSizeInput) isValid() bool {
+	return s.Label != "" && utf8.RuneCountInString(s.Label) <= 32 && s.Grams > 0
+}
+
+func (FoodApiController) CreateServingSize(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	var input ServingSizeInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if !input.isValid() {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	food, err := models.Food{}.Get(id)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if food == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	newServingSize := models.ServingSize{FoodId: food.Id, Label: input.Label, Grams: input.Grams}
+	if _, err := newServingSize.Create(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, newServingSize)
+}
+
+type ServingSizeDeleteInput struct {
+	ServingSizeId int64 `query:"servingSizeId" swagger:"desc(삭제할 serving size의 ID),required"`
+}
+
+func (FoodApiController) DeleteServingSize(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	var input ServingSizeDeleteInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	// recipe의 1인분 serving size는 recipe가 참조한다.
+	if rejected, err := rejectRecipeFood(ctx, id); rejected {
+		return err
+	}
+
+	servingSize, err := models.Food{Id: id}.GetServingSize(input.ServingSizeId)
+	if err == models.ErrServingSizeNotFound {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	} else if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	if err = (models.ServingSize{}).Delete(servingSize.Id); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
 	return Success(ctx, nil)
 }
 
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/importer"
+	"github.com/kernelgarden/diet/models"
+	"github.com/labstack/echo"
+	"net/http"
+)
+
+// export 할 때 한 번에 읽는 food 개수
+const exportPageSize = 500
+
+type FoodImportInput struct {
+	DryRun bool `query:"dryRun" swagger:"desc(true 이면 저장하지 않고 검증 결과만 반환),allowEmpty"`
+}
+
+func (FoodApiController) Import(ctx echo.Context) error {
+	var input FoodImportInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+	defer file.Close()
+
+	im := importer.New(input.DryRun)
+	if err := importer.ImportCSV(im, file); err != nil {
+		ctx.Logger().Errorf("food import stopped after %d rows: %v", im.Summary.Total, err)
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	logReindexError(ctx, im.Finish())
+
+	return Success(ctx, im.Summary)
+}
+
+func (FoodApiController) Export(ctx echo.Context) error {
+	response := ctx.Response()
+	response.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	response.Header().Set(echo.HeaderContentDisposition, `attachment; filename="foods.csv"`)
+	response.WriteHeader(http.StatusOK)
+
+	// 응답을 이미 보내기 시작했으므로 중간에 실패하면 로그만 남기고 끊는다.
+	writer := importer.NewCSVWriter(response)
+	if err := writer.WriteHeader(); err != nil {
+		return err
+	}
+
+	for offset := 0; ; offset += exportPageSize {
+		foods, err := models.Food{}.GetAll(offset, exportPageSize)
+		if err != nil {
+			ctx.Logger().Errorf("food export failed: %v", err)
+			return err
+		}
+
+		for _, food := range foods {
+			foodJSON, err := food.ToJSON()
+			if err != nil {
+				ctx.Logger().Errorf("food export failed: %v", err)
+				return err
+			} else if foodJSON == nil {
+				continue
+			}
+
+			if err := writer.Write(importer.FromFoodJSON(*foodJSON)); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		response.Flush()
+
+		if len(foods) < exportPageSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/importer"
+	"github.com/labstack/echo"
+	"github.com/pangpanglabs/echoswagger"
+	"net/http"
+)
+
+type HealthApiController struct {
+}
+
+func (h HealthApiController) Init(g echoswagger.ApiGroup) {
+	g.SetSecurity("Authorization")
+
+	g.POST("/import", h.Import, RequireLogin).
+		SetRequestContentType("multipart/form-data").
+		AddParamQueryNested(HealthImportInput{}).
+		AddParamFile("file", "가져올 파일 (Apple 건강 export.xml, Google 테이크아웃 피트니스 JSON, Fitbit 계정 archive 의 운동/몸무게 JSON, Fitbit CSV)", true).
+		AddResponse(http.StatusOK, "체성분과 운동 기록을 가져온 결과와 에러를 반환합니다.", importer.Summary{}, nil)
+}
+
+type HealthImportInput struct {
+	DryRun     bool   `query:"dryRun" swagger:"desc(true 이면 저장하지 않고 검증 결과만 반환),allowEmpty"`
+	WeightUnit string `query:"weightUnit" swagger:"desc(Fitbit 처럼 파일에 단위가 없는 몸무게의 단위 (kg, lb)(보내지 않으면 kg)),allowEmpty"`
+}
+
+// Import 는 로그인한 user의 체성분과 운동 기록을 가져온다. 형식은 파일 이름의 확장자로 정한다.
+func (HealthApiController) Import(ctx echo.Context) error {
+	var input HealthImportInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if input.WeightUnit != "" && !importer.IsValidWeightUnit(input.WeightUnit) {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+	defer file.Close()
+
+	im := importer.NewHealth(CurrentUserId(ctx), input.DryRun)
+	im.WeightUnit = input.WeightUnit
+	if err := importer.ImportHealth(im, file, fileHeader.Filename); err == importer.ErrHealthFormat {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	} else if err != nil {
+		ctx.Logger().Errorf("health import stopped after %d records: %v", im.Summary.Total, err)
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, im.Summary)
+}
@@ -0,0 +1,341 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/importer"
+	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/units"
+	"github.com/labstack/echo"
+	"github.com/pangpanglabs/echoswagger"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type MealApiController struct {
+	// 식단 기록을 가져오면서 catalog에 없는 food를 새로 만들 수 있는 권한
+	Permission Permission
+}
+
+func (m MealApiController) Init(g echoswagger.ApiGroup) {
+	g.SetSecurity("Authorization")
+
+	g.POST("", m.Create, RequireLogin).
+		AddParamBody(MealCreateInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "생성된 식사 기록을 반환합니다.", models.MealEntryJSON{}, nil)
+
+	g.GET("/:id", m.GetById, RequireLogin).
+		AddParamQueryNested(MealGetByIdInput{}).
+		AddResponse(http.StatusOK, "조회할 식사 기록을 반환합니다.", models.MealEntryJSON{}, nil)
+	g.GET("/page", m.GetPage, RequireLogin).
+		AddParamQueryNested(MealGetPageInput{}).
+		AddResponse(http.StatusOK, "기간 내의 식사 기록 페이지를 반환합니다.", MealGetPageOutput{}, nil)
+
+	g.PUT("/:id", m.Update, RequireLogin).
+		AddParamBody(MealUpdateInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "", nil, nil)
+
+	g.DELETE("", m.Delete, RequireLogin).
+		AddParamQueryNested(MealDeleteInput{}).
+		AddResponse(http.StatusOK, "", nil, nil)
+
+	g.POST("/import", m.Import, RequireLogin).
+		SetRequestContentType("multipart/form-data").
+		AddParamQueryNested(MealImportInput{}).
+		AddParamFile("file", "가져올 식단 기록 CSV 파일", true).
+		AddResponse(http.StatusOK, "가져온 식사 기록과 새로 만든 food의 결과, catalog에 없는 food와 줄 별 에러를 반환합니다.", importer.DiarySummary{}, nil)
+}
+
+// getOwnMealEntry 는 로그인한 user의 식사 기록만 돌려준다.
+// 기록을 돌려주지 못한 경우에는 이미 실패 응답을 보냈으므로 함께 돌려준 error를 그대로 반환하면 된다.
+func getOwnMealEntry(ctx echo.Context, id int64) (*models.MealEntry, error) {
+	entry, err := models.MealEntry{}.Get(id)
+	if err != nil {
+		return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if entry == nil {
+		return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	} else if entry.UserId != CurrentUserId(ctx) {
+		return nil, Fail(ctx, http.StatusForbidden, factory.NewFailResp(constant.NeedPermission))
+	}
+
+	return entry, nil
+}
+
+type MealCreateInput struct {
+	FoodId        int64      `json:"FoodId" swagger:"desc(먹은 food의 ID),required"`
+	Quantity      float64    `json:"Quantity" swagger:"desc(먹은 양),required"`
+	Unit          units.Unit `json:"Unit" swagger:"desc(먹은 양의 단위(보내지 않으면 food의 Unit)),allowEmpty"`
+	ServingSizeId int64      `json:"ServingSizeId" swagger:"desc(먹은 양의 serving size ID(보내면 Quantity 는 인분 수이고 Unit 은 무시)),allowEmpty"`
+	Slot          int32      `json:"Slot" swagger:"desc(끼니 (1: 아침, 2: 점심, 3: 저녁, 4: 간식)),required"`
+	EatenAt       time.Time  `json:"EatenAt" swagger:"desc(먹은 시간(보내지 않으면 현재 시간)),allowEmpty"`
+	SessionId     int64      `json:"SessionId" swagger:"desc(술자리에서 먹었으면 술자리 ID),allowEmpty"`
+}
+
+// failQuantity 는 입력한 양을 food의 단위로 바꾸다가 생긴 error에 맞는 실패 응답을 보낸다.
+func failQuantity(ctx echo.Context, err error) error {
+	switch err {
+	case units.ErrUnknownUnit, units.ErrIncompatible, units.ErrDensityRequired, models.ErrServingSizeNotFound:
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	case models.ErrNutrientNotFound:
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	default:
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+}
+
+// setMealQuantity 는 입력한 양을 food의 단위로 바꿔서 entry에 저장한다.
+// 실패하면 이미 실패 응답을 보냈으므로 false와 함께 돌려준 error를 그대로 반환하면 된다.
+func setMealQuantity(ctx echo.Context, entry *models.MealEntry, food models.Food, quantity float64, unit units.Unit,
+	servingSizeId int64) (bool, error) {
+	if err := entry.SetQuantity(food, quantity, unit, servingSizeId); err != nil {
+		return false, failQuantity(ctx, err)
+	}
+
+	return true, nil
+}
+
+func (MealApiController) Create(ctx echo.Context) error {
+	var input MealCreateInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if input.Quantity <= 0 || !models.IsValidMealSlot(input.Slot) {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	food, err := models.Food{}.Get(input.FoodId)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if food == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	if input.EatenAt.IsZero() {
+		input.EatenAt = time.Now()
+	}
+
+	if input.SessionId != 0 {
+		if session, err := getOwnDrinkingSession(ctx, input.SessionId); session == nil {
+			return err
+		}
+	}
+
+	newEntry := models.MealEntry{UserId: CurrentUserId(ctx), Slot: input.Slot, EatenAt: input.EatenAt,
+		SessionId: input.SessionId}
+	if ok, err := setMealQuantity(ctx, &newEntry, *food, input.Quantity, input.Unit, input.ServingSizeId); !ok {
+		return err
+	}
+
+	if _, err := newEntry.Create(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	result, err := newEntry.ToJSON()
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if result == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	return Success(ctx, result)
+}
+
+type MealGetByIdInput struct {
+	Id int64 `query:"id" swagger:"desc(조회할 식사 기록의 ID),required"`
+}
+
+func (MealApiController) GetById(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	entry, err := getOwnMealEntry(ctx, id)
+	if entry == nil {
+		return err
+	}
+
+	result, err := entry.ToJSON()
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if result == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	return Success(ctx, result)
+}
+
+type MealGetPageInput struct {
+	From   string `query:"from" swagger:"desc(조회를 시작할 날짜(2006-01-02)),required"`
+	To     string `query:"to" swagger:"desc(조회를 끝낼 날짜(2006-01-02), 이 날짜를 포함),required"`
+	Limit  int    `query:"limit" swagger:"desc(조회할 식사 기록의 개수),required"`
+	Offset int    `query:"offset" swagger:"desc(조회를 시작할 offset),required"`
+}
+type MealGetPageOutput struct {
+	MealList []models.MealEntryJSON `json:"MealList"`
+	Intake   models.Intake          `json:"Intake"`
+}
+
+func (MealApiController) GetPage(ctx echo.Context) error {
+	var input MealGetPageInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	from, err := ParseDate(input.From)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	to, err := ParseDate(input.To)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	entries, err := models.MealEntry{}.GetByUser(CurrentUserId(ctx), from, to.AddDate(0, 0, 1), input.Offset, input.Limit)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	var intake models.Intake
+	mealList := make([]models.MealEntryJSON, 0, len(entries))
+	for _, entry := range entries {
+		entryJSON, err := entry.ToJSON()
+		if err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		} else if entryJSON == nil {
+			// 삭제된 food를 먹은 기록은 건너뛴다.
+			continue
+		}
+
+		intake = intake.Add(entryJSON.Intake)
+		mealList = append(mealList, *entryJSON)
+	}
+
+	result := MealGetPageOutput{MealList: mealList, Intake: intake}
+
+	return Success(ctx, result)
+}
+
+// MealUpdateInput 의 SessionId 가 이 값이면 술자리에서 뺀다.
+const noDrinkingSession = -1
+
+type MealUpdateInput struct {
+	FoodId        int64      `json:"FoodId" swagger:"desc(변경할 food ID(보내지 않으면 적용X)),allowEmpty"`
+	Quantity      float64    `json:"Quantity" swagger:"desc(변경할 양(보내지 않으면 적용X)),allowEmpty"`
+	Unit          units.Unit `json:"Unit" swagger:"desc(변경할 양의 단위(보내지 않으면 기존 단위)),allowEmpty"`
+	ServingSizeId int64      `json:"ServingSizeId" swagger:"desc(변경할 serving size ID(보내지 않으면 기존 serving size)),allowEmpty"`
+	Slot          int32      `json:"Slot" swagger:"desc(변경할 끼니(보내지 않으면 적용X)),allowEmpty"`
+	EatenAt       time.Time  `json:"EatenAt" swagger:"desc(변경할 먹은 시간(보내지 않으면 적용X)),allowEmpty"`
+	SessionId     int64      `json:"SessionId" swagger:"desc(옮길 술자리 ID(보내지 않으면 적용X, -1 이면 술자리에서 뺌)),allowEmpty"`
+}
+
+func (MealApiController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	var input MealUpdateInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	entry, err := getOwnMealEntry(ctx, id)
+	if entry == nil {
+		return err
+	}
+
+	if input.Quantity < 0 {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	// food, 양, 단위, serving size 중 하나라도 바뀌면 food의 단위 기준 양을 다시 계산한다.
+	if input.FoodId != 0 || input.Quantity > 0 || input.Unit != units.Unknown || input.ServingSizeId != 0 {
+		foodId := entry.FoodId
+		if input.FoodId != 0 {
+			foodId = input.FoodId
+		}
+
+		food, err := models.Food{}.Get(foodId)
+		if err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		} else if food == nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+		}
+
+		quantity, unit, servingSizeId := entry.LoggedQuantity, entry.LoggedUnit, entry.ServingSizeId
+		if quantity == 0 {
+			// 단위를 기록하기 전에 저장된 entry
+			quantity = entry.Quantity
+		}
+		if input.Quantity > 0 {
+			quantity = input.Quantity
+		}
+		if food.Id != entry.FoodId || input.Unit != units.Unknown {
+			// serving size는 food마다 다르고, 단위를 직접 바꾸면 serving size로 입력하지 않은 것이다.
+			servingSizeId = 0
+			if unit == units.Serving {
+				unit = units.Unknown
+			}
+		}
+		if input.Unit != units.Unknown {
+			unit = input.Unit
+		}
+		if input.ServingSizeId != 0 {
+			servingSizeId = input.ServingSizeId
+		}
+
+		if ok, err := setMealQuantity(ctx, entry, *food, quantity, unit, servingSizeId); !ok {
+			return err
+		}
+	}
+	if input.Slot != 0 {
+		if !models.IsValidMealSlot(input.Slot) {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+		}
+		entry.Slot = input.Slot
+	}
+	if !input.EatenAt.IsZero() {
+		entry.EatenAt = input.EatenAt
+	}
+	if input.SessionId == noDrinkingSession {
+		entry.SessionId = 0
+	} else if input.SessionId != 0 {
+		if session, err := getOwnDrinkingSession(ctx, input.SessionId); session == nil {
+			return err
+		}
+		entry.SessionId = input.SessionId
+	}
+
+	if err = entry.Update(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, nil)
+}
+
+type MealDeleteInput struct {
+	Id int64 `query:"id" swagger:"desc(삭제할 식사 기록의 ID),required"`
+}
+
+func (MealApiController) Delete(ctx echo.Context) error {
+	var input MealDeleteInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	entry, err := getOwnMealEntry(ctx, input.Id)
+	if entry == nil {
+		return err
+	}
+
+	err = models.MealEntry{}.Delete(entry.Id)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, nil)
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/importer"
+	"github.com/labstack/echo"
+	"net/http"
+)
+
+// 가져올 수 있는 식단 기록 형식
+const (
+	mealImportMyFitnessPal = "myfitnesspal"
+	mealImportCSV          = "csv"
+)
+
+type MealImportInput struct {
+	Format  string `query:"format" swagger:"desc(파일 형식 (myfitnesspal: MyFitnessPal 식단 기록 CSV, csv: Columns 로 column을 정한 CSV)),required"`
+	Columns string `query:"columns" swagger:"desc(csv 형식에서 값을 읽을 column (예: Date=Day,Food=Item,Calorie=kcal)(보내지 않으면 값의 이름과 같은 column)),allowEmpty"`
+	DryRun  bool   `query:"dryRun" swagger:"desc(true 이면 저장하지 않고 검증 결과만 반환),allowEmpty"`
+}
+
+// Import 는 다른 앱에서 기록한 식단을 로그인한 user의 식사 기록으로 가져온다.
+// catalog에 없는 food는 catalog를 바꿀 권한이 있는 user만 기록에 적힌 영양소로 새로 만들고,
+// 아니면 가져오지 못한 food로 알려준다.
+func (m MealApiController) Import(ctx echo.Context) error {
+	var input MealImportInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	var source string
+	var columns importer.DiaryColumns
+	switch input.Format {
+	case mealImportMyFitnessPal:
+		source, columns = importer.SourceMyFitnessPal, importer.MyFitnessPalColumns
+	case mealImportCSV:
+		var err error
+		if columns, err = importer.ParseDiaryColumns(input.Columns); err != nil {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+		}
+		source = importer.SourceDiaryCSV
+	default:
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+	defer file.Close()
+
+	createFoods, err := hasRole(ctx, m.Permission.Write)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	im := importer.NewDiary(CurrentUserId(ctx), source, createFoods, input.DryRun)
+	if err := importer.ImportDiaryCSV(im, file, columns); err == importer.ErrDiaryColumns {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	} else if err != nil {
+		ctx.Logger().Errorf("meal import stopped after %d rows: %v", im.Summary.Total, err)
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	logReindexError(ctx, im.Finish())
+
+	return Success(ctx, im.Result())
+}
@@ -0,0 +1,400 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+	"github.com/labstack/echo"
+	"github.com/pangpanglabs/echoswagger"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 한 plan이 가질 수 있는 최대 일 수
+const maxPlanDays = 31
+
+type PlanApiController struct {
+}
+
+func (p PlanApiController) Init(g echoswagger.ApiGroup) {
+	g.SetSecurity("Authorization")
+
+	g.POST("", p.Create, RequireLogin).
+		AddParamBody(PlanCreateInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "생성된 plan의 정보를 반환합니다.", models.PlanJSON{}, nil)
+
+	g.POST("/generate", p.Generate, RequireLogin).
+		AddParamBody(PlanGenerateInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "목표를 허용 오차 안에서 맞추도록 만든 식단을 반환합니다. (저장하지 않으면 plan의 Id 는 0)",
+			models.PlanJSON{}, nil)
+
+	g.GET("/:id", p.GetById, RequireLogin).
+		AddParamQueryNested(PlanGetByIdInput{}).
+		AddResponse(http.StatusOK, "조회할 plan의 정보를 반환합니다.", models.PlanJSON{}, nil)
+	g.GET("/page", p.GetPage, RequireLogin).
+		AddParamQueryNested(PlanGetPageInput{}).
+		AddResponse(http.StatusOK, "조회할 plan 정보들의 페이지를 반환합니다.", PlanGetPageOutput{}, nil)
+
+	g.DELETE("", p.Delete, RequireLogin).
+		AddParamQueryNested(PlanDeleteInput{}).
+		AddResponse(http.StatusOK, "", nil, nil)
+
+	g.POST("/:id/copy", p.Copy, RequireLogin).
+		AddParamBody(PlanCopyInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "복사된 plan의 정보를 반환합니다.", models.PlanJSON{}, nil)
+
+	g.POST("/:id/meals", p.AddMeal, RequireLogin).
+		AddParamBody(PlannedMealInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "추가된 식단을 반환합니다.", models.PlannedMeal{}, nil)
+	g.DELETE("/:id/meals", p.DeleteMeal, RequireLogin).
+		AddParamQueryNested(PlanDeleteMealInput{}).
+		AddResponse(http.StatusOK, "", nil, nil)
+
+	g.GET("/:id/compare", p.Compare, RequireLogin).
+		AddParamQueryNested(PlanGetByIdInput{}).
+		AddResponse(http.StatusOK, "plan과 실제로 먹은 양을 날짜별로 비교합니다.", PlanCompareOutput{}, nil)
+}
+
+// getOwnPlan 은 로그인한 user의 plan만 돌려준다.
+// plan을 돌려주지 못한 경우에는 이미 실패 응답을 보냈으므로 함께 돌려준 error를 그대로 반환하면 된다.
+func getOwnPlan(ctx echo.Context) (*models.Plan, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	plan, err := models.Plan{}.Get(id)
+	if err != nil {
+		return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if plan == nil {
+		return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	} else if plan.UserId != CurrentUserId(ctx) {
+		return nil, Fail(ctx, http.StatusForbidden, factory.NewFailResp(constant.NeedPermission))
+	}
+
+	return plan, nil
+}
+
+func respondPlan(ctx echo.Context, plan *models.Plan) error {
+	result, err := plan.ToJSON()
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, result)
+}
+
+type PlanDayInput struct {
+	DayIndex int32 `json:"DayIndex" swagger:"desc(목표를 적용할 날(0부터 시작)),required"`
+	models.MacroTarget
+}
+type PlannedMealInput struct {
+	DayIndex int32   `json:"DayIndex" swagger:"desc(먹을 날(0부터 시작)),required"`
+	Slot     int32   `json:"Slot" swagger:"desc(끼니 (1: 아침, 2: 점심, 3: 저녁, 4: 간식)),required"`
+	FoodId   int64   `json:"FoodId" swagger:"desc(먹을 food의 ID),required"`
+	Quantity float64 `json:"Quantity" swagger:"desc(먹을 양(food의 Unit 기준)),required"`
+}
+type PlanCreateInput struct {
+	Name       string             `json:"Name" swagger:"desc(plan 이름),required"`
+	StartDate  string             `json:"StartDate" swagger:"desc(시작 날짜(2006-01-02), 템플릿이면 생략),allowEmpty"`
+	Days       int32              `json:"Days" swagger:"desc(plan의 일 수(주 단위는 7)),required"`
+	IsTemplate bool               `json:"IsTemplate" swagger:"desc(템플릿으로 저장할지 여부),allowEmpty"`
+	Target     models.MacroTarget `json:"Target" swagger:"desc(모든 날에 적용할 기본 목표(보내지 않으면 profile로 계산한 목표)),allowEmpty"`
+	DayTargets []PlanDayInput     `json:"DayTargets" swagger:"desc(특정 날의 목표(Target 대신 적용)),allowEmpty"`
+	Meals      []PlannedMealInput `json:"Meals" swagger:"desc(계획한 식단),allowEmpty"`
+}
+
+func (p PlannedMealInput) isValid(days int32) bool {
+	return p.DayIndex >= 0 && p.DayIndex < days && models.IsValidMealSlot(p.Slot) && p.Quantity > 0
+}
+
+func (PlanApiController) Create(ctx echo.Context) error {
+	var input PlanCreateInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if input.Days <= 0 || input.Days > maxPlanDays {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	var startDate time.Time
+	if input.StartDate != "" {
+		var err error
+		if startDate, err = ParseDate(input.StartDate); err != nil {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+		}
+	} else if !input.IsTemplate {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if input.Target.Calorie == 0 {
+		dailyTarget, err := models.DailyTarget(CurrentUserId(ctx), time.Now())
+		if err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		} else if dailyTarget == nil {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+		}
+		input.Target = *dailyTarget
+	}
+
+	targets := make([]models.MacroTarget, input.Days)
+	for idx := range targets {
+		targets[idx] = input.Target
+	}
+	for _, dayTarget := range input.DayTargets {
+		if dayTarget.DayIndex < 0 || dayTarget.DayIndex >= input.Days {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+		}
+		targets[dayTarget.DayIndex] = dayTarget.MacroTarget
+	}
+
+	for _, meal := range input.Meals {
+		if !meal.isValid(input.Days) {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+		}
+
+		food, err := models.Food{}.Get(meal.FoodId)
+		if err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		} else if food == nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+		}
+	}
+
+	newPlan := models.Plan{UserId: CurrentUserId(ctx), Name: input.Name, StartDate: startDate, Days: input.Days,
+		IsTemplate: input.IsTemplate}
+
+	meals := make([]models.PlannedMeal, len(input.Meals))
+	for idx, meal := range input.Meals {
+		meals[idx] = models.PlannedMeal{DayIndex: meal.DayIndex, Slot: meal.Slot, FoodId: meal.FoodId, Quantity: meal.Quantity}
+	}
+
+	if err := models.CreatePlan(&newPlan, targets, meals); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return respondPlan(ctx, &newPlan)
+}
+
+type PlanGetByIdInput struct {
+	Id int64 `query:"id" swagger:"desc(조회할 plan의 ID),required"`
+}
+
+func (PlanApiController) GetById(ctx echo.Context) error {
+	plan, err := getOwnPlan(ctx)
+	if plan == nil {
+		return err
+	}
+
+	return respondPlan(ctx, plan)
+}
+
+type PlanGetPageInput struct {
+	IsTemplate bool `query:"isTemplate" swagger:"desc(템플릿만 조회할지 여부),allowEmpty"`
+	Limit      int  `query:"limit" swagger:"desc(조회할 plan의 개수),required"`
+	Offset     int  `query:"offset" swagger:"desc(조회를 시작할 offset),required"`
+}
+type PlanGetPageOutput struct {
+	PlanList []*models.Plan `json:"PlanList"`
+}
+
+func (PlanApiController) GetPage(ctx echo.Context) error {
+	var input PlanGetPageInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	planList, err := models.Plan{}.GetByUser(CurrentUserId(ctx), input.IsTemplate, input.Offset, input.Limit)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	result := PlanGetPageOutput{PlanList: planList}
+
+	return Success(ctx, result)
+}
+
+type PlanDeleteInput struct {
+	Id int64 `query:"id" swagger:"desc(삭제할 plan의 ID),required"`
+}
+
+func (PlanApiController) Delete(ctx echo.Context) error {
+	var input PlanDeleteInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	plan, err := models.Plan{}.Get(input.Id)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if plan == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	} else if plan.UserId != CurrentUserId(ctx) {
+		return Fail(ctx, http.StatusForbidden, factory.NewFailResp(constant.NeedPermission))
+	}
+
+	if err = (models.Plan{}).Delete(plan.Id); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, nil)
+}
+
+type PlanCopyInput struct {
+	Name       string `json:"Name" swagger:"desc(새 plan 이름(보내지 않으면 원본 이름)),allowEmpty"`
+	StartDate  string `json:"StartDate" swagger:"desc(새 plan의 시작 날짜(2006-01-02), 템플릿이면 생략),allowEmpty"`
+	IsTemplate bool   `json:"IsTemplate" swagger:"desc(템플릿으로 저장할지 여부),allowEmpty"`
+}
+
+func (PlanApiController) Copy(ctx echo.Context) error {
+	var input PlanCopyInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	plan, err := getOwnPlan(ctx)
+	if plan == nil {
+		return err
+	}
+
+	var startDate time.Time
+	if input.StartDate != "" {
+		if startDate, err = ParseDate(input.StartDate); err != nil {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+		}
+	} else if !input.IsTemplate {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	name := input.Name
+	if name == "" {
+		name = plan.Name
+	}
+
+	newPlan, err := plan.Copy(CurrentUserId(ctx), name, startDate, input.IsTemplate)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return respondPlan(ctx, newPlan)
+}
+
+func (PlanApiController) AddMeal(ctx echo.Context) error {
+	var input PlannedMealInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	plan, err := getOwnPlan(ctx)
+	if plan == nil {
+		return err
+	}
+
+	if !input.isValid(plan.Days) {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	food, err := models.Food{}.Get(input.FoodId)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if food == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	newMeal := models.PlannedMeal{PlanId: plan.Id, DayIndex: input.DayIndex, Slot: input.Slot,
+		FoodId: food.Id, Quantity: input.Quantity}
+	if _, err := newMeal.Create(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, newMeal)
+}
+
+type PlanDeleteMealInput struct {
+	MealId int64 `query:"mealId" swagger:"desc(삭제할 식단의 ID),required"`
+}
+
+func (PlanApiController) DeleteMeal(ctx echo.Context) error {
+	var input PlanDeleteMealInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	plan, err := getOwnPlan(ctx)
+	if plan == nil {
+		return err
+	}
+
+	meal, err := models.PlannedMeal{}.Get(input.MealId)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if meal == nil || meal.PlanId != plan.Id {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	if err = (models.PlannedMeal{}).Delete(meal.Id); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, nil)
+}
+
+type PlanDayComparison struct {
+	Date      string             `json:"Date"`
+	Target    models.MacroTarget `json:"Target"`
+	Planned   models.MacroTarget `json:"Planned"`
+	Actual    models.MacroTarget `json:"Actual"`
+	Deviation models.MacroTarget `json:"Deviation"`
+}
+type PlanCompareOutput struct {
+	Plan models.Plan         `json:"Plan"`
+	Days []PlanDayComparison `json:"Days"`
+}
+
+func (PlanApiController) Compare(ctx echo.Context) error {
+	plan, err := getOwnPlan(ctx)
+	if plan == nil {
+		return err
+	}
+
+	if plan.IsTemplate {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	planJSON, err := plan.ToJSON()
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	from := plan.StartDate
+	to := from.AddDate(0, 0, int(plan.Days))
+	dailyIntakes, err := models.SummarizeDailyIntake(CurrentUserId(ctx), from, to)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	actualByDate := make(map[string]models.Intake, len(dailyIntakes))
+	for _, daily := range dailyIntakes {
+		actualByDate[daily.Date] = daily.Intake
+	}
+
+	days := make([]PlanDayComparison, len(planJSON.Days))
+	for idx, day := range planJSON.Days {
+		date := from.AddDate(0, 0, int(day.PlanDay.DayIndex)).Format(DateLayout)
+		actual := actualByDate[date].Macro()
+
+		days[idx] = PlanDayComparison{
+			Date:      date,
+			Target:    day.PlanDay.MacroTarget,
+			Planned:   day.Planned.Macro(),
+			Actual:    actual,
+			Deviation: actual.Sub(day.PlanDay.MacroTarget),
+		}
+	}
+
+	result := PlanCompareOutput{Plan: *plan, Days: days}
+
+	return Success(ctx, result)
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/planner"
+	"github.com/kernelgarden/diet/recommend"
+	"github.com/labstack/echo"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	defaultPlanTolerance = 0.1
+	maxPlanTolerance     = 0.5
+	// 한 portion 의 칼로리가 이보다 적은 food는 간식으로 넣는다.
+	snackCalorie = 150
+)
+
+type PlanMustIncludeInput struct {
+	DayIndex int32   `json:"DayIndex" swagger:"desc(먹을 날(0부터 시작)),required"`
+	Slot     int32   `json:"Slot" swagger:"desc(끼니 (1: 아침, 2: 점심, 3: 저녁, 4: 간식)),required"`
+	FoodId   int64   `json:"FoodId" swagger:"desc(꼭 먹을 food의 ID),required"`
+	Count    float64 `json:"Count" swagger:"desc(먹을 양(food의 첫 번째 serving size, 없으면 기본 양의 개수)),required"`
+}
+type PlanGenerateInput struct {
+	Name               string                 `json:"Name" swagger:"desc(plan 이름(저장할 때만 필요)),allowEmpty"`
+	StartDate          string                 `json:"StartDate" swagger:"desc(시작 날짜(2006-01-02), 템플릿이면 생략),allowEmpty"`
+	Days               int32                  `json:"Days" swagger:"desc(식단을 만들 일 수(주 단위는 7)),required"`
+	IsTemplate         bool                   `json:"IsTemplate" swagger:"desc(템플릿으로 저장할지 여부),allowEmpty"`
+	Target             models.MacroTarget     `json:"Target" swagger:"desc(하루 목표(보내지 않으면 profile로 계산한 목표)),allowEmpty"`
+	Tolerance          float64                `json:"Tolerance" swagger:"desc(목표 대비 허용 오차 비율(기본 0.1, 최대 0.5)),allowEmpty"`
+	ExcludeFoodIds     []int64                `json:"ExcludeFoodIds" swagger:"desc(식단에서 뺄 food의 ID),allowEmpty"`
+	ExcludeCategoryIds []int64                `json:"ExcludeCategoryIds" swagger:"desc(식단에서 뺄 category의 ID),allowEmpty"`
+	FavoriteFoodIds    []int64                `json:"FavoriteFoodIds" swagger:"desc(먼저 넣을 즐겨 먹는 food의 ID),allowEmpty"`
+	MaxRepeatsPerWeek  int                    `json:"MaxRepeatsPerWeek" swagger:"desc(한 주에 같은 food가 나올 수 있는 최대 일 수(보내지 않으면 제한 없음)),allowEmpty"`
+	MustInclude        []PlanMustIncludeInput `json:"MustInclude" swagger:"desc(정한 날에 꼭 넣을 food),allowEmpty"`
+	Save               bool                   `json:"Save" swagger:"desc(만든 식단을 plan으로 저장할지 여부),allowEmpty"`
+}
+
+func toRecommendMacro(target models.MacroTarget) recommend.Macro {
+	return recommend.Macro{Calorie: target.Calorie, Carbohydrate: target.Carbohydrate, Protein: target.Protein, Fat: target.Fat}
+}
+
+// assignMealSlots 는 끼니를 정하지 않은 item을 아침, 점심, 저녁 중 칼로리가 가장 적게 들어간 끼니에 큰 것부터 넣는다.
+// 칼로리가 적은 item은 간식으로 넣는다.
+func assignMealSlots(items []planner.Item) {
+	calories := make(map[int32]float64)
+	for _, item := range items {
+		if item.Slot != 0 {
+			calories[item.Slot] += item.Portion.Macro.Calorie * item.Count
+		}
+	}
+
+	order := make([]int, 0, len(items))
+	for idx, item := range items {
+		if item.Slot == 0 {
+			order = append(order, idx)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return items[order[i]].Portion.Macro.Calorie*items[order[i]].Count > items[order[j]].Portion.Macro.Calorie*items[order[j]].Count
+	})
+
+	for _, idx := range order {
+		calorie := items[idx].Portion.Macro.Calorie * items[idx].Count
+		if items[idx].Portion.Macro.Calorie < snackCalorie {
+			items[idx].Slot = models.MealSlotSnack
+			continue
+		}
+
+		slot := models.MealSlotBreakfast
+		for _, candidate := range []int32{models.MealSlotLunch, models.MealSlotDinner} {
+			if calories[candidate] < calories[slot] {
+				slot = candidate
+			}
+		}
+		items[idx].Slot = slot
+		calories[slot] += calorie
+	}
+}
+
+// Generate 는 목표를 허용 오차 안에서 맞추는 식단을 만든다. Save 가 아니면 저장하지 않고 보여주기만 한다.
+func (PlanApiController) Generate(ctx echo.Context) error {
+	var input PlanGenerateInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if input.Tolerance == 0 {
+		input.Tolerance = defaultPlanTolerance
+	}
+
+	if input.Days <= 0 || input.Days > maxPlanDays || input.Tolerance < 0 || input.Tolerance > maxPlanTolerance ||
+		input.MaxRepeatsPerWeek < 0 || (input.Save && input.Name == "") {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	var startDate time.Time
+	if input.StartDate != "" {
+		var err error
+		if startDate, err = ParseDate(input.StartDate); err != nil {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+		}
+	} else if input.Save && !input.IsTemplate {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	userId := CurrentUserId(ctx)
+
+	if input.Target.Calorie == 0 {
+		dailyTarget, err := models.DailyTarget(userId, time.Now())
+		if err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		} else if dailyTarget == nil {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+		}
+		input.Target = *dailyTarget
+	}
+
+	excluded := make(map[int64]bool, len(input.ExcludeFoodIds))
+	for _, foodId := range input.ExcludeFoodIds {
+		excluded[foodId] = true
+	}
+
+	options := planner.Options{Days: int(input.Days), Target: toRecommendMacro(input.Target), Tolerance: input.Tolerance,
+		Favorites: make(map[int64]bool), MaxRepeatsPerWeek: input.MaxRepeatsPerWeek}
+
+	// 즐겨 먹는 food와 꼭 넣을 food는 남은 칼로리와 상관없이 불러오지만, 뺄 food와 category는 똑같이 뺀다.
+	extraIds := make([]int64, 0, len(input.FavoriteFoodIds)+len(input.MustInclude))
+	for _, foodId := range input.FavoriteFoodIds {
+		if !excluded[foodId] {
+			options.Favorites[foodId] = true
+			extraIds = append(extraIds, foodId)
+		}
+	}
+	for _, mustInclude := range input.MustInclude {
+		if mustInclude.DayIndex < 0 || mustInclude.DayIndex >= input.Days || mustInclude.Count <= 0 ||
+			(mustInclude.Slot != 0 && !models.IsValidMealSlot(mustInclude.Slot)) || excluded[mustInclude.FoodId] {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+		}
+
+		options.MustInclude = append(options.MustInclude, planner.Fixed{DayIndex: int(mustInclude.DayIndex),
+			FoodId: mustInclude.FoodId, Count: mustInclude.Count, Slot: mustInclude.Slot})
+		extraIds = append(extraIds, mustInclude.FoodId)
+	}
+
+	boosts, err := models.FoodBoosts(userId, time.Now().Add(-suggestBoostPeriod))
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	filter := models.RecommendationFilter{ExcludeFoodIds: input.ExcludeFoodIds, ExcludeCategoryIds: input.ExcludeCategoryIds}
+	candidates, err := models.PlanCandidates(filter, input.Target.Calorie, boosts)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	extraCandidates, err := models.FoodCandidates(filter, extraIds, boosts)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	found := make(map[int64]bool, len(extraCandidates))
+	for _, candidate := range extraCandidates {
+		found[candidate.FoodId] = true
+	}
+	for _, mustInclude := range input.MustInclude {
+		if found[mustInclude.FoodId] {
+			continue
+		}
+
+		// 없는 food가 아니면 뺄 category에 있거나 칼로리를 몰라서 넣을 수 없는 food이다.
+		if food, err := (models.Food{}).Get(mustInclude.FoodId); err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		} else if food == nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+		}
+
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	foods := planner.SelectFoods(append(extraCandidates, candidates...), options, planner.MaxFoods)
+
+	days, err := planner.Generate(foods, options)
+	if err == planner.ErrInfeasible {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	} else if err == planner.ErrSearchLimit {
+		// 식단이 없다는 뜻은 아니므로 허용 오차를 넓히거나 후보를 바꿔서 다시 요청할 수 있다.
+		return Fail(ctx, http.StatusServiceUnavailable, factory.NewFailResp(constant.Unknown))
+	} else if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	targets := make([]models.MacroTarget, input.Days)
+	meals := make([]models.PlannedMeal, 0)
+	for idx, day := range days {
+		targets[idx] = input.Target
+
+		assignMealSlots(day.Items)
+		for _, item := range day.Items {
+			meals = append(meals, models.PlannedMeal{DayIndex: int32(day.DayIndex), Slot: item.Slot, FoodId: item.FoodId,
+				Quantity: item.Portion.Quantity * item.Count})
+		}
+	}
+
+	newPlan := models.Plan{UserId: userId, Name: input.Name, StartDate: startDate, Days: input.Days,
+		IsTemplate: input.IsTemplate}
+
+	if !input.Save {
+		result, err := newPlan.PreviewJSON(targets, meals)
+		if err != nil {
+			return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		}
+
+		return Success(ctx, result)
+	}
+
+	if err := models.CreatePlan(&newPlan, targets, meals); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return respondPlan(ctx, &newPlan)
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/calculator"
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+	"github.com/labstack/echo"
+	"github.com/pangpanglabs/echoswagger"
+	"net/http"
+	"time"
+)
+
+type ProfileApiController struct {
+}
+
+func (p ProfileApiController) Init(g echoswagger.ApiGroup) {
+	g.SetSecurity("Authorization")
+
+	g.GET("", p.Get, RequireLogin).
+		AddResponse(http.StatusOK, "로그인한 user의 profile을 반환합니다.", models.Profile{}, nil)
+	g.PUT("", p.Update, RequireLogin).
+		AddParamBody(ProfileUpdateInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "변경된 profile을 반환합니다.", models.Profile{}, nil)
+
+	g.GET("/targets", p.GetTargets, RequireLogin).
+		AddResponse(http.StatusOK, "profile로 계산한 BMR, TDEE와 하루 목표를 반환합니다.", models.ProfileTarget{}, nil)
+}
+
+func (ProfileApiController) Get(ctx echo.Context) error {
+	profile, err := models.Profile{}.GetByUser(CurrentUserId(ctx))
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if profile == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	return Success(ctx, profile)
+}
+
+type ProfileUpdateInput struct {
+	Sex           int32    `json:"Sex" swagger:"desc(성별 (1: 남성, 2: 여성)(보내지 않으면 적용X)),allowEmpty"`
+	BirthDate     string   `json:"BirthDate" swagger:"desc(생년월일(2006-01-02)(보내지 않으면 적용X)),allowEmpty"`
+	Height        float64  `json:"Height" swagger:"desc(키(cm)(보내지 않으면 적용X)),allowEmpty"`
+	Weight        float64  `json:"Weight" swagger:"desc(몸무게(kg)(보내지 않으면 적용X)),allowEmpty"`
+	ActivityLevel int32    `json:"ActivityLevel" swagger:"desc(활동 수준 (1: 거의 없음 ~ 5: 매우 많음)(보내지 않으면 적용X)),allowEmpty"`
+	GoalWeight    float64  `json:"GoalWeight" swagger:"desc(목표 몸무게(kg)(보내지 않으면 적용X)),allowEmpty"`
+	GoalRate      *float64 `json:"GoalRate" swagger:"desc(주당 목표 변화량(kg), 감량이면 음수, 유지면 0(보내지 않으면 적용X)),allowEmpty"`
+	Formula       int32    `json:"Formula" swagger:"desc(BMR 계산식 (1: Mifflin-St Jeor, 2: Harris-Benedict)(보내지 않으면 적용X)),allowEmpty"`
+}
+
+func (ProfileApiController) Update(ctx echo.Context) error {
+	var input ProfileUpdateInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	userId := CurrentUserId(ctx)
+
+	profile, err := models.Profile{}.GetByUser(userId)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	isNew := profile == nil
+	if isNew {
+		profile = &models.Profile{UserId: userId, Formula: calculator.FormulaMifflinStJeor,
+			ActivityLevel: calculator.ActivitySedentary}
+	}
+
+	if input.Sex != 0 {
+		if input.Sex != calculator.SexMale && input.Sex != calculator.SexFemale {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+		}
+		profile.Sex = input.Sex
+	}
+	if input.BirthDate != "" {
+		birthDate, err := ParseDate(input.BirthDate)
+		if err != nil {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+		}
+		profile.BirthDate = birthDate
+	}
+	if input.Height > 0 {
+		profile.Height = input.Height
+	}
+	if input.Weight > 0 {
+		profile.Weight = input.Weight
+	}
+	if input.ActivityLevel != 0 {
+		if input.ActivityLevel < calculator.ActivitySedentary || input.ActivityLevel > calculator.ActivityVeryActive {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+		}
+		profile.ActivityLevel = input.ActivityLevel
+	}
+	if input.GoalWeight > 0 {
+		profile.GoalWeight = input.GoalWeight
+	}
+	if input.GoalRate != nil {
+		profile.GoalRate = *input.GoalRate
+	}
+	if input.Formula != 0 {
+		if input.Formula != calculator.FormulaMifflinStJeor && input.Formula != calculator.FormulaHarrisBenedict {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+		}
+		profile.Formula = input.Formula
+	}
+
+	if isNew {
+		_, err = profile.Create()
+	} else {
+		err = profile.Update()
+	}
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, profile)
+}
+
+func (ProfileApiController) GetTargets(ctx echo.Context) error {
+	profile, err := models.Profile{}.GetByUser(CurrentUserId(ctx))
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if profile == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	target, err := profile.Target(time.Now())
+	if err == calculator.ErrInvalidBody {
+		// 목표를 계산하기에 profile 정보가 부족하다.
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	} else if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, target)
+}
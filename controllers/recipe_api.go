@@ -0,0 +1,293 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/units"
+	"github.com/kernelgarden/diet/validator"
+	"github.com/labstack/echo"
+	"github.com/pangpanglabs/echoswagger"
+	"net/http"
+	"strconv"
+)
+
+// 한 recipe가 가질 수 있는 최대 재료 수
+const maxRecipeIngredients = 100
+
+type RecipeApiController struct {
+	Permission Permission
+}
+
+func (r RecipeApiController) Init(g echoswagger.ApiGroup) {
+	g.SetSecurity("Authorization")
+
+	// recipe는 catalog의 food로 저장되므로 food를 만들 수 있는 user만 만들고 바꿀 수 있다.
+	g.POST("", r.Create, RequireRole(r.Permission.Write)).
+		AddParamBody(RecipeInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "생성된 recipe를 재료 구성과 함께 food 형태로 반환합니다.", models.FoodJSON{}, nil)
+
+	g.GET("/:id", r.GetById, RequireLogin).
+		AddParamPath("", "id", "조회할 recipe의 ID").
+		AddResponse(http.StatusOK, "조회할 recipe를 재료 구성과 함께 food 형태로 반환합니다.", models.FoodJSON{}, nil)
+	g.GET("/page", r.GetPage, RequireLogin).
+		AddParamQueryNested(RecipeGetPageInput{}).
+		AddResponse(http.StatusOK, "내가 만든 recipe 페이지를 반환합니다.", RecipeGetPageOutput{}, nil)
+
+	g.PUT("/:id", r.Update, RequireRole(r.Permission.Write)).
+		AddParamPath("", "id", "변경할 recipe의 ID").
+		AddParamBody(RecipeInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "영양 정보를 다시 계산한 recipe를 food 형태로 반환합니다.", models.FoodJSON{}, nil)
+
+	g.DELETE("", r.Delete, RequireRole(r.Permission.Write)).
+		AddParamQueryNested(RecipeDeleteInput{}).
+		AddResponse(http.StatusOK, "", nil, nil)
+}
+
+// getOwnRecipe 는 로그인한 user의 recipe만 돌려준다.
+// recipe를 돌려주지 못한 경우에는 이미 실패 응답을 보냈으므로 함께 돌려준 error를 그대로 반환하면 된다.
+func getOwnRecipe(ctx echo.Context, id int64) (*models.Recipe, error) {
+	recipe, err := models.Recipe{}.Get(id)
+	if err != nil {
+		return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if recipe == nil {
+		return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	} else if recipe.UserId != CurrentUserId(ctx) {
+		return nil, Fail(ctx, http.StatusForbidden, factory.NewFailResp(constant.NeedPermission))
+	}
+
+	return recipe, nil
+}
+
+func respondRecipeFood(ctx echo.Context, foodId int64) error {
+	food, err := models.Food{}.Get(foodId)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if food == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	result, err := food.ToJSON()
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if result == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	return Success(ctx, result)
+}
+
+type RecipeIngredientInput struct {
+	FoodId        int64      `json:"FoodId" swagger:"desc(재료 food의 ID),required"`
+	Quantity      float64    `json:"Quantity" swagger:"desc(넣은 양),required"`
+	Unit          units.Unit `json:"Unit" swagger:"desc(넣은 양의 단위(보내지 않으면 food의 Unit)),allowEmpty"`
+	ServingSizeId int64      `json:"ServingSizeId" swagger:"desc(넣은 양의 serving size ID(보내면 Quantity 는 인분 수이고 Unit 은 무시)),allowEmpty"`
+}
+type RecipeInput struct {
+	Name        string                  `json:"Name" swagger:"desc(recipe 이름),required"`
+	CategoryId  int64                   `json:"CategoryId" swagger:"desc(recipe의 categoryId),required"`
+	Servings    int32                   `json:"Servings" swagger:"desc(완성된 음식이 몇 인분인지),required"`
+	YieldGrams  float64                 `json:"YieldGrams" swagger:"desc(완성된 음식의 무게(g)(보내지 않으면 재료 무게의 합)),allowEmpty"`
+	Ingredients []RecipeIngredientInput `json:"Ingredients" swagger:"desc(재료 목록),required"`
+}
+
+func (input RecipeInput) isValid() bool {
+	if input.Name == "" || input.Servings <= 0 || input.YieldGrams < 0 ||
+		len(input.Ingredients) == 0 || len(input.Ingredients) > maxRecipeIngredients {
+		return false
+	}
+
+	for _, ingredient := range input.Ingredients {
+		if ingredient.Quantity <= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// buildIngredients 는 입력한 재료의 양을 재료 food의 단위로 바꾼다.
+// recipeFoodId 는 recipe 자신의 food로, 재료로 쓸 수 없다.
+// 재료를 돌려주지 못한 경우에는 이미 실패 응답을 보냈으므로 함께 돌려준 error를 그대로 반환하면 된다.
+func buildIngredients(ctx echo.Context, recipeFoodId int64, inputs []RecipeIngredientInput) ([]models.RecipeIngredient, error) {
+	ingredients := make([]models.RecipeIngredient, len(inputs))
+	for idx, input := range inputs {
+		if recipeFoodId != 0 && input.FoodId == recipeFoodId {
+			return nil, Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+		}
+
+		food, err := models.Food{}.Get(input.FoodId)
+		if err != nil {
+			return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+		} else if food == nil {
+			return nil, Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+		}
+
+		if err := ingredients[idx].SetQuantity(*food, input.Quantity, input.Unit, input.ServingSizeId); err != nil {
+			return nil, failQuantity(ctx, err)
+		}
+	}
+
+	return ingredients, nil
+}
+
+// saveRecipe 는 recipe를 저장하고 저장된 food를 응답으로 보낸다.
+func saveRecipe(ctx echo.Context, food *models.Food, recipe *models.Recipe, ingredients []models.RecipeIngredient) error {
+	category, err := models.Category{}.Get(food.CategoryId)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if category == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	nutrient, micronutrients, err := models.RecipeNutrient(recipe, ingredients)
+	if err == models.ErrRecipeYieldUnknown {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	} else if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	errs := validator.Food(*food)
+	errs = append(errs, validator.Nutrient(*food, nutrient)...)
+	errs = append(errs, validator.Micronutrients(micronutrients)...)
+	if len(errs) > 0 {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFieldFailResp(errs))
+	}
+
+	// 재료에 알코올 양이 없으면 요리의 도수로 계산한다.
+	nutrient.FillAlcohol(*food)
+
+	if err := models.SaveRecipe(food, recipe, ingredients, nutrient, micronutrients); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	logReindexError(ctx, models.ReindexFood(food.Id))
+
+	return respondRecipeFood(ctx, food.Id)
+}
+
+func (RecipeApiController) Create(ctx echo.Context) error {
+	var input RecipeInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if !input.isValid() {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	ingredients, err := buildIngredients(ctx, 0, input.Ingredients)
+	if ingredients == nil {
+		return err
+	}
+
+	newFood := models.Food{CategoryId: input.CategoryId, Name: input.Name}
+	newRecipe := models.Recipe{UserId: CurrentUserId(ctx), Servings: input.Servings, YieldGrams: input.YieldGrams}
+
+	return saveRecipe(ctx, &newFood, &newRecipe, ingredients)
+}
+
+func (RecipeApiController) GetById(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	recipe, err := models.Recipe{}.Get(id)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if recipe == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	return respondRecipeFood(ctx, recipe.FoodId)
+}
+
+type RecipeGetPageInput struct {
+	Limit  int `query:"limit" swagger:"desc(조회할 recipe의 개수),required"`
+	Offset int `query:"offset" swagger:"desc(조회를 시작할 offset),required"`
+}
+type RecipeGetPageOutput struct {
+	RecipeList []*models.Recipe `json:"RecipeList"`
+}
+
+func (RecipeApiController) GetPage(ctx echo.Context) error {
+	var input RecipeGetPageInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	recipeList, err := models.Recipe{}.GetByUser(CurrentUserId(ctx), input.Offset, input.Limit)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	result := RecipeGetPageOutput{RecipeList: recipeList}
+
+	return Success(ctx, result)
+}
+
+// Update 는 recipe를 입력한 값으로 모두 바꾸고 영양 정보를 다시 계산한다.
+func (RecipeApiController) Update(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	var input RecipeInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if !input.isValid() {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	recipe, err := getOwnRecipe(ctx, id)
+	if recipe == nil {
+		return err
+	}
+
+	food, err := models.Food{}.Get(recipe.FoodId)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if food == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	ingredients, err := buildIngredients(ctx, food.Id, input.Ingredients)
+	if ingredients == nil {
+		return err
+	}
+
+	food.Name = input.Name
+	food.CategoryId = input.CategoryId
+	recipe.Servings = input.Servings
+	recipe.YieldGrams = input.YieldGrams
+
+	return saveRecipe(ctx, food, recipe, ingredients)
+}
+
+type RecipeDeleteInput struct {
+	Id int64 `query:"id" swagger:"desc(삭제할 recipe의 ID),required"`
+}
+
+func (RecipeApiController) Delete(ctx echo.Context) error {
+	var input RecipeDeleteInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	recipe, err := getOwnRecipe(ctx, input.Id)
+	if recipe == nil {
+		return err
+	}
+
+	if err = recipe.Delete(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	logReindexError(ctx, models.ReindexFood(recipe.FoodId))
+
+	return Success(ctx, nil)
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/recommend"
+	"github.com/labstack/echo"
+	"github.com/pangpanglabs/echoswagger"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultRecommendationLimit = 10
+	maxRecommendationLimit     = 50
+)
+
+type RecommendationApiController struct {
+}
+
+func (r RecommendationApiController) Init(g echoswagger.ApiGroup) {
+	g.SetSecurity("Authorization")
+
+	g.GET("", r.GetList, RequireLogin).
+		AddParamQueryNested(RecommendationGetListInput{}).
+		AddResponse(http.StatusOK, "오늘 남은 칼로리와 탄단지 안에서 먹을 수 있는 food와 양을 추천 이유와 함께 반환합니다.",
+			RecommendationGetListOutput{}, nil)
+}
+
+type RecommendationGetListInput struct {
+	Date       string `query:"date" swagger:"desc(남은 양을 계산할 날짜(2006-01-02)(보내지 않으면 오늘)),allowEmpty"`
+	CategoryId int64  `query:"categoryId" swagger:"desc(이 category의 food만 추천),allowEmpty"`
+	BrandId    int64  `query:"brandId" swagger:"desc(이 brand의 food만 추천),allowEmpty"`
+	Limit      int    `query:"limit" swagger:"desc(추천할 food의 개수(기본 10, 최대 50)),allowEmpty"`
+}
+type RecommendationGetListOutput struct {
+	Date               string                     `json:"Date"`
+	Target             models.MacroTarget         `json:"Target"`
+	Remaining          models.MacroTarget         `json:"Remaining"`
+	RecommendationList []recommend.Recommendation `json:"RecommendationList"`
+}
+
+func (RecommendationApiController) GetList(ctx echo.Context) error {
+	var input RecommendationGetListInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if input.Limit == 0 {
+		input.Limit = defaultRecommendationLimit
+	} else if input.Limit < 0 || input.Limit > maxRecommendationLimit {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	now := time.Now()
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if input.Date != "" {
+		var err error
+		if date, err = ParseDate(input.Date); err != nil {
+			return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+		}
+	}
+
+	userId := CurrentUserId(ctx)
+
+	// 목표가 없으면 남은 양을 알 수 없으므로 profile을 먼저 채워야 한다.
+	target, err := models.DailyTarget(userId, date)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if target == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	intake, err := models.SummarizeIntake(userId, date, date.AddDate(0, 0, 1))
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	remaining := target.Sub(intake.Intake.Macro())
+
+	boosts, err := models.FoodBoosts(userId, now.Add(-suggestBoostPeriod))
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	result := RecommendationGetListOutput{Date: date.Format(DateLayout), Target: *target, Remaining: remaining,
+		RecommendationList: make([]recommend.Recommendation, 0)}
+
+	// 칼로리가 남지 않았으면 추천할 food가 없다.
+	if remaining.Calorie <= 0 {
+		return Success(ctx, result)
+	}
+
+	filter := models.RecommendationFilter{CategoryId: input.CategoryId, BrandId: input.BrandId}
+	candidates, err := models.RecommendationCandidates(filter, remaining.Calorie, boosts)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	budget := recommend.Macro{Calorie: remaining.Calorie, Carbohydrate: remaining.Carbohydrate, Protein: remaining.Protein,
+		Fat: remaining.Fat}
+	result.RecommendationList = recommend.Rank(budget, candidates, input.Limit)
+
+	return Success(ctx, result)
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+	"github.com/labstack/echo"
+	"github.com/pangpanglabs/echoswagger"
+	"math"
+	"net/http"
+	"time"
+)
+
+type SummaryApiController struct {
+}
+
+func (s SummaryApiController) Init(g echoswagger.ApiGroup) {
+	g.SetSecurity("Authorization")
+
+	g.GET("/day/:date", s.GetDay, RequireLogin).
+		AddParamPath("", "date", "조회할 날짜(2006-01-02)").
+		AddResponse(http.StatusOK, "하루 동안 먹은 영양소의 합계를 반환합니다.", SummaryOutput{}, nil)
+	g.GET("/week/:isoWeek", s.GetWeek, RequireLogin).
+		AddParamPath("", "isoWeek", "조회할 ISO 주(2006-W01)").
+		AddResponse(http.StatusOK, "한 주 동안 먹은 영양소의 합계를 날짜별로 반환합니다.", SummaryOutput{}, nil)
+	g.GET("/month/:month", s.GetMonth, RequireLogin).
+		AddParamPath("", "month", "조회할 달(2006-01)").
+		AddResponse(http.StatusOK, "한 달 동안 먹은 영양소의 합계를 날짜별로 반환합니다.", SummaryOutput{}, nil)
+}
+
+type SummaryOutput struct {
+	From      string                       `json:"From"`
+	To        string                       `json:"To"`
+	Total     models.IntakeSummary         `json:"Total"`
+	Days      []*models.DailyIntakeSummary `json:"Days"`
+	Alcohol   models.AlcoholSummary        `json:"Alcohol"`
+	Energy    models.EnergySummary         `json:"Energy"`
+	Target    *models.MacroTarget          `json:"Target,omitempty"`
+	Remaining *models.MacroTarget          `json:"Remaining,omitempty"`
+}
+
+// parseISOWeek 는 "2006-W01" 형식의 ISO 주를 그 주 월요일 자정으로 변환한다.
+func parseISOWeek(value string) (time.Time, error) {
+	var year, week int
+	if _, err := fmt.Sscanf(value, "%d-W%d", &year, &week); err != nil {
+		return time.Time{}, err
+	}
+
+	// 1월 4일이 들어있는 주가 그 해의 첫 번째 주이다.
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.Local)
+	firstMonday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	monday := firstMonday.AddDate(0, 0, (week-1)*7)
+
+	if y, w := monday.ISOWeek(); y != year || w != week {
+		return time.Time{}, errors.New("invalid iso week")
+	}
+
+	return monday, nil
+}
+
+func summarize(ctx echo.Context, from, to time.Time) error {
+	userId := CurrentUserId(ctx)
+
+	total, err := models.SummarizeIntake(userId, from, to)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	days, err := models.SummarizeDailyIntake(userId, from, to)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	// 알코올 칼로리는 Total 에도 들어있지만, 술이 기간 동안 미친 영향을 보기 위해 따로 나눠서 보여준다.
+	alcohol, err := models.SummarizeAlcohol(userId, from, to, *total, days)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	// 먹은 칼로리에서 운동으로 소비한 칼로리를 뺀 순 칼로리
+	energy, err := models.SummarizeEnergy(userId, from, to, *total, days)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	result := SummaryOutput{
+		From:    from.Format(DateLayout),
+		To:      to.AddDate(0, 0, -1).Format(DateLayout),
+		Total:   *total,
+		Days:    days,
+		Alcohol: *alcohol,
+		Energy:  *energy,
+	}
+
+	// profile이 있으면 기간 동안의 목표와 남은 양을 함께 보여준다.
+	dailyTarget, err := models.DailyTarget(userId, from)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if dailyTarget != nil {
+		target := dailyTarget.Scale(math.Round(to.Sub(from).Hours() / 24))
+		remaining := target.Sub(total.Intake.Macro())
+		result.Target = &target
+		result.Remaining = &remaining
+	}
+
+	return Success(ctx, result)
+}
+
+func (SummaryApiController) GetDay(ctx echo.Context) error {
+	from, err := ParseDate(ctx.Param("date"))
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	return summarize(ctx, from, from.AddDate(0, 0, 1))
+}
+
+func (SummaryApiController) GetWeek(ctx echo.Context) error {
+	from, err := parseISOWeek(ctx.Param("isoWeek"))
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	return summarize(ctx, from, from.AddDate(0, 0, 7))
+}
+
+func (SummaryApiController) GetMonth(ctx echo.Context) error {
+	from, err := time.ParseInLocation("2006-01", ctx.Param("month"), time.Local)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	return summarize(ctx, from, from.AddDate(0, 1, 0))
+}
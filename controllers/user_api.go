@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+	"github.com/labstack/echo"
+	"github.com/pangpanglabs/echoswagger"
+	"net/http"
+	"strconv"
+)
+
+type UserApiController struct {
+}
+
+func (u UserApiController) Init(g echoswagger.ApiGroup) {
+	g.POST("/signup", u.Signup).
+		AddParamBody(UserSignupInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "생성된 user의 정보와 토큰을 반환합니다.", UserTokenOutput{}, nil)
+	g.POST("/login", u.Login).
+		AddParamBody(UserLoginInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "로그인한 user의 정보와 토큰을 반환합니다.", UserTokenOutput{}, nil)
+
+	g.GET("/me", u.GetMe, RequireLogin).
+		AddResponse(http.StatusOK, "로그인한 user의 정보를 반환합니다.", models.User{}, nil).
+		SetSecurity("Authorization")
+
+	g.PUT("/:id/role", u.UpdateRole, RequireRole(models.RoleAdmin)).
+		AddParamBody(UserUpdateRoleInput{}, "body", "", true).
+		AddResponse(http.StatusOK, "", nil, nil).
+		SetSecurity("Authorization")
+}
+
+type UserSignupInput struct {
+	Email    string `json:"Email" swagger:"desc(가입할 email),required"`
+	Name     string `json:"Name" swagger:"desc(가입할 이름),required"`
+	Password string `json:"Password" swagger:"desc(비밀번호),required"`
+}
+type UserTokenOutput struct {
+	User  *models.User `json:"User"`
+	Token string       `json:"Token"`
+}
+
+func (UserApiController) Signup(ctx echo.Context) error {
+	var input UserSignupInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if input.Email == "" || input.Password == "" {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	exist, err := models.User{}.GetByEmail(input.Email)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if exist != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	// 가입한 user는 조회만 할 수 있고, admin이 권한을 부여한다. 처음 admin은 grant-admin 명령으로 정한다.
+	newUser := models.User{Email: input.Email, Name: input.Name, Role: models.RoleViewer}
+	if err := newUser.SetPassword(input.Password); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	if _, err := newUser.Create(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	token, err := factory.NewToken(newUser.Id)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, UserTokenOutput{User: &newUser, Token: token})
+}
+
+type UserLoginInput struct {
+	Email    string `json:"Email" swagger:"desc(로그인할 email),required"`
+	Password string `json:"Password" swagger:"desc(비밀번호),required"`
+}
+
+func (UserApiController) Login(ctx echo.Context) error {
+	var input UserLoginInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	user, err := models.User{}.GetByEmail(input.Email)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if user == nil || !user.CheckPassword(input.Password) {
+		return Fail(ctx, http.StatusUnauthorized, factory.NewFailResp(constant.Wrong))
+	}
+
+	token, err := factory.NewToken(user.Id)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, UserTokenOutput{User: user, Token: token})
+}
+
+func (UserApiController) GetMe(ctx echo.Context) error {
+	user, err := models.User{}.Get(CurrentUserId(ctx))
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if user == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	return Success(ctx, user)
+}
+
+type UserUpdateRoleInput struct {
+	Role int32 `json:"Role" swagger:"desc(변경할 role (1: viewer, 2: contributor, 3: moderator, 4: admin)),required"`
+}
+
+func (UserApiController) UpdateRole(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	var input UserUpdateRoleInput
+	if err := ctx.Bind(&input); err != nil {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.InvalidRequestFormat))
+	}
+
+	if !models.IsValidRole(input.Role) {
+		return Fail(ctx, http.StatusBadRequest, factory.NewFailResp(constant.Invalid))
+	}
+
+	user, err := models.User{}.Get(id)
+	if err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	} else if user == nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.InExist))
+	}
+
+	user.Role = input.Role
+	if err = user.Update(); err != nil {
+		return Fail(ctx, http.StatusInternalServerError, factory.NewFailResp(constant.Unknown))
+	}
+
+	return Success(ctx, nil)
+}
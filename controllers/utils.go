@@ -4,8 +4,11 @@ import (
 	"github.com/kernelgarden/diet/constant"
 	"github.com/labstack/echo"
 	"net/http"
+	"time"
 )
 
+const DateLayout = "2006-01-02"
+
 func Fail(ctx echo.Context, statusCode int, failResp constant.FailResp) error {
 	ctx.Logger().Errorf("request: %v, statusCode: %v, failResp: %s\n", ctx.Request(), statusCode, failResp)
 	return ctx.JSON(statusCode, failResp)
@@ -14,3 +17,25 @@ func Fail(ctx echo.Context, statusCode int, failResp constant.FailResp) error {
 func Success(ctx echo.Context, resp interface{}) error {
 	return ctx.JSON(http.StatusOK, resp)
 }
+
+// logReindexError 는 검색 색인 갱신 실패를 기록한다.
+// DB에는 이미 반영됐으므로 요청은 성공으로 처리하고, 색인은 서버를 다시 띄울 때 다시 만들어진다.
+func logReindexError(ctx echo.Context, err error) {
+	if err != nil {
+		ctx.Logger().Errorf("search index update failed: %v", err)
+	}
+}
+
+func CurrentUserId(ctx echo.Context) int64 {
+	userId, ok := ctx.Get(constant.CtxUserId).(int64)
+	if !ok {
+		return 0
+	}
+
+	return userId
+}
+
+// ParseDate 는 "2006-01-02" 형식의 날짜를 서버 시간대의 자정으로 변환한다.
+func ParseDate(value string) (time.Time, error) {
+	return time.ParseInLocation(DateLayout, value, time.Local)
+}
@@ -28,3 +28,11 @@ func NewFailResp(failCode int) constant.FailResp {
 
 	return constant.FailResp{FailCode: failCode, Message: msg}
 }
+
+// NewFieldFailResp 는 잘못된 field 마다 이유를 알려주는 Invalid 응답을 만든다.
+func NewFieldFailResp(fields []constant.FieldError) constant.FailResp {
+	resp := NewFailResp(constant.Invalid)
+	resp.Fields = fields
+
+	return resp
+}
@@ -0,0 +1,36 @@
+package factory
+
+import (
+	"github.com/go-xorm/xorm"
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/search"
+	"sync"
+)
+
+var searchIndex search.Index
+var searchOnce sync.Once
+
+var suggester = search.NewSuggester()
+
+func SearchIndex() search.Index {
+	searchOnce.Do(func() {
+		var driver string
+		if c, ok := constant.GlobalCtx.Value(constant.CtxConfig).(*constant.Config); ok {
+			driver = c.Search.Driver
+		}
+
+		switch driver {
+		case "sql":
+			searchIndex = search.NewSQLIndex(DB().(*xorm.Engine))
+		default:
+			searchIndex = search.NewMemoryIndex()
+		}
+	})
+
+	return searchIndex
+}
+
+// Suggester 는 자동 완성에 쓰는 food 이름 trie 이다. 항상 서버 메모리에 둔다.
+func Suggester() *search.Suggester {
+	return suggester
+}
@@ -0,0 +1,74 @@
+package factory
+
+import (
+	"errors"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/kernelgarden/diet/constant"
+	"strconv"
+	"time"
+)
+
+const defaultTokenExpireHours = 24 * 7
+
+func authConfig() (*constant.Config, error) {
+	config := constant.GlobalCtx.Value(constant.CtxConfig)
+	if config == nil {
+		return nil, errors.New("config file is empty")
+	}
+
+	c, isValid := config.(*constant.Config)
+	if !isValid {
+		return nil, errors.New("config file is broken")
+	}
+
+	if c.Auth.Secret == "" {
+		return nil, errors.New("auth secret is empty")
+	}
+
+	return c, nil
+}
+
+// NewToken 은 userId 에 대한 서명된 토큰을 발급한다.
+func NewToken(userId int64) (string, error) {
+	c, err := authConfig()
+	if err != nil {
+		return "", err
+	}
+
+	expireHours := c.Auth.ExpireHours
+	if expireHours <= 0 {
+		expireHours = defaultTokenExpireHours
+	}
+
+	now := time.Now()
+	claims := jwt.StandardClaims{
+		Subject:   strconv.FormatInt(userId, 10),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Duration(expireHours) * time.Hour).Unix(),
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(c.Auth.Secret))
+}
+
+// ParseToken 은 토큰의 서명과 만료 시간을 검증하고 userId 를 돌려준다.
+func ParseToken(tokenString string) (int64, error) {
+	c, err := authConfig()
+	if err != nil {
+		return 0, err
+	}
+
+	var claims jwt.StandardClaims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(c.Auth.Secret), nil
+	})
+	if err != nil {
+		return 0, err
+	} else if !token.Valid {
+		return 0, errors.New("invalid token")
+	}
+
+	return strconv.ParseInt(claims.Subject, 10, 64)
+}
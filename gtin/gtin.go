@@ -0,0 +1,80 @@
+// Package gtin 은 상품 바코드(EAN-13, UPC-A, EAN-8, GTIN-14)를 검증하고 13자리로 맞춘다.
+package gtin
+
+import (
+	"errors"
+	"strings"
+)
+
+// Length 는 정규화한 바코드의 길이이다.
+const Length = 13
+
+var (
+	ErrInvalidCharacter  = errors.New("barcode must contain only digits")
+	ErrInvalidLength     = errors.New("barcode must be 8, 12, 13 or 14 digits")
+	ErrInvalidCheckDigit = errors.New("invalid barcode check digit")
+	ErrPlaceholder       = errors.New("barcode must not be all zeros")
+)
+
+// IsValid 는 code 의 마지막 자리가 올바른 check digit 인지 확인한다.
+// 오른쪽 끝(check digit 제외)부터 3, 1 을 번갈아 곱해서 더하므로 앞에 0 을 붙여도 결과가 같다.
+func IsValid(code string) bool {
+	if len(code) < 2 {
+		return false
+	}
+
+	sum := 0
+	for idx := len(code) - 2; idx >= 0; idx-- {
+		c := code[idx]
+		if c < '0' || c > '9' {
+			return false
+		}
+
+		digit := int(c - '0')
+		if (len(code)-2-idx)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	last := code[len(code)-1]
+
+	return last >= '0' && last <= '9' && int(last-'0') == (10-sum%10)%10
+}
+
+// Normalize 는 공백과 '-' 를 뺀 바코드를 검증하고 13자리(EAN-13)로 맞춘다.
+// UPC-A 와 EAN-8 은 앞에 0 을 붙이고, 0 으로 시작하는 GTIN-14 는 앞의 0 을 뺀다. 모두 0 인 바코드는 받지 않는다.
+func Normalize(code string) (string, error) {
+	code = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code))
+
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return "", ErrInvalidCharacter
+		}
+	}
+
+	switch len(code) {
+	case 8, 12, 13:
+	case 14:
+		if code[0] != '0' {
+			// 물류 단위(indicator digit 1~8)는 낱개 상품이 아니다.
+			return "", ErrInvalidLength
+		}
+	default:
+		return "", ErrInvalidLength
+	}
+
+	if !IsValid(code) {
+		return "", ErrInvalidCheckDigit
+	}
+	// 모두 0 인 바코드도 check digit 은 맞지만, 바코드가 없는 상품에 채워 넣는 값이라 여러 상품이 같이 쓴다.
+	if strings.Trim(code, "0") == "" {
+		return "", ErrPlaceholder
+	}
+
+	if len(code) > Length {
+		return code[len(code)-Length:], nil
+	}
+
+	return strings.Repeat("0", Length-len(code)) + code, nil
+}
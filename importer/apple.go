@@ -0,0 +1,195 @@
+package importer
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const appleDateLayout = "2006-01-02 15:04:05 -0700"
+
+// Apple Health 의 record type 과 운동 종류 앞에 붙는 이름
+const (
+	appleBodyMass      = "HKQuantityTypeIdentifierBodyMass"
+	appleBodyFat       = "HKQuantityTypeIdentifierBodyFatPercentage"
+	appleWaist         = "HKQuantityTypeIdentifierWaistCircumference"
+	appleActiveEnergy  = "HKQuantityTypeIdentifierActiveEnergyBurned"
+	appleWorkoutPrefix = "HKWorkoutActivityType"
+)
+
+var appleWorkoutKinds = map[string]string{
+	"Walking":                     "walking",
+	"Running":                     "running",
+	"Cycling":                     "cycling",
+	"Swimming":                    "swimming",
+	"TraditionalStrengthTraining": "strength",
+	"FunctionalStrengthTraining":  "strength",
+	"Yoga":                        "yoga",
+	"Hiking":                      "hiking",
+}
+
+// 몸무게와 길이 단위를 kg, cm 로 바꿀 때 곱하는 값
+var appleUnitFactors = map[string]float64{
+	"kg": 1, "g": 0.001, "lb": 0.45359237, "st": 6.35029318,
+	"cm": 1, "m": 100, "mm": 0.1, "in": 2.54, "ft": 30.48,
+}
+
+type appleRecord struct {
+	Type       string `xml:"type,attr"`
+	SourceName string `xml:"sourceName,attr"`
+	Unit       string `xml:"unit,attr"`
+	Value      string `xml:"value,attr"`
+	StartDate  string `xml:"startDate,attr"`
+}
+
+type appleWorkout struct {
+	ActivityType          string `xml:"workoutActivityType,attr"`
+	SourceName            string `xml:"sourceName,attr"`
+	Duration              string `xml:"duration,attr"`
+	DurationUnit          string `xml:"durationUnit,attr"`
+	TotalEnergyBurned     string `xml:"totalEnergyBurned,attr"`
+	TotalEnergyBurnedUnit string `xml:"totalEnergyBurnedUnit,attr"`
+	StartDate             string `xml:"startDate,attr"`
+	EndDate               string `xml:"endDate,attr"`
+	// 최근 export 는 소비 칼로리를 속성 대신 WorkoutStatistics 로 적는다.
+	Statistics []struct {
+		Type string `xml:"type,attr"`
+		Sum  string `xml:"sum,attr"`
+		Unit string `xml:"unit,attr"`
+	} `xml:"WorkoutStatistics"`
+}
+
+func (r appleRecord) record(line int) (BodyRecord, bool) {
+	record := BodyRecord{Line: line, Source: SourceAppleHealth,
+		SourceId: sourceKey(r.SourceName, r.Type, r.StartDate, r.Value)}
+
+	value, err := strconv.ParseFloat(r.Value, 64)
+	if err != nil {
+		return record, false
+	}
+	if record.MeasuredAt, err = time.Parse(appleDateLayout, r.StartDate); err != nil {
+		return record, false
+	}
+
+	switch r.Type {
+	case appleBodyMass:
+		factor, ok := appleUnitFactors[r.Unit]
+		if !ok {
+			return record, false
+		}
+		record.Weight = value * factor
+	case appleBodyFat:
+		// 비율(0.2)로 적혀 있다.
+		record.BodyFat = value * 100
+	case appleWaist:
+		factor, ok := appleUnitFactors[r.Unit]
+		if !ok {
+			return record, false
+		}
+		record.Waist = value * factor
+	}
+
+	return record, true
+}
+
+func (w appleWorkout) record(line int) (WorkoutRecord, bool) {
+	record := WorkoutRecord{Line: line, Source: SourceAppleHealth, Kind: workoutOther,
+		SourceId: sourceKey(w.SourceName, w.ActivityType, w.StartDate, w.EndDate)}
+	if kind, ok := appleWorkoutKinds[strings.TrimPrefix(w.ActivityType, appleWorkoutPrefix)]; ok {
+		record.Kind = kind
+	}
+
+	var err error
+	if record.StartedAt, err = time.Parse(appleDateLayout, w.StartDate); err != nil {
+		return record, false
+	}
+
+	if duration, err := strconv.ParseFloat(w.Duration, 64); err == nil {
+		switch w.DurationUnit {
+		case "min", "":
+			record.Minutes = duration
+		case "s":
+			record.Minutes = duration / 60
+		case "hr":
+			record.Minutes = duration * 60
+		}
+	} else if endedAt, err := time.Parse(appleDateLayout, w.EndDate); err == nil {
+		record.Minutes = endedAt.Sub(record.StartedAt).Minutes()
+	}
+
+	energy, unit := w.TotalEnergyBurned, w.TotalEnergyBurnedUnit
+	for _, statistics := range w.Statistics {
+		if energy == "" && statistics.Type == appleActiveEnergy {
+			energy, unit = statistics.Sum, statistics.Unit
+		}
+	}
+	if calorie, err := strconv.ParseFloat(energy, 64); err == nil {
+		switch unit {
+		case "kcal", "Cal", "":
+			record.Calorie = calorie
+		case "kJ":
+			record.Calorie = calorie / 4.184
+		}
+	}
+
+	return record, true
+}
+
+// ImportAppleHealth 는 Apple 건강 앱에서 내보낸 export.xml 의 체성분 Record 와 Workout 을 im 으로 가져온다.
+// 파일이 커서 element 하나씩 읽고, 걸음 수처럼 가져오지 않는 Record 는 건너뛴다.
+func ImportAppleHealth(im *HealthImporter, reader io.Reader) error {
+	decoder := xml.NewDecoder(reader)
+
+	line := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch element.Name.Local {
+		case "Record":
+			var r appleRecord
+			if err := decoder.DecodeElement(&r, &element); err != nil {
+				return err
+			}
+			if r.Type != appleBodyMass && r.Type != appleBodyFat && r.Type != appleWaist {
+				continue
+			}
+
+			line++
+			record, ok := r.record(line)
+			if !ok {
+				im.Summary.Fail(RowError{Line: line, Field: r.Type, Message: "값이나 시간을 읽을 수 없습니다."})
+				continue
+			}
+			if err := im.ImportBody(record); err != nil {
+				return err
+			}
+		case "Workout":
+			var w appleWorkout
+			if err := decoder.DecodeElement(&w, &element); err != nil {
+				return err
+			}
+
+			line++
+			record, ok := w.record(line)
+			if !ok {
+				im.Summary.Fail(RowError{Line: line, Field: "Workout", Message: "운동한 시간을 읽을 수 없습니다."})
+				continue
+			}
+			if err := im.ImportWorkout(record); err != nil {
+				return err
+			}
+		}
+	}
+}
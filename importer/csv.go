@@ -0,0 +1,205 @@
+package importer
+
+import (
+	"encoding/csv"
+	"github.com/kernelgarden/diet/gtin"
+	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/units"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CSV 의 기본 column. 뒤에 models.MicronutrientCodes() 의 비타민/무기질 column이 붙는다.
+// Unit 은 "g", "ml" 같은 단위 기호이고, 빈 칸인 선택 항목은 값을 모르는 것으로 본다.
+var csvColumns = []string{"Name", "Brand", "Category", "Barcode", "Weight", "Density", "Abv",
+	"Carbohydrate", "Protein", "SaturatedFat", "UnSaturatedFat", "TransFat", "PerUnit", "Calorie", "Unit",
+	"Sodium", "Sugars", "DietaryFiber", "Cholesterol", "Alcohol"}
+
+func CSVHeader() []string {
+	return append(append([]string{}, csvColumns...), models.MicronutrientCodes()...)
+}
+
+// csvRow 는 header 이름으로 한 줄의 값을 읽으면서 잘못된 값을 모은다.
+type csvRow struct {
+	line    int
+	columns map[string]int
+	values  []string
+	errs    []RowError
+}
+
+func (r *csvRow) get(column string) string {
+	idx, ok := r.columns[strings.ToLower(column)]
+	if !ok || idx >= len(r.values) {
+		return ""
+	}
+
+	return strings.TrimSpace(r.values[idx])
+}
+
+func (r *csvRow) fail(column, message string) {
+	r.errs = append(r.errs, RowError{Line: r.line, Field: column, Message: message})
+}
+
+func (r *csvRow) float(column string) float64 {
+	value := r.get(column)
+	if value == "" {
+		return 0
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		r.fail(column, "숫자가 아닙니다.")
+	}
+
+	return parsed
+}
+
+func (r *csvRow) optionalFloat(column string) *float32 {
+	if r.get(column) == "" {
+		return nil
+	}
+
+	value := float32(r.float(column))
+
+	return &value
+}
+
+func (r *csvRow) record() Record {
+	record := Record{Line: r.line, Name: r.get("Name"), BrandName: r.get("Brand"), CategoryName: r.get("Category"),
+		Weight: r.float("Weight"), Density: r.float("Density"), Abv: r.float("Abv"),
+		Carbohydrate: float32(r.float("Carbohydrate")), Protein: float32(r.float("Protein")),
+		SaturatedFat: float32(r.float("SaturatedFat")), UnSaturatedFat: float32(r.float("UnSaturatedFat")),
+		TransFat: float32(r.float("TransFat")), PerUnit: int32(r.float("PerUnit")), Calorie: int64(r.float("Calorie") + 0.5),
+		Sodium: r.optionalFloat("Sodium"), Sugars: r.optionalFloat("Sugars"),
+		DietaryFiber: r.optionalFloat("DietaryFiber"), Cholesterol: r.optionalFloat("Cholesterol"),
+		Alcohol:        r.optionalFloat("Alcohol"),
+		Micronutrients: make(map[string]float32)}
+
+	if code := r.get("Barcode"); code != "" {
+		barcode, err := gtin.Normalize(code)
+		if err != nil {
+			r.fail("Barcode", "올바르지 않은 바코드입니다.")
+		}
+		record.Barcode = barcode
+	}
+
+	if symbol := r.get("Unit"); symbol != "" {
+		unit, err := units.Parse(symbol)
+		if err != nil {
+			r.fail("Unit", "알 수 없는 단위입니다.")
+		}
+		record.Unit = unit
+	} else {
+		record.Unit = units.Gram
+	}
+
+	for _, code := range models.MicronutrientCodes() {
+		if r.get(code) != "" {
+			record.Micronutrients[code] = float32(r.float(code))
+		}
+	}
+
+	return record
+}
+
+// ImportCSV 는 header가 있는 CSV 를 한 줄씩 읽어 im 으로 가져온다.
+// column 순서는 상관없고 header 이름은 대소문자를 구분하지 않는다.
+func ImportCSV(im *Importer, reader io.Reader) error {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return err
+	}
+
+	columns := make(map[string]int, len(header))
+	for idx, name := range header {
+		// 엑셀에서 저장한 CSV 는 BOM 으로 시작한다.
+		name = strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")
+		columns[strings.ToLower(name)] = idx
+	}
+
+	for line := 2; ; line++ {
+		values, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			if _, ok := err.(*csv.ParseError); ok {
+				im.Summary.Fail(RowError{Line: line, Message: err.Error()})
+				continue
+			}
+			return err
+		}
+
+		row := csvRow{line: line, columns: columns, values: values}
+		record := row.record()
+		if len(row.errs) > 0 {
+			im.Summary.Fail(row.errs...)
+			continue
+		}
+
+		if err := im.Import(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatOptional(value *float32) string {
+	if value == nil {
+		return ""
+	}
+
+	return formatFloat(float64(*value))
+}
+
+// CSVWriter 는 ImportCSV 로 다시 가져올 수 있는 형식으로 record를 쓴다.
+type CSVWriter struct {
+	writer *csv.Writer
+	codes  []string
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{writer: csv.NewWriter(w), codes: models.MicronutrientCodes()}
+}
+
+func (w *CSVWriter) WriteHeader() error {
+	return w.writer.Write(CSVHeader())
+}
+
+func (w *CSVWriter) Write(record Record) error {
+	unit := record.Unit
+	if unit == units.Unknown {
+		unit = units.Gram
+	}
+
+	values := []string{record.Name, record.BrandName, record.CategoryName, record.Barcode, formatFloat(record.Weight),
+		formatFloat(record.Density), formatFloat(record.Abv), formatFloat(float64(record.Carbohydrate)), formatFloat(float64(record.Protein)),
+		formatFloat(float64(record.SaturatedFat)), formatFloat(float64(record.UnSaturatedFat)),
+		formatFloat(float64(record.TransFat)), strconv.FormatInt(int64(record.PerUnit), 10),
+		strconv.FormatInt(record.Calorie, 10), unit.String(), formatOptional(record.Sodium),
+		formatOptional(record.Sugars), formatOptional(record.DietaryFiber), formatOptional(record.Cholesterol),
+		formatOptional(record.Alcohol)}
+	for _, code := range w.codes {
+		if amount, ok := record.Micronutrients[code]; ok {
+			values = append(values, formatFloat(float64(amount)))
+		} else {
+			values = append(values, "")
+		}
+	}
+
+	return w.writer.Write(values)
+}
+
+// Flush 는 버퍼에 남은 줄을 쓰고 그동안의 쓰기 에러를 돌려준다.
+func (w *CSVWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
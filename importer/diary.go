@@ -0,0 +1,646 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/units"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 식단 기록을 가져온 앱
+const (
+	SourceMyFitnessPal = "myfitnesspal"
+	SourceDiaryCSV     = "diary-csv"
+)
+
+// 식단 기록에만 있고 catalog에 없는 food를 만들 때 넣는 category
+const diaryCategory = "가져온 식단"
+
+var ErrDiaryColumns = errors.New("식단 CSV 에 날짜나 음식 이름 column이 없습니다")
+
+// 먹은 시간이 없는 기록은 끼니마다 이 시간에 먹은 것으로 본다.
+var diarySlotHours = map[int32]int{
+	models.MealSlotBreakfast: 8,
+	models.MealSlotLunch:     12,
+	models.MealSlotDinner:    18,
+	models.MealSlotSnack:     15,
+}
+
+var diarySlotNames = map[string]int32{
+	"breakfast": models.MealSlotBreakfast, "아침": models.MealSlotBreakfast,
+	"lunch": models.MealSlotLunch, "점심": models.MealSlotLunch,
+	"dinner": models.MealSlotDinner, "저녁": models.MealSlotDinner,
+	"snack": models.MealSlotSnack, "snacks": models.MealSlotSnack, "간식": models.MealSlotSnack,
+}
+
+// 날짜 column 에 시간까지 적혀 있을 수 있다.
+var (
+	diaryDateTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "1/2/2006 15:04"}
+	diaryDateLayouts     = []string{"2006-01-02", "2006/01/02", "2006.01.02", "1/2/2006"}
+	diaryTimeLayouts     = []string{"15:04", "15:04:05", "3:04 PM", "3:04PM"}
+)
+
+// 단위 기호가 아닌 이름으로 적힌 단위
+var diaryUnitNames = map[string]units.Unit{
+	"gram": units.Gram, "kilogram": units.Kilogram, "ounce": units.Ounce, "milliliter": units.Milliliter,
+	"liter": units.Liter, "tablespoon": units.Tablespoon, "teaspoon": units.Teaspoon,
+	"개": units.Piece, "인분": units.Serving,
+}
+
+// DiaryRecord 는 가져온 식사 기록 하나이다. 영양소는 먹은 양 전체의 값이다.
+type DiaryRecord struct {
+	Line      int
+	EatenAt   time.Time
+	Slot      int32
+	FoodName  string
+	BrandName string
+	// 먹은 양. 모르면 0이고 1인분으로 본다. Unit 이 Unknown 이면 food의 단위(새로 만드는 food는 g)로 본다.
+	Quantity float64
+	Unit     units.Unit
+
+	Calorie        float64
+	Carbohydrate   float32
+	Protein        float32
+	SaturatedFat   float32
+	UnSaturatedFat float32
+	TransFat       float32
+	Sodium         *float32
+	Sugars         *float32
+	DietaryFiber   *float32
+	Cholesterol    *float32
+}
+
+func (r DiaryRecord) Validate() []RowError {
+	errs := make([]RowError, 0)
+	fail := func(field, message string) {
+		errs = append(errs, RowError{Line: r.Line, Field: field, Message: message})
+	}
+
+	if r.EatenAt.IsZero() {
+		fail("Date", "먹은 날짜가 없습니다.")
+	}
+	if !models.IsValidMealSlot(r.Slot) {
+		fail("Meal", "알 수 없는 끼니입니다.")
+	}
+	if r.FoodName == "" {
+		fail("Food", "음식 이름이 없습니다.")
+	}
+	if r.Quantity < 0 {
+		fail("Quantity", "음수일 수 없습니다.")
+	}
+	if !units.IsValid(r.Unit) {
+		fail("Unit", "알 수 없는 단위입니다.")
+	}
+	if r.Calorie < 0 {
+		fail("Calorie", "음수일 수 없습니다.")
+	}
+
+	for field, value := range map[string]float32{"Carbohydrate": r.Carbohydrate, "Protein": r.Protein,
+		"SaturatedFat": r.SaturatedFat, "UnSaturatedFat": r.UnSaturatedFat, "TransFat": r.TransFat} {
+		if value < 0 {
+			fail(field, "음수일 수 없습니다.")
+		}
+	}
+	for field, value := range map[string]*float32{"Sodium": r.Sodium, "Sugars": r.Sugars,
+		"DietaryFiber": r.DietaryFiber, "Cholesterol": r.Cholesterol} {
+		if value != nil && *value < 0 {
+			fail(field, "음수일 수 없습니다.")
+		}
+	}
+
+	return errs
+}
+
+// amount 는 먹은 양과 단위이다. 양을 모르면 1인분이다.
+func (r DiaryRecord) amount() (float64, units.Unit) {
+	if r.Quantity <= 0 {
+		return 1, units.Serving
+	}
+
+	return r.Quantity, r.Unit
+}
+
+// foodRecord 는 catalog에 없는 food를 이 기록의 영양소로 만들 record로 바꾼다.
+// 무게나 부피로 먹었으면 100g(ml) 당, 개수로 먹었으면 1개(인분) 당 값으로 바꾼다.
+func (r DiaryRecord) foodRecord() (Record, error) {
+	quantity, unit := r.amount()
+
+	perUnit, base := int32(1), unit
+	switch unit.Kind() {
+	case units.KindMass:
+		perUnit, base = 100, units.Gram
+	case units.KindVolume:
+		perUnit, base = 100, units.Milliliter
+	}
+
+	converted, err := units.Convert(quantity, unit, base, 0)
+	if err != nil {
+		return Record{}, err
+	}
+
+	scale := float64(perUnit) / converted
+	scaled := func(value float32) float32 {
+		return float32(float64(value) * scale)
+	}
+	scaledOptional := func(value *float32) *float32 {
+		if value == nil {
+			return nil
+		}
+
+		result := scaled(*value)
+		return &result
+	}
+
+	return Record{Line: r.Line, Name: r.FoodName, BrandName: r.BrandName, CategoryName: diaryCategory,
+		Carbohydrate: scaled(r.Carbohydrate), Protein: scaled(r.Protein), SaturatedFat: scaled(r.SaturatedFat),
+		UnSaturatedFat: scaled(r.UnSaturatedFat), TransFat: scaled(r.TransFat), PerUnit: perUnit,
+		Calorie: int64(r.Calorie*scale + 0.5), Unit: base,
+		Sodium: scaledOptional(r.Sodium), Sugars: scaledOptional(r.Sugars),
+		DietaryFiber: scaledOptional(r.DietaryFiber), Cholesterol: scaledOptional(r.Cholesterol)}, nil
+}
+
+type DiarySummary struct {
+	Entries Summary `json:"Entries"`
+	Foods   Summary `json:"Foods"` // catalog에 없어서 새로 만든 food
+	// catalog에 없고 새로 만들 수도 없어서 가져오지 못한 food ("brand - 이름")
+	Unmatched []string `json:"Unmatched"`
+}
+
+// DiaryImporter 는 다른 앱에서 기록한 한 user의 식사 기록을 가져온다.
+// food는 이름과 brand가 같은 것을 catalog에서 찾고, 없으면 createFoods 일 때만 기록에 적힌 영양소로 새로 만든다.
+// 원본에 ID가 없으므로 날짜, 끼니, food와 그 끼니에서 food가 나온 순서로 같은 기록을 찾는다.
+// 이미 가져온 기록은 양이나 시간이 바뀌었으면 바꾸고, 아니면 건너뛰므로 같은 파일을 다시 가져와도 된다.
+type DiaryImporter struct {
+	Summary Summary
+
+	userId int64
+	source string
+	// catalog에 없는 food를 새로 만들지 여부. catalog를 바꿀 권한이 있을 때만 켠다.
+	createFoods bool
+	foods       *Importer
+	// 이름과 brand로 찾은 food. DryRun 에서 새로 만드는 food는 nil 이다.
+	cache map[string]*models.Food
+	// catalog에 없어서 가져오지 못한 food
+	unmatched     map[string]bool
+	unmatchedKeys []string
+	// 날짜, 끼니, food가 같은 기록이 파일 안에서 나온 횟수
+	occurrences map[string]int
+}
+
+func NewDiary(userId int64, source string, createFoods, dryRun bool) *DiaryImporter {
+	return &DiaryImporter{Summary: Summary{DryRun: dryRun, Errors: make([]RowError, 0)}, userId: userId,
+		source: source, createFoods: createFoods, foods: New(dryRun), cache: make(map[string]*models.Food),
+		unmatched: make(map[string]bool), unmatchedKeys: make([]string, 0), occurrences: make(map[string]int)}
+}
+
+func (im *DiaryImporter) Result() DiarySummary {
+	return DiarySummary{Entries: im.Summary, Foods: im.foods.Summary, Unmatched: im.unmatchedKeys}
+}
+
+// ImportEntry 는 식사 기록 하나를 저장하고 결과를 Summary 에 기록한다.
+// 반환하는 error 는 DB 에러처럼 다음 기록도 처리할 수 없는 경우에만 돌려준다.
+func (im *DiaryImporter) ImportEntry(record DiaryRecord) error {
+	im.Summary.Total++
+
+	if errs := record.Validate(); len(errs) > 0 {
+		im.Summary.fail(errs...)
+		return nil
+	}
+
+	// 파일 안에서 같은 자리의 기록은 같은 ID가 되므로 양을 고쳐서 다시 가져와도 새 기록이 생기지 않는다.
+	// 같은 음식을 한 끼에 두 번 적은 기록도 따로 가져오도록 순서를 붙인다.
+	key := sourceKey(record.EatenAt.Format("2006-01-02"), strconv.Itoa(int(record.Slot)), record.BrandName,
+		record.FoodName)
+	im.occurrences[key]++
+	sourceId := sourceKey(key, strconv.Itoa(im.occurrences[key]))
+
+	food, rowErr, err := im.food(record)
+	if err != nil {
+		return err
+	} else if rowErr != nil {
+		im.Summary.fail(*rowErr)
+		return nil
+	}
+
+	entry := models.MealEntry{UserId: im.userId, Slot: record.Slot, EatenAt: record.EatenAt,
+		Source: im.source, SourceId: sourceId}
+	if food != nil {
+		if ok, err := im.setQuantity(&entry, *food, record); err != nil {
+			return err
+		} else if !ok {
+			im.Summary.fail(RowError{Line: record.Line, Field: "Quantity", Message: "먹은 양을 food의 단위로 바꿀 수 없습니다."})
+			return nil
+		}
+	}
+
+	existing, err := models.MealEntry{}.GetBySource(im.userId, im.source, sourceId)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		im.Summary.Created++
+		if im.Summary.DryRun {
+			return nil
+		}
+
+		_, err = entry.Create()
+		return err
+	}
+
+	if food != nil && existing.FoodId == entry.FoodId && existing.Quantity == entry.Quantity &&
+		existing.EatenAt.Equal(entry.EatenAt) {
+		im.Summary.Skipped++
+		return nil
+	}
+
+	im.Summary.Updated++
+	if im.Summary.DryRun {
+		return nil
+	}
+
+	// 가져온 뒤에 user가 술자리로 옮겼으면 그대로 둔다.
+	entry.Id, entry.SessionId = existing.Id, existing.SessionId
+	return entry.Update()
+}
+
+// food 는 기록의 음식을 catalog에서 이름과 brand로 찾고, 없으면 createFoods 일 때만 새로 만든다.
+// 찾지도 만들지도 못하면 그 이유를 RowError 로 돌려준다.
+func (im *DiaryImporter) food(record DiaryRecord) (*models.Food, *RowError, error) {
+	key := record.BrandName + "\x00" + record.FoodName
+	if food, ok := im.cache[key]; ok {
+		return food, nil, nil
+	} else if im.unmatched[key] {
+		return nil, &RowError{Line: record.Line, Field: "Food", Message: "catalog에 없는 food입니다."}, nil
+	}
+
+	var brandId int64
+	if record.BrandName != "" {
+		var err error
+		if brandId, err = findBrand(record.BrandName); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var food *models.Food
+	if record.BrandName == "" || brandId != 0 {
+		var err error
+		if food, err = (models.Food{}).GetByNameAndBrand(record.FoodName, brandId); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if food == nil && !im.createFoods {
+		name := record.FoodName
+		if record.BrandName != "" {
+			name = record.BrandName + " - " + name
+		}
+		im.unmatched[key] = true
+		im.unmatchedKeys = append(im.unmatchedKeys, name)
+		return nil, &RowError{Line: record.Line, Field: "Food", Message: "catalog에 없는 food입니다."}, nil
+	}
+
+	if food == nil {
+		cannotCreate := &RowError{Line: record.Line, Field: "Food", Message: "food를 만들 수 없습니다."}
+		foodRecord, err := record.foodRecord()
+		if err != nil {
+			return nil, cannotCreate, nil
+		}
+
+		failed := im.foods.Summary.Failed
+		if food, err = im.foods.importRecord(foodRecord); err != nil {
+			return nil, nil, err
+		} else if im.foods.Summary.Failed > failed {
+			return nil, cannotCreate, nil
+		}
+	}
+
+	im.cache[key] = food
+
+	return food, nil, nil
+}
+
+// setQuantity 는 먹은 양을 food의 단위로 바꿔서 entry에 저장한다.
+// "1 slice" 처럼 food의 단위로 바꿀 수 없는 양은 칼로리가 같아지는 양으로 정한다.
+func (im *DiaryImporter) setQuantity(entry *models.MealEntry, food models.Food, record DiaryRecord) (bool, error) {
+	quantity, unit := record.amount()
+	switch err := entry.SetQuantity(food, quantity, unit, 0); err {
+	case nil:
+		return true, nil
+	case models.ErrNutrientNotFound:
+		return false, nil
+	case units.ErrIncompatible, units.ErrDensityRequired, units.ErrUnknownUnit:
+	default:
+		return false, err
+	}
+
+	nutrient, err := models.Nutrient{}.GetByFoodId(food.Id)
+	if err != nil {
+		return false, err
+	} else if nutrient == nil || nutrient.Calorie <= 0 || nutrient.PerUnit <= 0 || record.Calorie <= 0 {
+		return false, nil
+	}
+
+	// 나중에 food를 바꿔도 다시 계산할 수 있도록 입력한 양도 food의 단위로 남긴다.
+	entry.FoodId = food.Id
+	entry.Quantity = record.Calorie / float64(nutrient.Calorie) * float64(nutrient.PerUnit)
+	entry.LoggedQuantity, entry.LoggedUnit, entry.ServingSizeId = entry.Quantity, nutrient.Unit, 0
+
+	return true, nil
+}
+
+// Finish 는 새로 만든 food가 검색되도록 그 food만 검색 색인에 넣는다.
+func (im *DiaryImporter) Finish() error {
+	for _, foodId := range im.foods.changed {
+		if err := models.ReindexFood(foodId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DiaryColumns 는 식단 CSV 에서 각 값을 읽을 column 이름이다. 비어 있으면 그 값은 없는 것으로 본다.
+// 지방은 UnSaturatedFat 이 없으면 PolyunsaturatedFat 과 MonounsaturatedFat 의 합, 그것도 없으면
+// Fat 에서 SaturatedFat 과 TransFat 을 뺀 값을 불포화 지방으로 본다.
+type DiaryColumns struct {
+	Date               string
+	Time               string
+	Meal               string
+	Food               string
+	Brand              string
+	Quantity           string
+	Unit               string
+	Calorie            string
+	Carbohydrate       string
+	Protein            string
+	Fat                string
+	SaturatedFat       string
+	UnSaturatedFat     string
+	PolyunsaturatedFat string
+	MonounsaturatedFat string
+	TransFat           string
+	Sodium             string
+	Sugars             string
+	DietaryFiber       string
+	Cholesterol        string
+	// Food column 이 MyFitnessPal 처럼 "brand - 이름, 1 cup" 형식이면 brand와 먹은 양도 여기서 읽는다.
+	FoodWithServing bool
+}
+
+func (c *DiaryColumns) fields() map[string]*string {
+	return map[string]*string{"Date": &c.Date, "Time": &c.Time, "Meal": &c.Meal, "Food": &c.Food, "Brand": &c.Brand,
+		"Quantity": &c.Quantity, "Unit": &c.Unit, "Calorie": &c.Calorie, "Carbohydrate": &c.Carbohydrate,
+		"Protein": &c.Protein, "Fat": &c.Fat, "SaturatedFat": &c.SaturatedFat, "UnSaturatedFat": &c.UnSaturatedFat,
+		"PolyunsaturatedFat": &c.PolyunsaturatedFat, "MonounsaturatedFat": &c.MonounsaturatedFat,
+		"TransFat": &c.TransFat, "Sodium": &c.Sodium, "Sugars": &c.Sugars, "DietaryFiber": &c.DietaryFiber,
+		"Cholesterol": &c.Cholesterol}
+}
+
+// DefaultDiaryColumns 는 값의 이름과 같은 column을 읽는다.
+func DefaultDiaryColumns() DiaryColumns {
+	var columns DiaryColumns
+	for name, column := range columns.fields() {
+		*column = name
+	}
+
+	return columns
+}
+
+// ParseDiaryColumns 는 "Date=Day,Food=Item,Calorie=kcal" 처럼 적은 column 이름으로 DefaultDiaryColumns 를 바꾼다.
+// 값의 이름은 대소문자를 구분하지 않고, column 이름을 비우면 그 값은 읽지 않는다.
+func ParseDiaryColumns(spec string) (DiaryColumns, error) {
+	columns := DefaultDiaryColumns()
+	if strings.TrimSpace(spec) == "" {
+		return columns, nil
+	}
+
+	fields := columns.fields()
+	for _, pair := range strings.Split(spec, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return columns, fmt.Errorf("column mapping 은 이름=column 형식이어야 합니다: %q", pair)
+		}
+
+		found := false
+		for name, column := range fields {
+			if strings.EqualFold(name, strings.TrimSpace(parts[0])) {
+				*column, found = strings.TrimSpace(parts[1]), true
+			}
+		}
+		if !found {
+			return columns, fmt.Errorf("알 수 없는 값의 이름입니다: %q", parts[0])
+		}
+	}
+
+	return columns, nil
+}
+
+// diaryRow 는 csvRow 처럼 한 줄의 값을 읽는다. 숫자에 천 단위 구분 기호가 있을 수 있다.
+type diaryRow struct {
+	csvRow
+	columns DiaryColumns
+}
+
+func (r *diaryRow) value(column string) string {
+	if column == "" {
+		return ""
+	}
+
+	return r.get(column)
+}
+
+func (r *diaryRow) number(column string) float64 {
+	value := strings.Replace(r.value(column), ",", "", -1)
+	if value == "" {
+		return 0
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		r.fail(column, "숫자가 아닙니다.")
+	}
+
+	return parsed
+}
+
+func (r *diaryRow) optionalNumber(column string) *float32 {
+	if r.value(column) == "" {
+		return nil
+	}
+
+	value := float32(r.number(column))
+
+	return &value
+}
+
+// eatenAt 은 날짜와 시간 column으로 먹은 시간을 정한다. 시간을 모르면 끼니마다 정한 시간이다.
+func (r *diaryRow) eatenAt(slot int32) time.Time {
+	date := r.value(r.columns.Date)
+	if date == "" {
+		return time.Time{}
+	}
+
+	for _, layout := range diaryDateTimeLayouts {
+		if eatenAt, err := time.ParseInLocation(layout, date, time.Local); err == nil {
+			return eatenAt
+		}
+	}
+
+	for _, layout := range diaryDateLayouts {
+		day, err := time.ParseInLocation(layout, date, time.Local)
+		if err != nil {
+			continue
+		}
+
+		if clock := r.value(r.columns.Time); clock != "" {
+			for _, timeLayout := range diaryTimeLayouts {
+				if parsed, err := time.Parse(timeLayout, strings.ToUpper(clock)); err == nil {
+					return day.Add(time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute)
+				}
+			}
+			r.fail(r.columns.Time, "시간을 읽을 수 없습니다.")
+		}
+
+		return day.Add(time.Duration(diarySlotHours[slot]) * time.Hour)
+	}
+
+	r.fail(r.columns.Date, "날짜를 읽을 수 없습니다.")
+
+	return time.Time{}
+}
+
+func (r *diaryRow) record() DiaryRecord {
+	c := r.columns
+
+	slot := parseMealSlot(r.value(c.Meal))
+	record := DiaryRecord{Line: r.line, EatenAt: r.eatenAt(slot), Slot: slot,
+		FoodName: r.value(c.Food), BrandName: r.value(c.Brand),
+		Quantity: r.number(c.Quantity), Unit: parseDiaryUnit(r.value(c.Unit)),
+		Calorie: r.number(c.Calorie), Carbohydrate: float32(r.number(c.Carbohydrate)),
+		Protein: float32(r.number(c.Protein)), SaturatedFat: float32(r.number(c.SaturatedFat)),
+		TransFat: float32(r.number(c.TransFat)), Sodium: r.optionalNumber(c.Sodium), Sugars: r.optionalNumber(c.Sugars),
+		DietaryFiber: r.optionalNumber(c.DietaryFiber), Cholesterol: r.optionalNumber(c.Cholesterol)}
+
+	if c.FoodWithServing {
+		brand, name, quantity, unit := splitFoodName(record.FoodName)
+		record.FoodName = name
+		if record.BrandName == "" {
+			record.BrandName = brand
+		}
+		if record.Quantity == 0 {
+			record.Quantity, record.Unit = quantity, unit
+		}
+	}
+
+	if r.value(c.UnSaturatedFat) != "" {
+		record.UnSaturatedFat = float32(r.number(c.UnSaturatedFat))
+	} else if r.value(c.PolyunsaturatedFat) != "" || r.value(c.MonounsaturatedFat) != "" {
+		record.UnSaturatedFat = float32(r.number(c.PolyunsaturatedFat) + r.number(c.MonounsaturatedFat))
+	} else if fat := float32(r.number(c.Fat)); fat > record.SaturatedFat+record.TransFat {
+		record.UnSaturatedFat = fat - record.SaturatedFat - record.TransFat
+	}
+
+	return record
+}
+
+// parseMealSlot 은 끼니 이름이나 번호를 끼니로 바꾼다. 알 수 없는 끼니는 간식으로 본다.
+func parseMealSlot(meal string) int32 {
+	if slot, ok := diarySlotNames[strings.ToLower(meal)]; ok {
+		return slot
+	}
+
+	if slot, err := strconv.ParseInt(meal, 10, 32); err == nil && models.IsValidMealSlot(int32(slot)) {
+		return int32(slot)
+	}
+
+	return models.MealSlotSnack
+}
+
+// parseDiaryUnit 은 "cups", "serving(s)" 처럼 적힌 단위를 Unit 으로 바꾼다.
+// 비어 있으면 Unknown 이고, "slice" 처럼 알 수 없는 단위는 인분으로 본다.
+func parseDiaryUnit(symbol string) units.Unit {
+	symbol = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(symbol)), "(s)")
+	if symbol == "" {
+		return units.Unknown
+	}
+
+	// 복수형이면 s를 떼고 다시 찾는다.
+	for _, name := range []string{symbol, strings.TrimSuffix(symbol, "s")} {
+		if unit, ok := diaryUnitNames[name]; ok {
+			return unit
+		}
+		if unit, err := units.Parse(name); err == nil {
+			return unit
+		}
+	}
+
+	return units.Serving
+}
+
+// ImportDiaryCSV 는 header가 있는 식단 CSV 를 columns 에 따라 한 줄씩 읽어 im 으로 가져온다.
+// header 이름은 대소문자를 구분하지 않는다.
+func ImportDiaryCSV(im *DiaryImporter, reader io.Reader, columns DiaryColumns) error {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return err
+	}
+
+	indexes := make(map[string]int, len(header))
+	for idx, name := range header {
+		// 엑셀에서 저장한 CSV 는 BOM 으로 시작한다.
+		name = strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")
+		indexes[strings.ToLower(name)] = idx
+	}
+
+	for _, required := range []string{columns.Date, columns.Food} {
+		if _, ok := indexes[strings.ToLower(required)]; !ok || required == "" {
+			return ErrDiaryColumns
+		}
+	}
+
+	for line := 2; ; line++ {
+		values, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			if _, ok := err.(*csv.ParseError); ok {
+				im.Summary.Fail(RowError{Line: line, Message: err.Error()})
+				continue
+			}
+			return err
+		}
+
+		row := diaryRow{csvRow: csvRow{line: line, columns: indexes, values: values}, columns: columns}
+		record := row.record()
+		if len(row.errs) > 0 {
+			im.Summary.Fail(row.errs...)
+			continue
+		}
+
+		if err := im.ImportEntry(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ImportDiaryFile(im *DiaryImporter, path string, columns DiaryColumns) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return ImportDiaryCSV(im, file, columns)
+}
@@ -0,0 +1,280 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/units"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SourceFDC 는 USDA FoodData Central 에서 가져온 food의 Source 이다.
+const SourceFDC = "fdc"
+
+var ErrFDCFormat = errors.New("FoodData Central JSON 형식이 아닙니다")
+
+type fdcFood struct {
+	FdcId        int64  `json:"fdcId"`
+	Description  string `json:"description"`
+	DataType     string `json:"dataType"`
+	FoodCategory *struct {
+		Description string `json:"description"`
+	} `json:"foodCategory"`
+	BrandedFoodCategory string  `json:"brandedFoodCategory"`
+	BrandOwner          string  `json:"brandOwner"`
+	ServingSize         float64 `json:"servingSize"`
+	ServingSizeUnit     string  `json:"servingSizeUnit"`
+	HouseholdServing    string  `json:"householdServingFullText"`
+	FoodNutrients       []struct {
+		Nutrient struct {
+			Id       int64  `json:"id"`
+			UnitName string `json:"unitName"`
+		} `json:"nutrient"`
+		Amount *float64 `json:"amount"`
+	} `json:"foodNutrients"`
+	FoodPortions []struct {
+		GramWeight         float64 `json:"gramWeight"`
+		Amount             float64 `json:"amount"`
+		Modifier           string  `json:"modifier"`
+		PortionDescription string  `json:"portionDescription"`
+		MeasureUnit        struct {
+			Name string `json:"name"`
+		} `json:"measureUnit"`
+	} `json:"foodPortions"`
+}
+
+// FDC nutrient id. 값이 없을 때 대신 쓸 id를 뒤에 둔다.
+var fdcNutrientIds = map[string][]int64{
+	"calorie":      {1008, 2047, 2048},
+	"energyKJ":     {1062},
+	"protein":      {1003},
+	"carbohydrate": {1005, 1050},
+	"fat":          {1004, 1085},
+	"saturatedFat": {1258},
+	"monoFat":      {1292},
+	"polyFat":      {1293},
+	"transFat":     {1257},
+	"sodium":       {1093},
+	"sugars":       {2000, 1063},
+	"dietaryFiber": {1079},
+	"cholesterol":  {1253},
+	"alcohol":      {1018},
+	"vitamin_a":    {1106},
+	"vitamin_b1":   {1165},
+	"vitamin_b2":   {1166},
+	"vitamin_b6":   {1175},
+	"vitamin_b12":  {1178},
+	"vitamin_c":    {1162},
+	"vitamin_d":    {1114},
+	"vitamin_e":    {1109},
+	"vitamin_k":    {1185},
+	"niacin":       {1167},
+	"folate":       {1177, 1190},
+	"calcium":      {1087},
+	"iron":         {1089},
+	"magnesium":    {1090},
+	"phosphorus":   {1091},
+	"potassium":    {1092},
+	"zinc":         {1095},
+}
+
+type fdcAmount struct {
+	value float64
+	unit  string
+}
+
+// amounts 는 nutrient id 별 100g(ml) 당 함량이다.
+func (f fdcFood) amounts() map[int64]fdcAmount {
+	amounts := make(map[int64]fdcAmount, len(f.FoodNutrients))
+	for _, foodNutrient := range f.FoodNutrients {
+		if foodNutrient.Amount != nil {
+			amounts[foodNutrient.Nutrient.Id] = fdcAmount{value: *foodNutrient.Amount,
+				unit: strings.ToLower(foodNutrient.Nutrient.UnitName)}
+		}
+	}
+
+	return amounts
+}
+
+// fdcValue 는 field의 함량을 저장 단위로 바꿔서 돌려준다. 값이 없으면 false 이다.
+func fdcValue(amounts map[int64]fdcAmount, field string) (float64, bool) {
+	for _, id := range fdcNutrientIds[field] {
+		amount, ok := amounts[id]
+		if !ok {
+			continue
+		}
+
+		from, fromOk := massUnitFactors[amount.unit]
+		to, toOk := massUnitFactors[fieldUnit(field)]
+		if fromOk && toOk {
+			return amount.value * from / to, true
+		}
+
+		return amount.value, true
+	}
+
+	return 0, false
+}
+
+func fdcOptional(amounts map[int64]fdcAmount, field string) *float32 {
+	value, ok := fdcValue(amounts, field)
+	if !ok {
+		return nil
+	}
+
+	result := float32(value)
+
+	return &result
+}
+
+// truncate 는 food 이름 길이 제한에 맞게 긴 FDC 설명을 자른다.
+func truncate(value string, length int) string {
+	runes := []rune(strings.TrimSpace(value))
+	if len(runes) <= length {
+		return string(runes)
+	}
+
+	return strings.TrimSpace(string(runes[:length]))
+}
+
+func (f fdcFood) record(line int) Record {
+	amounts := f.amounts()
+
+	record := Record{Line: line, Source: SourceFDC, SourceId: strconv.FormatInt(f.FdcId, 10),
+		Name: truncate(f.Description, 64), BrandName: truncate(f.BrandOwner, 64), CategoryName: f.BrandedFoodCategory,
+		PerUnit: 100, Unit: units.Gram, Micronutrients: make(map[string]float32)}
+
+	if f.FoodCategory != nil && f.FoodCategory.Description != "" {
+		record.CategoryName = f.FoodCategory.Description
+	}
+	if record.CategoryName == "" {
+		record.CategoryName = "기타"
+	}
+
+	// Branded 식품은 음료처럼 ml 기준으로 표시된 것도 있다.
+	if unit, err := units.Parse(f.ServingSizeUnit); err == nil && unit.Kind() == units.KindVolume {
+		record.Unit = units.Milliliter
+	}
+
+	calorie, ok := fdcValue(amounts, "calorie")
+	if !ok {
+		if energy, ok := fdcValue(amounts, "energyKJ"); ok {
+			calorie = energy / 4.184
+		}
+	}
+	record.Calorie = int64(math.Round(calorie))
+
+	value := func(field string) float32 {
+		v, _ := fdcValue(amounts, field)
+		return float32(v)
+	}
+	record.Carbohydrate, record.Protein = value("carbohydrate"), value("protein")
+	record.SaturatedFat, record.TransFat = value("saturatedFat"), value("transFat")
+
+	mono, hasMono := fdcValue(amounts, "monoFat")
+	poly, hasPoly := fdcValue(amounts, "polyFat")
+	if hasMono || hasPoly {
+		record.UnSaturatedFat = float32(mono + poly)
+	} else if fat, ok := fdcValue(amounts, "fat"); ok {
+		record.UnSaturatedFat = float32(math.Max(0, fat-float64(record.SaturatedFat)-float64(record.TransFat)))
+	}
+
+	record.Sodium, record.Sugars = fdcOptional(amounts, "sodium"), fdcOptional(amounts, "sugars")
+	record.DietaryFiber, record.Cholesterol = fdcOptional(amounts, "dietaryFiber"), fdcOptional(amounts, "cholesterol")
+	record.Alcohol = fdcOptional(amounts, "alcohol")
+
+	for _, code := range models.MicronutrientCodes() {
+		if amount, ok := fdcValue(amounts, code); ok {
+			record.Micronutrients[code] = float32(amount)
+		}
+	}
+
+	record.ServingSizes = f.servingSizes(record.Unit)
+
+	return record
+}
+
+func (f fdcFood) servingSizes(unit units.Unit) []ServingSize {
+	servingSizes := make([]ServingSize, 0)
+	for _, portion := range f.FoodPortions {
+		if portion.GramWeight <= 0 {
+			continue
+		}
+
+		label := strings.TrimSpace(portion.PortionDescription)
+		if label == "" || label == "Quantity not specified" {
+			label = strings.TrimSpace(strconv.FormatFloat(portion.Amount, 'f', -1, 64) + " " +
+				strings.TrimSpace(portion.MeasureUnit.Name+" "+portion.Modifier))
+		}
+		servingSizes = append(servingSizes, ServingSize{Label: truncate(label, 32), Grams: portion.GramWeight})
+	}
+
+	// Branded 식품은 1회 제공량 하나만 있다. ml 로 표시된 식품은 무게를 알 수 없어서 넣지 않는다.
+	if f.ServingSize > 0 && unit == units.Gram && strings.ToLower(f.ServingSizeUnit) == "g" {
+		label := f.HouseholdServing
+		if label == "" {
+			label = strconv.FormatFloat(f.ServingSize, 'f', -1, 64) + " g"
+		}
+		servingSizes = append(servingSizes, ServingSize{Label: truncate(label, 32), Grams: f.ServingSize})
+	}
+
+	return servingSizes
+}
+
+// ImportFDC 는 FoodData Central 의 bulk JSON ({"FoundationFoods": [...]}, {"SRLegacyFoods": [...]},
+// {"BrandedFoods": [...]})을 food 하나씩 읽어서 im 으로 가져온다. 파일이 커서 한 번에 읽지 않는다.
+func ImportFDC(im *Importer, reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+
+	if token, err := decoder.Token(); err != nil {
+		return err
+	} else if token != json.Delim('{') {
+		return ErrFDCFormat
+	}
+
+	line := 0
+	for decoder.More() {
+		// 최상위 key 다음에는 food 배열이 온다.
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+		if token, err := decoder.Token(); err != nil {
+			return err
+		} else if token != json.Delim('[') {
+			return ErrFDCFormat
+		}
+
+		for decoder.More() {
+			line++
+
+			var food fdcFood
+			if err := decoder.Decode(&food); err != nil {
+				return err
+			}
+
+			if err := im.Import(food.record(line)); err != nil {
+				return err
+			}
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ImportFDCFile(im *Importer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return ImportFDC(im, file)
+}
@@ -0,0 +1,229 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrFitbitFormat = errors.New("Fitbit 계정 archive 의 JSON 형식이 아닙니다")
+
+// Fitbit 은 설정에 따라 날짜를 이 형식 중 하나로 내보낸다.
+var fitbitDateLayouts = []string{"2006-01-02", "01-02-2006", "02-01-2006", "01/02/2006"}
+
+type fitbitRow struct {
+	line    int
+	section string
+	columns map[string]int
+	// "Weight (lbs)" 처럼 column 이름에 붙은 단위
+	units  map[string]string
+	values []string
+}
+
+func (r fitbitRow) get(column string) string {
+	if idx, ok := r.columns[column]; ok && idx < len(r.values) {
+		return strings.TrimSpace(r.values[idx])
+	}
+
+	return ""
+}
+
+// number 는 "1,234" 처럼 천 단위 구분 기호가 들어간 숫자도 읽는다. 값이 없으면 0이다.
+func (r fitbitRow) number(column string) (float64, bool) {
+	value := strings.Replace(r.get(column), ",", "", -1)
+	if value == "" {
+		return 0, true
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	return parsed, err == nil
+}
+
+func (r fitbitRow) date() (time.Time, bool) {
+	value := r.get("Date")
+	for _, layout := range fitbitDateLayouts {
+		if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// importBody 는 Body 구역의 하루 측정을 가져온다.
+// 몸무게는 column 이름에 단위가 없으면 HealthImporter.WeightUnit 으로 본다.
+func (r fitbitRow) importBody(im *HealthImporter) error {
+	date, ok := r.date()
+	weight, weightOk := r.number("Weight")
+	fat, fatOk := r.number("Fat")
+	if !ok || !weightOk || !fatOk {
+		im.Summary.Fail(RowError{Line: r.line, Message: "날짜나 값을 읽을 수 없습니다."})
+		return nil
+	}
+
+	if weight, ok = im.kilograms(weight, r.units["Weight"]); !ok {
+		im.Summary.Fail(RowError{Line: r.line, Field: "Weight", Message: "알 수 없는 몸무게 단위입니다."})
+		return nil
+	}
+
+	return im.ImportBody(BodyRecord{Line: r.line, Source: SourceFitbit, SourceId: sourceKey("body", r.get("Date")),
+		MeasuredAt: date, Weight: weight, BodyFat: fat})
+}
+
+// splitColumnUnit 은 "Weight (lbs)" 같은 column 이름을 이름과 단위로 나눈다.
+func splitColumnUnit(column string) (string, string) {
+	column = strings.TrimSpace(column)
+	if open := strings.LastIndex(column, " ("); open > 0 && strings.HasSuffix(column, ")") {
+		return column[:open], column[open+2 : len(column)-1]
+	}
+
+	return column, ""
+}
+
+// ImportFitbit 은 Fitbit 웹에서 내보낸 CSV 의 Body 구역을 im 으로 가져온다.
+// 이 CSV 는 구역 이름 한 줄, column 이름 한 줄, 값 여러 줄이 구역마다 반복되고, 다른 구역은 건너뛴다.
+// Activities 구역은 하루 동안 활발했던 시간의 합계뿐이라 실제 운동 기록과 겹치는지 알 수 없으므로
+// 칼로리가 두 번 계산되지 않도록 가져오지 않는다. 운동 하나하나는 계정 archive 의 JSON 으로 가져온다(ImportFitbitJSON).
+func ImportFitbit(im *HealthImporter, reader io.Reader) error {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var row fitbitRow
+	for line := 1; ; line++ {
+		values, err := r.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			if _, ok := err.(*csv.ParseError); ok {
+				im.Summary.Fail(RowError{Line: line, Message: err.Error()})
+				continue
+			}
+			return err
+		}
+
+		// 값이 하나뿐인 줄은 구역 이름이고, 그 다음 줄이 column 이름이다.
+		if len(values) == 1 {
+			row = fitbitRow{section: strings.TrimSpace(values[0])}
+			continue
+		}
+		if row.columns == nil {
+			row.columns, row.units = make(map[string]int, len(values)), make(map[string]string)
+			for idx, name := range values {
+				name, unit := splitColumnUnit(name)
+				row.columns[name] = idx
+				if unit != "" {
+					row.units[name] = unit
+				}
+			}
+			continue
+		}
+
+		row.line, row.values = line, values
+		if row.section == "Body" {
+			if err := row.importBody(im); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Fitbit 계정 archive 의 시간 형식
+const (
+	fitbitArchiveTimeLayout = "01/02/06 15:04:05"
+	fitbitArchiveDateLayout = "01/02/06"
+)
+
+var fitbitActivityKinds = map[string]string{
+	"walk": "walking", "run": "running", "treadmill": "running",
+	"bike": "cycling", "outdoor bike": "cycling", "spinning": "cycling",
+	"swim": "swimming", "weights": "strength", "yoga": "yoga", "hike": "hiking",
+}
+
+// fitbitArchiveItem 은 계정 archive 의 exercise-*.json(운동 하나)과 weight-*.json(측정 하나)의 항목이다.
+type fitbitArchiveItem struct {
+	LogId int64 `json:"logId"`
+
+	ActivityName string  `json:"activityName"`
+	Calories     float64 `json:"calories"`
+	Duration     int64   `json:"duration"` // ms
+	StartTime    string  `json:"startTime"`
+
+	Weight *float64 `json:"weight"`
+	Fat    float64  `json:"fat"`
+	Date   string   `json:"date"`
+	Time   string   `json:"time"`
+}
+
+// ImportFitbitJSON 은 Fitbit 계정 archive 의 Physical Activity 폴더에 있는 exercise-*.json 이나
+// weight-*.json 파일 하나를 im 으로 가져온다. 운동은 기기가 잰 운동마다의 소비 칼로리를 쓴다.
+// archive 의 몸무게에는 단위가 없으므로 HealthImporter.WeightUnit 으로 본다.
+func ImportFitbitJSON(im *HealthImporter, reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+
+	if token, err := decoder.Token(); err != nil {
+		return err
+	} else if token != json.Delim('[') {
+		return ErrFitbitFormat
+	}
+
+	for line := 1; decoder.More(); line++ {
+		var item fitbitArchiveItem
+		if err := decoder.Decode(&item); err != nil {
+			return err
+		}
+
+		var err error
+		if item.Weight != nil {
+			err = importFitbitWeight(im, item, line)
+		} else {
+			err = importFitbitExercise(im, item, line)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func importFitbitExercise(im *HealthImporter, item fitbitArchiveItem, line int) error {
+	startedAt, err := time.ParseInLocation(fitbitArchiveTimeLayout, item.StartTime, time.Local)
+	if err != nil {
+		im.Summary.Fail(RowError{Line: line, Field: "startTime", Message: "운동한 시간을 읽을 수 없습니다."})
+		return nil
+	}
+
+	kind, ok := fitbitActivityKinds[strings.ToLower(item.ActivityName)]
+	if !ok {
+		kind = workoutOther
+	}
+
+	return im.ImportWorkout(WorkoutRecord{Line: line, Source: SourceFitbit,
+		SourceId: strconv.FormatInt(item.LogId, 10), Kind: kind, StartedAt: startedAt,
+		Minutes: float64(item.Duration) / float64(time.Minute/time.Millisecond), Calorie: item.Calories})
+}
+
+func importFitbitWeight(im *HealthImporter, item fitbitArchiveItem, line int) error {
+	measuredAt, err := time.ParseInLocation(fitbitArchiveTimeLayout, item.Date+" "+item.Time, time.Local)
+	if err != nil {
+		if measuredAt, err = time.ParseInLocation(fitbitArchiveDateLayout, item.Date, time.Local); err != nil {
+			im.Summary.Fail(RowError{Line: line, Field: "date", Message: "측정한 날짜를 읽을 수 없습니다."})
+			return nil
+		}
+	}
+
+	weight, ok := im.kilograms(*item.Weight, "")
+	if !ok {
+		im.Summary.Fail(RowError{Line: line, Field: "weight", Message: "알 수 없는 몸무게 단위입니다."})
+		return nil
+	}
+
+	return im.ImportBody(BodyRecord{Line: line, Source: SourceFitbit,
+		SourceId: sourceKey("weight", strconv.FormatInt(item.LogId, 10)), MeasuredAt: measuredAt,
+		Weight: weight, BodyFat: item.Fat})
+}
@@ -0,0 +1,147 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Google 피트니스 data type
+const (
+	googleFitWeight   = "com.google.weight"
+	googleFitBodyFat  = "com.google.body.fat.percentage"
+	googleFitActivity = "com.google.activity.segment"
+)
+
+// 이보다 짧은 활동 구간은 하루 중 잠깐 움직인 것이므로 운동으로 가져오지 않는다.
+const googleFitMinSegmentMinutes = 10
+
+var ErrGoogleFitFormat = errors.New("Google 테이크아웃 피트니스 JSON 형식이 아닙니다")
+
+// Google 피트니스 활동 코드. 가만히 있기, 차량 이동, 수면처럼 운동이 아닌 활동은 가져오지 않는다.
+var googleFitActivityKinds = map[int64]string{
+	7: "walking", 93: "walking", 94: "walking", 95: "walking",
+	8: "running", 56: "running", 57: "running", 58: "running",
+	1: "cycling", 14: "cycling", 15: "cycling", 16: "cycling",
+	82: "swimming", 83: "swimming", 84: "swimming",
+	80: "strength", 100: "yoga", 35: "hiking",
+}
+
+var googleFitIgnoredActivities = map[int64]bool{
+	0: true, 3: true, 4: true, 5: true, 72: true, 109: true, 110: true, 111: true, 112: true,
+}
+
+type googleFitPoint struct {
+	DataTypeName       string `json:"dataTypeName"`
+	StartTimeNanos     int64  `json:"startTimeNanos"`
+	EndTimeNanos       int64  `json:"endTimeNanos"`
+	OriginDataSourceId string `json:"originDataSourceId"`
+	FitValue           []struct {
+		Value struct {
+			FpVal  *float64 `json:"fpVal"`
+			IntVal *int64   `json:"intVal"`
+		} `json:"value"`
+	} `json:"fitValue"`
+}
+
+func (p googleFitPoint) sourceId() string {
+	return sourceKey(p.OriginDataSourceId, p.DataTypeName, strconv.FormatInt(p.StartTimeNanos, 10))
+}
+
+// ImportGoogleFit 은 Google 테이크아웃의 피트니스 "All Data" JSON 파일({"Data Points": [...]}) 하나를
+// 읽어서 몸무게, 체지방률과 운동 구간을 im 으로 가져온다. 파일이 커서 한 번에 읽지 않는다.
+func ImportGoogleFit(im *HealthImporter, reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+
+	if token, err := decoder.Token(); err != nil {
+		return err
+	} else if token != json.Delim('{') {
+		return ErrGoogleFitFormat
+	}
+
+	line := 0
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		if key != "Data Points" {
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if token, err := decoder.Token(); err != nil {
+			return err
+		} else if token != json.Delim('[') {
+			return ErrGoogleFitFormat
+		}
+
+		for decoder.More() {
+			var point googleFitPoint
+			if err := decoder.Decode(&point); err != nil {
+				return err
+			}
+
+			line++
+			if err := importGoogleFitPoint(im, point, line); err != nil {
+				return err
+			}
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func importGoogleFitPoint(im *HealthImporter, point googleFitPoint, line int) error {
+	if len(point.FitValue) == 0 {
+		return nil
+	}
+	value := point.FitValue[0].Value
+	startedAt := time.Unix(0, point.StartTimeNanos)
+
+	switch point.DataTypeName {
+	case googleFitWeight, googleFitBodyFat:
+		if value.FpVal == nil {
+			im.Summary.Fail(RowError{Line: line, Field: point.DataTypeName, Message: "값이 없습니다."})
+			return nil
+		}
+
+		record := BodyRecord{Line: line, Source: SourceGoogleFit, SourceId: point.sourceId(), MeasuredAt: startedAt}
+		if point.DataTypeName == googleFitWeight {
+			record.Weight = *value.FpVal
+		} else {
+			record.BodyFat = *value.FpVal
+		}
+
+		return im.ImportBody(record)
+	case googleFitActivity:
+		if value.IntVal == nil || googleFitIgnoredActivities[*value.IntVal] {
+			return nil
+		}
+
+		minutes := time.Duration(point.EndTimeNanos - point.StartTimeNanos).Minutes()
+		if minutes < googleFitMinSegmentMinutes {
+			return nil
+		}
+
+		kind, ok := googleFitActivityKinds[*value.IntVal]
+		if !ok {
+			kind = workoutOther
+		}
+
+		return im.ImportWorkout(WorkoutRecord{Line: line, Source: SourceGoogleFit, SourceId: point.sourceId(),
+			Kind: kind, StartedAt: startedAt, Minutes: minutes})
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,399 @@
+package importer
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"github.com/kernelgarden/diet/calculator"
+	"github.com/kernelgarden/diet/models"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 건강 기록을 가져온 기기/앱
+const (
+	SourceAppleHealth = "apple-health"
+	SourceGoogleFit   = "google-fit"
+	SourceFitbit      = "fitbit"
+)
+
+const (
+	// 이 시간 안에 잰 체성분은 다른 기기에서 가져왔어도 같은 측정으로 본다.
+	bodyDedupeWindow = 10 * time.Minute
+	// 다른 운동 기록과 겹친 시간이 둘 중 짧은 운동 시간의 이 비율 이상이면 같은 운동으로 본다.
+	workoutOverlapRatio = 0.5
+	// 하루보다 오래 한 운동은 기록할 수 없다.
+	maxWorkoutMinutes = 24 * 60
+)
+
+var ErrHealthFormat = errors.New("지원하지 않는 건강 기록 파일입니다 (.xml, .json, .csv)")
+
+// 몸무게를 kg 으로 바꿀 때 곱하는 값
+var weightUnitFactors = map[string]float64{"kg": 1, "lb": 0.45359237, "lbs": 0.45359237}
+
+// IsValidWeightUnit 은 HealthImporter.WeightUnit 에 쓸 수 있는 단위인지 확인한다.
+func IsValidWeightUnit(unit string) bool {
+	_, ok := weightUnitFactors[strings.ToLower(unit)]
+	return ok
+}
+
+type workoutKind struct {
+	Name string
+	Met  float64 // 보통 강도로 했을 때의 MET
+}
+
+// 종류를 알 수 없는 운동
+const workoutOther = "other"
+
+// 가져온 운동을 넣을 운동 종류. catalog에 같은 이름이 없으면 catalog에 넣지 않고 운동 기록에 이름과 이 MET로 계산한 값만 남긴다.
+// MET는 Compendium of Physical Activities 의 대표값이다.
+var workoutKinds = map[string]workoutKind{
+	"walking":    {"걷기", 3.5},
+	"running":    {"달리기", 9.8},
+	"cycling":    {"자전거 타기", 7.5},
+	"swimming":   {"수영", 7.0},
+	"strength":   {"근력 운동", 5.0},
+	"yoga":       {"요가", 2.5},
+	"hiking":     {"등산", 6.0},
+	workoutOther: {"기타 운동", 4.0},
+}
+
+// BodyRecord 는 가져온 체성분 측정 하나이다. 측정하지 않은 항목은 0이다.
+type BodyRecord struct {
+	Line       int
+	Source     string
+	SourceId   string
+	MeasuredAt time.Time
+	Weight     float64 // kg
+	BodyFat    float64 // %
+	Waist      float64 // cm
+	MuscleMass float64 // kg
+}
+
+// WorkoutRecord 는 가져온 운동 하나이다.
+type WorkoutRecord struct {
+	Line      int
+	Source    string
+	SourceId  string
+	Kind      string // workoutKinds 의 key
+	Intensity int32  // 모르면 0이고 보통 강도로 본다.
+	StartedAt time.Time
+	Minutes   float64
+	// 기기가 잰 소비 칼로리. 모르면 0이고 몸무게와 MET로 계산한다.
+	Calorie float64
+}
+
+func (r BodyRecord) Validate() []RowError {
+	errs := make([]RowError, 0)
+	fail := func(field, message string) {
+		errs = append(errs, RowError{Line: r.Line, Field: field, Message: message})
+	}
+
+	if r.MeasuredAt.IsZero() {
+		fail("MeasuredAt", "측정 시간이 없습니다.")
+	}
+	if r.Weight == 0 && r.BodyFat == 0 && r.Waist == 0 && r.MuscleMass == 0 {
+		fail("Weight", "측정한 값이 없습니다.")
+	}
+	if r.Weight < 0 || r.Waist < 0 || r.MuscleMass < 0 {
+		fail("Weight", "음수일 수 없습니다.")
+	}
+	if r.BodyFat < 0 || r.BodyFat >= 100 {
+		fail("BodyFat", "0에서 100 사이여야 합니다.")
+	}
+
+	return errs
+}
+
+func (r WorkoutRecord) Validate() []RowError {
+	errs := make([]RowError, 0)
+	fail := func(field, message string) {
+		errs = append(errs, RowError{Line: r.Line, Field: field, Message: message})
+	}
+
+	if _, ok := workoutKinds[r.Kind]; !ok {
+		fail("Kind", "알 수 없는 운동 종류입니다.")
+	}
+	if r.Intensity != 0 && !calculator.IsValidIntensity(r.Intensity) {
+		fail("Intensity", "알 수 없는 운동 강도입니다.")
+	}
+	if r.StartedAt.IsZero() {
+		fail("StartedAt", "운동한 시간이 없습니다.")
+	}
+	if r.Minutes <= 0 || r.Minutes > maxWorkoutMinutes {
+		fail("Minutes", "0분보다 길고 하루보다 짧아야 합니다.")
+	}
+	if r.Calorie < 0 {
+		fail("Calorie", "음수일 수 없습니다.")
+	}
+
+	return errs
+}
+
+func (r WorkoutRecord) endedAt() time.Time {
+	return r.StartedAt.Add(time.Duration(r.Minutes * float64(time.Minute)))
+}
+
+// sourceKey 는 ID가 없는 기록에 쓸 ID를 기록의 내용으로 만든다.
+func sourceKey(parts ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// HealthImporter 는 한 user의 체성분과 운동 기록을 가져온다.
+// 같은 출처에서 이미 가져온 기록은 건너뛰고, 다른 출처에서 가져왔거나 직접 기록한 것과 겹치는 기록도 같은 기록으로 본다.
+// 체성분은 비슷한 시간에 잰 기록이 있으면 그 기록에 없는 항목만 채우고, 운동은 시간이 많이 겹치면 건너뛴다.
+// DryRun 이면 아무것도 저장하지 않으므로 파일 안에서 서로 겹치는 기록은 알 수 없다.
+type HealthImporter struct {
+	Summary Summary
+	// Fitbit 처럼 파일에 단위가 없는 몸무게의 단위(kg, lb). 비어 있으면 kg 으로 본다.
+	WeightUnit string
+
+	userId     int64
+	activities map[string]*models.Activity
+}
+
+func NewHealth(userId int64, dryRun bool) *HealthImporter {
+	return &HealthImporter{Summary: Summary{DryRun: dryRun, Errors: make([]RowError, 0)}, userId: userId,
+		activities: make(map[string]*models.Activity)}
+}
+
+// ImportBody 는 체성분 기록 하나를 저장하고 결과를 Summary 에 기록한다.
+// 반환하는 error 는 DB 에러처럼 다음 기록도 처리할 수 없는 경우에만 돌려준다.
+func (im *HealthImporter) ImportBody(record BodyRecord) error {
+	im.Summary.Total++
+
+	if errs := record.Validate(); len(errs) > 0 {
+		im.Summary.fail(errs...)
+		return nil
+	}
+
+	existing, err := models.BodyMeasurement{}.GetBySource(im.userId, record.Source, record.SourceId)
+	if err != nil {
+		return err
+	} else if existing != nil {
+		im.Summary.Skipped++
+		return nil
+	}
+
+	nearest, err := models.BodyMeasurement{}.GetNearest(im.userId, record.MeasuredAt, bodyDedupeWindow)
+	if err != nil {
+		return err
+	}
+
+	if nearest == nil {
+		im.Summary.Created++
+		if im.Summary.DryRun {
+			return nil
+		}
+
+		measurement := models.BodyMeasurement{UserId: im.userId, Weight: record.Weight, BodyFat: record.BodyFat,
+			Waist: record.Waist, MuscleMass: record.MuscleMass, MeasuredAt: record.MeasuredAt,
+			Source: record.Source, SourceId: record.SourceId}
+		_, err := measurement.Create()
+		return err
+	}
+
+	// 이미 잰 항목은 그대로 두고, 없는 항목만 채운다.
+	merged := false
+	for _, field := range []struct {
+		target *float64
+		value  float64
+	}{
+		{&nearest.Weight, record.Weight}, {&nearest.BodyFat, record.BodyFat},
+		{&nearest.Waist, record.Waist}, {&nearest.MuscleMass, record.MuscleMass},
+	} {
+		if *field.target == 0 && field.value != 0 {
+			*field.target = field.value
+			merged = true
+		}
+	}
+
+	if !merged {
+		im.Summary.Skipped++
+		return nil
+	}
+
+	im.Summary.Updated++
+	if im.Summary.DryRun {
+		return nil
+	}
+
+	return nearest.Update()
+}
+
+// kilograms 는 unit 단위의 몸무게를 kg 으로 바꾼다. unit 이 비어 있으면 WeightUnit 으로 본다.
+func (im *HealthImporter) kilograms(weight float64, unit string) (float64, bool) {
+	if unit == "" {
+		unit = im.WeightUnit
+	}
+	if unit == "" {
+		return weight, true
+	}
+
+	factor, ok := weightUnitFactors[strings.ToLower(unit)]
+	return weight * factor, ok
+}
+
+// ImportWorkout 은 운동 기록 하나를 저장하고 결과를 Summary 에 기록한다.
+// 반환하는 error 는 DB 에러처럼 다음 기록도 처리할 수 없는 경우에만 돌려준다.
+func (im *HealthImporter) ImportWorkout(record WorkoutRecord) error {
+	im.Summary.Total++
+
+	if errs := record.Validate(); len(errs) > 0 {
+		im.Summary.fail(errs...)
+		return nil
+	}
+
+	// 지난 운동이므로 지금 몸무게가 아니라 운동한 때와 가장 가까운 때에 잰 몸무게로 계산한다.
+	weight, err := models.BodyWeightAt(im.userId, record.StartedAt)
+	if err != nil {
+		return err
+	}
+
+	if record.Calorie == 0 && weight <= 0 {
+		im.Summary.fail(RowError{Line: record.Line, Field: "Calorie",
+			Message: "몸무게를 몰라서 소비 칼로리를 계산할 수 없습니다. 체성분이나 profile을 먼저 기록해야 합니다."})
+		return nil
+	}
+
+	existing, err := models.ActivityEntry{}.GetBySource(im.userId, record.Source, record.SourceId)
+	if err != nil {
+		return err
+	} else if existing != nil {
+		im.Summary.Skipped++
+		return nil
+	}
+
+	overlapping, err := models.ActivityEntry{}.GetOverlapping(im.userId, record.StartedAt, record.endedAt())
+	if err != nil {
+		return err
+	}
+	for _, entry := range overlapping {
+		if isSameWorkout(*entry, record) {
+			im.Summary.Skipped++
+			return nil
+		}
+	}
+
+	im.Summary.Created++
+	if im.Summary.DryRun {
+		return nil
+	}
+
+	activity, err := im.activity(record.Kind)
+	if err != nil {
+		return err
+	}
+
+	intensity := record.Intensity
+	if intensity == 0 {
+		intensity = calculator.IntensityModerate
+	}
+
+	entry := models.ActivityEntry{UserId: im.userId, Minutes: record.Minutes, Intensity: intensity,
+		PerformedAt: record.StartedAt, Source: record.Source, SourceId: record.SourceId}
+	entry.SetCalorie(*activity, weight)
+	if record.Calorie > 0 {
+		// 기기가 잰 값이 MET로 계산한 값보다 정확하다.
+		entry.Calorie = record.Calorie
+	}
+
+	_, err = entry.Create()
+	return err
+}
+
+// isSameWorkout 은 entry와 record가 겹친 시간이 둘 중 짧은 운동 시간의 workoutOverlapRatio 이상인지 확인한다.
+func isSameWorkout(entry models.ActivityEntry, record WorkoutRecord) bool {
+	start := entry.PerformedAt
+	if record.StartedAt.After(start) {
+		start = record.StartedAt
+	}
+	end := entry.EndedAt()
+	if record.endedAt().Before(end) {
+		end = record.endedAt()
+	}
+
+	overlap := end.Sub(start).Minutes()
+	shorter := math.Min(entry.Minutes, record.Minutes)
+
+	return overlap > 0 && overlap >= shorter*workoutOverlapRatio
+}
+
+// activity 는 운동 종류를 catalog에서 이름으로 찾는다.
+// catalog는 권한이 있는 user만 바꿀 수 있으므로 없으면 만들지 않고 저장하지 않은(Id 가 0인) 운동 종류를 돌려준다.
+func (im *HealthImporter) activity(kind string) (*models.Activity, error) {
+	if activity, ok := im.activities[kind]; ok {
+		return activity, nil
+	}
+
+	definition := workoutKinds[kind]
+	activity, err := models.Activity{}.GetByName(definition.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if activity == nil {
+		activity = &models.Activity{Name: definition.Name, Met: definition.Met}
+	}
+
+	im.activities[kind] = activity
+
+	return activity, nil
+}
+
+// ImportHealth 는 파일 이름의 확장자에 따라 Apple Health export.xml, Google 테이크아웃 피트니스 JSON,
+// Fitbit 계정 archive 의 운동/몸무게 JSON, Fitbit CSV 를 가져온다.
+func ImportHealth(im *HealthImporter, reader io.Reader, name string) error {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xml":
+		return ImportAppleHealth(im, reader)
+	case ".json":
+		// Google 테이크아웃은 객체, Fitbit 계정 archive 는 배열이다.
+		buffered := bufio.NewReader(reader)
+		if first, err := firstNonSpace(buffered); err != nil {
+			return err
+		} else if first == '[' {
+			return ImportFitbitJSON(im, buffered)
+		}
+		return ImportGoogleFit(im, buffered)
+	case ".csv":
+		return ImportFitbit(im, reader)
+	default:
+		return ErrHealthFormat
+	}
+}
+
+// firstNonSpace 는 앞의 공백을 건너뛰고, 공백이 아닌 첫 byte는 읽지 않은 채로 돌려준다.
+func firstNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			if _, err := reader.ReadByte(); err != nil {
+				return 0, err
+			}
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+func ImportHealthFile(im *HealthImporter, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return ImportHealth(im, file, path)
+}
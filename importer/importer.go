@@ -0,0 +1,310 @@
+package importer
+
+import (
+	"errors"
+	"github.com/go-xorm/xorm"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/models"
+)
+
+// 응답이 너무 커지지 않도록 알려주는 에러의 최대 개수
+const maxReportedErrors = 1000
+
+type Summary struct {
+	DryRun  bool       `json:"DryRun"`
+	Total   int        `json:"Total"`
+	Created int        `json:"Created"`
+	Updated int        `json:"Updated"`
+	Skipped int        `json:"Skipped"` // 이미 있는 기록과 겹쳐서 건너뛴 수
+	Failed  int        `json:"Failed"`
+	Errors  []RowError `json:"Errors"`
+}
+
+// Fail 은 record로 바꾸지 못한 줄을 실패로 기록한다.
+func (s *Summary) Fail(errs ...RowError) {
+	s.Total++
+	s.fail(errs...)
+}
+
+// fail 은 이미 Total 에 센 줄을 실패로 기록한다. 에러는 maxReportedErrors 개까지만 남긴다.
+func (s *Summary) fail(errs ...RowError) {
+	s.Failed++
+	for _, err := range errs {
+		if len(s.Errors) < maxReportedErrors {
+			s.Errors = append(s.Errors, err)
+		}
+	}
+}
+
+// Importer 는 record를 하나씩 검증하고 같은 food가 있으면 바꾸고, 없으면 새로 만든다.
+// 출처가 있는 record는 출처와 ID가 같은 food를, 없는 record는 이름과 brand가 같은 food를 같은 food로 본다.
+// 없는 brand와 category는 이름으로 새로 만든다. record 마다 따로 저장하므로 실패한 record가 있어도 나머지는 저장된다.
+// DryRun 이면 검증과 생성/변경 여부만 Summary 에 기록하고 아무것도 저장하지 않는다.
+type Importer struct {
+	Summary Summary
+
+	brands     map[string]int64
+	categories map[string]int64
+	// DryRun 에서 앞서 새로 만든다고 기록한 food
+	planned map[string]bool
+	// 만들거나 바꾼 food의 ID
+	changed []int64
+}
+
+func New(dryRun bool) *Importer {
+	return &Importer{Summary: Summary{DryRun: dryRun, Errors: make([]RowError, 0)}, brands: make(map[string]int64),
+		categories: make(map[string]int64), planned: make(map[string]bool)}
+}
+
+// Import 는 record 하나를 저장하고 결과를 Summary 에 기록한다.
+// 반환하는 error 는 DB 에러처럼 다음 record도 처리할 수 없는 경우에만 돌려준다.
+func (im *Importer) Import(record Record) error {
+	_, err := im.importRecord(record)
+	return err
+}
+
+// importRecord 는 Import 와 같고, 저장한 food를 함께 돌려준다.
+// 검증에 실패했거나 DryRun 에서 새로 만드는 food는 nil 이다.
+func (im *Importer) importRecord(record Record) (*models.Food, error) {
+	im.Summary.Total++
+
+	if errs := record.Validate(); len(errs) > 0 {
+		im.Summary.fail(errs...)
+		return nil, nil
+	}
+
+	food, created, rowErr, err := im.upsert(record)
+	if err != nil {
+		return nil, err
+	} else if rowErr != nil {
+		im.Summary.fail(*rowErr)
+		return nil, nil
+	}
+
+	if created {
+		im.Summary.Created++
+	} else {
+		im.Summary.Updated++
+	}
+	if !im.Summary.DryRun {
+		im.changed = append(im.changed, food.Id)
+	}
+
+	return food, nil
+}
+
+// lookup 은 이름으로 id를 찾고, 없으면 create 로 만든다.
+// DryRun 에서는 만들지 않고 0 을 돌려준다.
+func (im *Importer) lookup(cache map[string]int64, name string, find func(string) (int64, error),
+	create func(string) (int64, error)) (int64, error) {
+	if id, ok := cache[name]; ok {
+		return id, nil
+	}
+
+	id, err := find(name)
+	if err != nil {
+		return 0, err
+	}
+
+	if id == 0 && !im.Summary.DryRun {
+		if id, err = create(name); err != nil {
+			return 0, err
+		}
+	}
+
+	cache[name] = id
+
+	return id, nil
+}
+
+func findBrand(name string) (int64, error) {
+	brand, err := models.Brand{}.GetByName(name)
+	if err != nil || brand == nil {
+		return 0, err
+	}
+
+	return brand.Id, nil
+}
+
+func createBrand(name string) (int64, error) {
+	brand := models.Brand{Name: name}
+	if _, err := brand.Create(); err != nil {
+		return 0, err
+	}
+
+	return brand.Id, nil
+}
+
+func findCategory(name string) (int64, error) {
+	category, err := models.Category{}.GetByName(name)
+	if err != nil || category == nil {
+		return 0, err
+	}
+
+	return category.Id, nil
+}
+
+func createCategory(name string) (int64, error) {
+	category := models.Category{Name: name}
+	if _, err := category.Create(); err != nil {
+		return 0, err
+	}
+
+	return category.Id, nil
+}
+
+// upsert 는 record를 저장한다. 저장된 food와 맞지 않아서 저장할 수 없는 record는 RowError 를 돌려준다.
+func (im *Importer) upsert(record Record) (*models.Food, bool, *RowError, error) {
+	var brandId int64
+	if record.BrandName != "" {
+		var err error
+		if brandId, err = im.lookup(im.brands, record.BrandName, findBrand, createBrand); err != nil {
+			return nil, false, nil, err
+		}
+	}
+
+	categoryId, err := im.lookup(im.categories, record.CategoryName, findCategory, createCategory)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	var food *models.Food
+	if record.Source != "" && record.SourceId != "" {
+		if food, err = (models.Food{}).GetBySource(record.Source, record.SourceId); err != nil {
+			return nil, false, nil, err
+		}
+	} else if record.BrandName == "" || brandId != 0 {
+		// DryRun 에서 아직 없는 brand의 food는 새로 만드는 것이다.
+		if food, err = (models.Food{}).GetByNameAndBrand(record.Name, brandId); err != nil {
+			return nil, false, nil, err
+		}
+	}
+
+	// 바코드는 상품 하나에만 붙는다. 찾지 못한 food는 같은 출처에서 가져온 같은 바코드의 food를 갱신하고,
+	// 찾은 food와 다른 food가 이미 가지고 있는 바코드는 저장하지 않는다.
+	barcode := record.Barcode
+	if barcode != "" {
+		owner, err := models.Food{}.GetByBarcode(barcode)
+		if err != nil {
+			return nil, false, nil, err
+		}
+
+		if food == nil && owner != nil {
+			if owner.Source != record.Source {
+				// 다른 출처의 food를 이 record로 덮어쓰면 그 출처에서 다시 가져올 때 찾지 못한다.
+				return nil, false, &RowError{Line: record.Line, Field: "Barcode",
+					Message: "다른 출처에서 가져온 food가 이미 쓰는 바코드입니다."}, nil
+			}
+			food = owner
+		} else if owner != nil && owner.Id != food.Id {
+			barcode = ""
+		}
+	}
+
+	if food != nil {
+		if rowErr, err := checkUnit(record, *food); err != nil || rowErr != nil {
+			return nil, false, rowErr, err
+		}
+	}
+
+	if im.Summary.DryRun {
+		key := record.Source + "\x00" + record.SourceId + "\x00" + record.BrandName + "\x00" + record.Name
+		if food != nil || im.planned[key] {
+			return food, false, nil, nil
+		}
+		im.planned[key] = true
+		return nil, true, nil, nil
+	}
+
+	created := food == nil
+	if created {
+		food = &models.Food{}
+	}
+	food.Name, food.BrandId, food.CategoryId, food.Weight, food.Density, food.Abv =
+		record.Name, brandId, categoryId, record.Weight, record.Density, record.Abv
+	// 출처가 없는 record(CSV 로 내보냈다가 다시 가져온 것 등)는 이미 있는 출처를 지우지 않는다.
+	if record.Source != "" {
+		food.Source, food.SourceId = record.Source, record.SourceId
+	}
+	if barcode != "" {
+		food.Barcode = barcode
+	}
+
+	// 도수로 알코올 양을 계산할 수 있는지는 Validate 에서 확인했다.
+	nutrient := record.nutrient()
+	nutrient.FillAlcohol(*food)
+
+	err = factory.Transaction(func(session *xorm.Session) error {
+		if created {
+			if _, err := food.CreateWithSes(session); err != nil {
+				return errors.New("food insert 실패")
+			}
+		} else if err := food.ReplaceWithSes(session); err != nil {
+			return errors.New("food update 실패")
+		}
+
+		existing, err := models.Nutrient{}.GetByFoodIdWithSes(session, food.Id)
+		if err != nil {
+			return err
+		}
+
+		nutrient.FoodId = food.Id
+		if existing == nil {
+			if _, err := nutrient.CreateWithSes(session); err != nil {
+				return errors.New("nutrient insert 실패")
+			}
+		} else {
+			nutrient.Id = existing.Id
+			if err := nutrient.ReplaceWithSes(session); err != nil {
+				return errors.New("nutrient update 실패")
+			}
+		}
+
+		// record가 food의 영양 정보 전체이므로 record에 없는 비타민/무기질은 지운다.
+		if err := models.ReplaceMicronutrientsWithSes(session, nutrient.Id, record.Micronutrients); err != nil {
+			return errors.New("micronutrient insert 실패")
+		}
+
+		if len(record.ServingSizes) > 0 {
+			servingSizes := make([]models.ServingSize, len(record.ServingSizes))
+			for idx, servingSize := range record.ServingSizes {
+				servingSizes[idx] = models.ServingSize{Label: servingSize.Label, Grams: servingSize.Grams}
+			}
+
+			if err := models.SetServingSizesWithSes(session, food.Id, servingSizes); err != nil {
+				return errors.New("serving size insert 실패")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	return food, created, nil, nil
+}
+
+// checkUnit 은 저장한 양이 다른 단위로 읽히지 않도록, 식사 기록이나 레시피에 쓰인 food의 단위를 바꾸는 record를 막는다.
+func checkUnit(record Record, food models.Food) (*RowError, error) {
+	nutrient, err := models.Nutrient{}.GetByFoodId(food.Id)
+	if err != nil || nutrient == nil || nutrient.Unit == record.Unit {
+		return nil, err
+	}
+
+	hasQuantities, err := food.HasQuantities()
+	if err != nil || !hasQuantities {
+		return nil, err
+	}
+
+	return &RowError{Line: record.Line, Field: "Unit", Message: "식사 기록이나 레시피에 쓰인 food는 단위를 바꿀 수 없습니다."}, nil
+}
+
+// Finish 는 import가 끝난 뒤 바뀐 food가 검색되도록 검색 색인을 다시 만든다.
+func (im *Importer) Finish() error {
+	if im.Summary.DryRun || im.Summary.Created+im.Summary.Updated == 0 {
+		return nil
+	}
+
+	return models.RebuildSearchIndex()
+}
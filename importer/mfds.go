@@ -0,0 +1,338 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/units"
+	"github.com/tealeg/xlsx"
+	"golang.org/x/text/encoding/korean"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SourceMFDS 는 식품의약품안전처 식품영양성분 DB에서 가져온 food의 Source 이다.
+const SourceMFDS = "mfds"
+
+var ErrMFDSHeaderNotFound = errors.New("식품명 column을 찾을 수 없습니다")
+
+// header를 찾을 때 앞에서부터 살펴볼 줄 수. 엑셀 파일은 제목 줄이 먼저 나오기도 한다.
+const mfdsHeaderSearchRows = 10
+
+// mfdsColumns 는 record field 마다 식품영양성분 DB에서 쓰는 column 이름들이다.
+// 판마다 이름이 조금씩 달라서 괄호 앞 부분을 공백 없이 비교한다. ("에너지(kcal)" -> "에너지")
+var mfdsColumns = map[string][]string{
+	"code":         {"식품코드", "food_cd"},
+	"name":         {"식품명", "desc_kor"},
+	"category":     {"식품대분류명", "식품대분류", "식품군", "group_name"},
+	"brand":        {"제조사명", "업체명", "제조사", "maker_name"},
+	"base":         {"영양성분함량기준량", "영양성분기준용량", "기준량", "serving_wt", "serving_size"},
+	"calorie":      {"에너지", "열량", "nutr_cont1"},
+	"carbohydrate": {"탄수화물", "nutr_cont2"},
+	"protein":      {"단백질", "nutr_cont3"},
+	"fat":          {"지방", "nutr_cont4"},
+	"sugars":       {"당류", "총당류", "nutr_cont5"},
+	"sodium":       {"나트륨", "nutr_cont6"},
+	"cholesterol":  {"콜레스테롤", "nutr_cont7"},
+	"saturatedFat": {"포화지방산", "총포화지방산", "포화지방", "nutr_cont8"},
+	"transFat":     {"트랜스지방산", "총트랜스지방산", "트랜스지방", "nutr_cont9"},
+	"monoFat":      {"단일불포화지방산", "총단일불포화지방산"},
+	"polyFat":      {"다중불포화지방산", "총다중불포화지방산"},
+	"dietaryFiber": {"식이섬유", "총식이섬유"},
+	"vitamin_a":    {"비타민a"},
+	"vitamin_b1":   {"티아민", "비타민b1"},
+	"vitamin_b2":   {"리보플라빈", "비타민b2"},
+	"vitamin_b6":   {"비타민b6"},
+	"vitamin_b12":  {"비타민b12"},
+	"vitamin_c":    {"비타민c"},
+	"vitamin_d":    {"비타민d"},
+	"vitamin_e":    {"비타민e"},
+	"vitamin_k":    {"비타민k", "비타민k1"},
+	"niacin":       {"니아신"},
+	"folate":       {"엽산"},
+	"calcium":      {"칼슘"},
+	"iron":         {"철"},
+	"magnesium":    {"마그네슘"},
+	"phosphorus":   {"인"},
+	"potassium":    {"칼륨"},
+	"zinc":         {"아연"},
+}
+
+// 영양소 field의 저장 단위. column 이름의 괄호 안 단위가 다르면 바꿔서 저장한다.
+var mfdsFieldUnits = map[string]string{
+	"carbohydrate": "g", "protein": "g", "fat": "g", "sugars": "g", "saturatedFat": "g", "transFat": "g",
+	"monoFat": "g", "polyFat": "g", "dietaryFiber": "g", "sodium": "mg", "cholesterol": "mg",
+	"alcohol": "g",
+}
+
+var massUnitFactors = map[string]float64{"g": 1, "mg": 0.001, "µg": 0.000001, "μg": 0.000001, "ug": 0.000001}
+
+type mfdsColumn struct {
+	idx int
+	// column 값을 저장 단위로 바꿀 때 곱하는 값
+	scale float64
+}
+
+func normalizeMFDSHeader(header string) (name, unit string) {
+	header = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(header), "\ufeff"))
+	if idx := strings.IndexAny(header, "(["); idx >= 0 {
+		if fields := strings.Fields(strings.Trim(header[idx:], "()[] ")); len(fields) > 0 {
+			unit = fields[0]
+		}
+		header = header[:idx]
+	}
+
+	return strings.Join(strings.Fields(header), ""), unit
+}
+
+// fieldUnit 은 field를 저장하는 단위이다. 질량 단위가 아닌 field는 빈 문자열이다.
+func fieldUnit(field string) string {
+	if unit, ok := mfdsFieldUnits[field]; ok {
+		return unit
+	}
+
+	return models.MicronutrientUnits[field]
+}
+
+func findMFDSColumns(header []string) map[string]mfdsColumn {
+	type headerColumn struct {
+		idx  int
+		unit string
+	}
+
+	byName := make(map[string]headerColumn, len(header))
+	for idx, value := range header {
+		name, unit := normalizeMFDSHeader(value)
+		if _, ok := byName[name]; !ok {
+			byName[name] = headerColumn{idx: idx, unit: unit}
+		}
+	}
+
+	columns := make(map[string]mfdsColumn)
+	for field, aliases := range mfdsColumns {
+		for _, alias := range aliases {
+			found, ok := byName[alias]
+			if !ok {
+				continue
+			}
+
+			column := mfdsColumn{idx: found.idx, scale: 1}
+			from, fromOk := massUnitFactors[found.unit]
+			to, toOk := massUnitFactors[fieldUnit(field)]
+			if fromOk && toOk {
+				column.scale = from / to
+			}
+
+			columns[field] = column
+			break
+		}
+	}
+
+	return columns
+}
+
+type mfdsRow struct {
+	line    int
+	columns map[string]mfdsColumn
+	values  []string
+	errs    []RowError
+}
+
+func (r *mfdsRow) text(field string) string {
+	column, ok := r.columns[field]
+	if !ok || column.idx >= len(r.values) {
+		return ""
+	}
+
+	return strings.TrimSpace(r.values[column.idx])
+}
+
+// number 는 값을 모르면("-", 빈 칸) false 를 돌려준다. 미량(tr)은 0 이다.
+func (r *mfdsRow) number(field string) (float64, bool) {
+	value := strings.Replace(r.text(field), ",", "", -1)
+	switch strings.ToLower(value) {
+	case "", "-", "n/a":
+		return 0, false
+	case "tr":
+		return 0, true
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		r.errs = append(r.errs, RowError{Line: r.line, Field: field, Message: "숫자가 아닙니다."})
+		return 0, false
+	}
+
+	return parsed * r.columns[field].scale, true
+}
+
+func (r *mfdsRow) value(field string) float32 {
+	value, _ := r.number(field)
+	return float32(value)
+}
+
+func (r *mfdsRow) optional(field string) *float32 {
+	value, ok := r.number(field)
+	if !ok {
+		return nil
+	}
+
+	result := float32(value)
+
+	return &result
+}
+
+// base 는 "100g", "100 ml" 같은 영양성분 기준량을 양과 단위로 나눈다. 없으면 100g 이다.
+func (r *mfdsRow) base() (int32, units.Unit) {
+	value := strings.ToLower(strings.Replace(r.text("base"), " ", "", -1))
+	if value == "" {
+		return 100, units.Gram
+	}
+
+	end := strings.IndexFunc(value, func(c rune) bool { return (c < '0' || c > '9') && c != '.' })
+	if end < 0 {
+		end = len(value)
+	}
+
+	amount, err := strconv.ParseFloat(value[:end], 64)
+	if err != nil || amount <= 0 {
+		r.errs = append(r.errs, RowError{Line: r.line, Field: "base", Message: "영양성분 기준량을 알 수 없습니다."})
+		return 0, units.Gram
+	}
+
+	unit := units.Gram
+	if symbol := value[end:]; symbol != "" {
+		if unit, err = units.Parse(symbol); err != nil {
+			r.errs = append(r.errs, RowError{Line: r.line, Field: "base", Message: "알 수 없는 단위입니다."})
+		}
+	}
+
+	return int32(math.Round(amount)), unit
+}
+
+func (r *mfdsRow) record() Record {
+	perUnit, unit := r.base()
+	calorie, _ := r.number("calorie")
+
+	record := Record{Line: r.line, Source: SourceMFDS, SourceId: r.text("code"), Name: r.text("name"),
+		BrandName: r.text("brand"), CategoryName: r.text("category"), PerUnit: perUnit, Unit: unit,
+		Calorie: int64(math.Round(calorie)), Carbohydrate: r.value("carbohydrate"), Protein: r.value("protein"),
+		SaturatedFat: r.value("saturatedFat"), TransFat: r.value("transFat"),
+		Sodium: r.optional("sodium"), Sugars: r.optional("sugars"), DietaryFiber: r.optional("dietaryFiber"),
+		Cholesterol: r.optional("cholesterol"), Micronutrients: make(map[string]float32)}
+
+	if record.SourceId == "" {
+		// 식품코드가 없는 판은 이름과 brand로 같은 food를 찾는다.
+		record.Source = ""
+	}
+
+	switch record.BrandName {
+	case "-", "해당없음", "없음":
+		record.BrandName = ""
+	}
+	if record.CategoryName == "" {
+		record.CategoryName = "기타"
+	}
+
+	// 불포화지방은 따로 없으면 총 지방에서 포화지방과 트랜스지방을 뺀다.
+	mono, hasMono := r.number("monoFat")
+	poly, hasPoly := r.number("polyFat")
+	if hasMono || hasPoly {
+		record.UnSaturatedFat = float32(mono + poly)
+	} else if fat, ok := r.number("fat"); ok {
+		record.UnSaturatedFat = float32(math.Max(0, fat-float64(record.SaturatedFat)-float64(record.TransFat)))
+	}
+
+	for _, code := range models.MicronutrientCodes() {
+		if amount, ok := r.number(code); ok {
+			record.Micronutrients[code] = float32(amount)
+		}
+	}
+
+	return record
+}
+
+// ImportMFDSRows 는 header 줄을 포함한 식품영양성분 DB의 줄들을 im 으로 가져온다.
+func ImportMFDSRows(im *Importer, rows [][]string) error {
+	headerIdx := -1
+	var columns map[string]mfdsColumn
+	for idx := 0; idx < len(rows) && idx < mfdsHeaderSearchRows; idx++ {
+		columns = findMFDSColumns(rows[idx])
+		if _, ok := columns["name"]; ok {
+			headerIdx = idx
+			break
+		}
+	}
+	if headerIdx < 0 {
+		return ErrMFDSHeaderNotFound
+	}
+
+	for idx := headerIdx + 1; idx < len(rows); idx++ {
+		if isEmptyRow(rows[idx]) {
+			continue
+		}
+
+		row := mfdsRow{line: idx + 1, columns: columns, values: rows[idx]}
+		record := row.record()
+		if len(row.errs) > 0 {
+			im.Summary.Fail(row.errs...)
+			continue
+		}
+
+		if err := im.Import(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isEmptyRow(values []string) bool {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ImportMFDSFile 은 내려받은 식품영양성분 DB 파일(.xlsx 또는 .csv)을 im 으로 가져온다.
+// CSV 는 UTF-8 이 아니면 EUC-KR 로 읽는다.
+func ImportMFDSFile(im *Importer, path string) error {
+	var rows [][]string
+	if strings.ToLower(filepath.Ext(path)) == ".xlsx" {
+		sheets, err := xlsx.FileToSlice(path)
+		if err != nil {
+			return err
+		} else if len(sheets) == 0 {
+			return ErrMFDSHeaderNotFound
+		}
+		rows = sheets[0]
+	} else {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if !utf8.Valid(data) {
+			if data, err = korean.EUCKR.NewDecoder().Bytes(data); err != nil {
+				return err
+			}
+		}
+
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+		r.LazyQuotes = true
+		if rows, err = r.ReadAll(); err != nil {
+			return err
+		}
+	}
+
+	return ImportMFDSRows(im, rows)
+}
@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"github.com/kernelgarden/diet/units"
+	"strconv"
+	"strings"
+)
+
+// MyFitnessPalColumns 는 MyFitnessPal 에서 내보낸 식단 기록 CSV 의 column이다.
+// 음식마다 한 줄이고, 영양소는 먹은 양 전체의 값이며 Food Name 은 "brand - 이름, 1 cup" 형식이다.
+// 끼니마다 합계만 있는 Nutrition Summary CSV 는 음식 이름이 없어서 가져올 수 없다.
+var MyFitnessPalColumns = DiaryColumns{Date: "Date", Time: "Time", Meal: "Meal", Food: "Food Name",
+	Calorie: "Calories", Carbohydrate: "Carbohydrates (g)", Protein: "Protein (g)", Fat: "Fat (g)",
+	SaturatedFat: "Saturated Fat", PolyunsaturatedFat: "Polyunsaturated Fat",
+	MonounsaturatedFat: "Monounsaturated Fat", TransFat: "Trans Fat", Sodium: "Sodium (mg)", Sugars: "Sugar",
+	DietaryFiber: "Fiber", Cholesterol: "Cholesterol", FoodWithServing: true}
+
+// MyFitnessPal 은 미국 계량 단위를 쓰지만 units 의 컵, 큰술, 작은술은 한국 기준(200ml, 15ml, 5ml)이므로
+// 이 단위들은 ml 로 바꿔서 읽는다. 값은 단위 하나의 ml 이다.
+var myFitnessPalVolumes = map[string]float64{
+	"cup":         236.5882365,
+	"fl oz":       29.5735295625,
+	"fluid ounce": 29.5735295625,
+	"tablespoon":  14.78676478125,
+	"tbsp":        14.78676478125,
+	"teaspoon":    4.92892159375,
+	"tsp":         4.92892159375,
+}
+
+// myFitnessPalVolume 은 미국 계량 단위 하나의 ml 을 돌려준다. "cups", "fl. oz" 처럼 적은 것도 읽는다.
+func myFitnessPalVolume(symbol string) (float64, bool) {
+	symbol = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(symbol)), "(s)")
+	symbol = strings.Replace(symbol, ".", "", -1)
+
+	for _, name := range []string{symbol, strings.TrimSuffix(symbol, "s")} {
+		if milliliters, ok := myFitnessPalVolumes[name]; ok {
+			return milliliters, true
+		}
+	}
+
+	return 0, false
+}
+
+// splitFoodName 은 "Kirkland - Greek Yogurt, 1 cup" 을 brand, 이름, 먹은 양으로 나눈다.
+// 양을 읽을 수 없으면 0 이다.
+func splitFoodName(food string) (string, string, float64, units.Unit) {
+	name, brand, quantity, unit := food, "", 0.0, units.Unknown
+	if idx := strings.LastIndex(name, ", "); idx >= 0 {
+		if amount, amountUnit, ok := parseServing(name[idx+2:]); ok {
+			name, quantity, unit = name[:idx], amount, amountUnit
+		}
+	}
+
+	if idx := strings.Index(name, " - "); idx > 0 {
+		brand, name = name[:idx], name[idx+3:]
+	}
+
+	return strings.TrimSpace(brand), strings.TrimSpace(name), quantity, unit
+}
+
+// parseServing 은 "1 cup (8 fl oz)", "1/2 serving(s)" 같은 먹은 양을 읽는다. 단위가 없으면 인분이다.
+// 미국 계량 단위인 컵, fl oz, 큰술, 작은술은 ml 로 바꾼다.
+func parseServing(serving string) (float64, units.Unit, bool) {
+	if idx := strings.Index(serving, " ("); idx >= 0 {
+		serving = serving[:idx]
+	}
+
+	fields := strings.Fields(serving)
+	if len(fields) == 0 {
+		return 0, units.Unknown, false
+	}
+
+	quantity, ok := parseAmount(fields[0])
+	if !ok {
+		return 0, units.Unknown, false
+	}
+
+	symbol := strings.Join(fields[1:], " ")
+	if milliliters, ok := myFitnessPalVolume(symbol); ok {
+		return quantity * milliliters, units.Milliliter, true
+	}
+
+	unit := parseDiaryUnit(symbol)
+	if unit == units.Unknown {
+		unit = units.Serving
+	}
+
+	return quantity, unit, true
+}
+
+// parseAmount 는 "1.5", "1,000", "1/2" 를 숫자로 바꾼다.
+func parseAmount(amount string) (float64, bool) {
+	if parts := strings.SplitN(amount, "/", 2); len(parts) == 2 {
+		numerator, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil {
+			return 0, false
+		}
+
+		denominator, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || denominator == 0 {
+			return 0, false
+		}
+
+		return numerator / denominator, true
+	}
+
+	value, err := strconv.ParseFloat(strings.Replace(amount, ",", "", -1), 64)
+
+	return value, err == nil
+}
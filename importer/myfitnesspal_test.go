@@ -0,0 +1,48 @@
+package importer
+
+import (
+	"github.com/kernelgarden/diet/units"
+	"math"
+	"testing"
+)
+
+func TestParseServing(t *testing.T) {
+	tests := []struct {
+		serving  string
+		quantity float64
+		unit     units.Unit
+	}{
+		{"1 cup", 236.5882365, units.Milliliter},
+		{"2 cups", 473.176473, units.Milliliter},
+		{"1/2 cup (4 fl oz)", 118.29411825, units.Milliliter},
+		{"8 fl oz", 236.5882365, units.Milliliter},
+		{"12 fl. oz", 354.88235475, units.Milliliter},
+		{"1 tbsp", 14.78676478125, units.Milliliter},
+		{"3 teaspoons", 14.78676478125, units.Milliliter},
+		{"100 g", 100, units.Gram},
+		{"1 oz", 1, units.Ounce},
+		{"250 ml", 250, units.Milliliter},
+		{"1 serving(s)", 1, units.Serving},
+		{"2 slices", 2, units.Serving},
+		{"1", 1, units.Serving},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serving, func(t *testing.T) {
+			quantity, unit, ok := parseServing(tt.serving)
+			if !ok {
+				t.Fatalf("parseServing(%q) failed", tt.serving)
+			}
+			if math.Abs(quantity-tt.quantity) > 1e-6 || unit != tt.unit {
+				t.Errorf("parseServing(%q) = %v %v, want %v %v", tt.serving, quantity, unit, tt.quantity, tt.unit)
+			}
+		})
+	}
+}
+
+func TestSplitFoodName(t *testing.T) {
+	brand, name, quantity, unit := splitFoodName("Kirkland - Greek Yogurt, 1 cup")
+	if brand != "Kirkland" || name != "Greek Yogurt" || math.Abs(quantity-236.5882365) > 1e-6 || unit != units.Milliliter {
+		t.Errorf("splitFoodName() = %q %q %v %v", brand, name, quantity, unit)
+	}
+}
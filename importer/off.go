@@ -0,0 +1,312 @@
+package importer
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/kernelgarden/diet/gtin"
+	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/units"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SourceOFF 는 Open Food Facts 에서 가져온 food의 Source 이다.
+const SourceOFF = "off"
+
+// Open Food Facts 의 _100g 값은 모두 g 단위이다. field 마다 nutriment 이름을 적는다.
+var offNutriments = map[string]string{
+	"carbohydrate": "carbohydrates",
+	"protein":      "proteins",
+	"fat":          "fat",
+	"saturatedFat": "saturated-fat",
+	"transFat":     "trans-fat",
+	"monoFat":      "monounsaturated-fat",
+	"polyFat":      "polyunsaturated-fat",
+	"sodium":       "sodium",
+	"sugars":       "sugars",
+	"dietaryFiber": "fiber",
+	"cholesterol":  "cholesterol",
+	"vitamin_a":    "vitamin-a",
+	"vitamin_b1":   "vitamin-b1",
+	"vitamin_b2":   "vitamin-b2",
+	"vitamin_b6":   "vitamin-b6",
+	"vitamin_b12":  "vitamin-b12",
+	"vitamin_c":    "vitamin-c",
+	"vitamin_d":    "vitamin-d",
+	"vitamin_e":    "vitamin-e",
+	"vitamin_k":    "vitamin-k",
+	"niacin":       "vitamin-pp",
+	"folate":       "folates",
+	"calcium":      "calcium",
+	"iron":         "iron",
+	"magnesium":    "magnesium",
+	"phosphorus":   "phosphorus",
+	"potassium":    "potassium",
+	"zinc":         "zinc",
+}
+
+// offProduct 는 JSONL 과 CSV 에서 같은 이름의 값을 읽는다.
+type offProduct func(key string) string
+
+func (p offProduct) first(keys ...string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(p(key)); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// firstItem 은 "a, b, c" 처럼 쉼표로 나열된 값의 첫 번째 항목이다.
+func firstItem(value string) string {
+	return strings.TrimSpace(strings.Split(value, ",")[0])
+}
+
+func (p offProduct) nutriment(name string) (float64, bool) {
+	value := strings.TrimSpace(p(name + "_100g"))
+	if value == "" {
+		return 0, false
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 {
+		return 0, false
+	}
+
+	return parsed, true
+}
+
+// value 는 field의 값을 저장 단위로 바꿔서 돌려준다.
+func (p offProduct) value(field string) (float64, bool) {
+	value, ok := p.nutriment(offNutriments[field])
+	if !ok {
+		return 0, false
+	}
+
+	if to, ok := massUnitFactors[fieldUnit(field)]; ok {
+		value = value / to
+	}
+
+	return value, true
+}
+
+func (p offProduct) optional(field string) *float32 {
+	value, ok := p.value(field)
+	if !ok {
+		return nil
+	}
+
+	result := float32(value)
+
+	return &result
+}
+
+func (p offProduct) record(line int) Record {
+	code := p.first("code")
+	record := Record{Line: line, Source: SourceOFF, SourceId: code,
+		Name:         truncate(p.first("product_name_ko", "product_name", "generic_name_ko", "generic_name"), 64),
+		BrandName:    truncate(firstItem(p.first("brands")), 64),
+		CategoryName: truncate(firstItem(p.first("categories")), 64),
+		PerUnit:      100, Unit: units.Gram, Micronutrients: make(map[string]float32)}
+
+	if barcode, err := gtin.Normalize(code); err == nil {
+		record.Barcode = barcode
+	}
+	if record.CategoryName == "" {
+		record.CategoryName = "기타"
+	}
+
+	// 음료는 100ml 기준으로 표시한다.
+	if strings.HasPrefix(p.first("nutrition_data_per"), "100ml") || isVolumeQuantity(p.first("quantity")) {
+		record.Unit = units.Milliliter
+	}
+
+	calorie, ok := p.nutriment("energy-kcal")
+	if !ok {
+		if energy, ok := p.nutriment("energy"); ok {
+			calorie = energy / 4.184
+		}
+	}
+	record.Calorie = int64(math.Round(calorie))
+
+	value := func(field string) float32 {
+		v, _ := p.value(field)
+		return float32(v)
+	}
+	record.Carbohydrate, record.Protein = value("carbohydrate"), value("protein")
+	record.SaturatedFat, record.TransFat = value("saturatedFat"), value("transFat")
+
+	mono, hasMono := p.value("monoFat")
+	poly, hasPoly := p.value("polyFat")
+	if hasMono || hasPoly {
+		record.UnSaturatedFat = float32(mono + poly)
+	} else if fat, ok := p.value("fat"); ok {
+		record.UnSaturatedFat = float32(math.Max(0, fat-float64(record.SaturatedFat)-float64(record.TransFat)))
+	}
+
+	record.Sodium, record.Sugars = p.optional("sodium"), p.optional("sugars")
+	record.DietaryFiber, record.Cholesterol = p.optional("dietaryFiber"), p.optional("cholesterol")
+	// alcohol_100g 은 g이 아니라 도수(% vol)이다.
+	if abv, ok := p.nutriment("alcohol"); ok && abv <= models.MaxAbv {
+		record.Abv = abv
+	}
+	if record.Sodium == nil {
+		// 소금만 표시한 상품은 나트륨으로 바꾼다. (소금 1g = 나트륨 400mg)
+		if salt, ok := p.nutriment("salt"); ok {
+			sodium := float32(salt * 400)
+			record.Sodium = &sodium
+		}
+	}
+
+	for _, code := range models.MicronutrientCodes() {
+		if amount, ok := p.value(code); ok {
+			record.Micronutrients[code] = float32(amount)
+		}
+	}
+
+	if grams, err := strconv.ParseFloat(p.first("serving_quantity"), 64); err == nil && grams > 0 && record.Unit == units.Gram {
+		label := p.first("serving_size")
+		if label == "" {
+			label = strconv.FormatFloat(grams, 'f', -1, 64) + " g"
+		}
+		record.ServingSizes = []ServingSize{{Label: truncate(label, 32), Grams: grams}}
+	}
+
+	return record
+}
+
+func isVolumeQuantity(quantity string) bool {
+	fields := strings.Fields(strings.ToLower(quantity))
+	if len(fields) == 0 {
+		return false
+	}
+
+	switch strings.TrimLeft(fields[len(fields)-1], "0123456789.,") {
+	case "ml", "cl", "dl", "l":
+		return true
+	}
+
+	return false
+}
+
+// offJSONValue 는 JSON 값을 문자열로 바꾼다. Open Food Facts 는 숫자를 문자열로 저장하기도 한다.
+func offJSONValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+// ImportOFFJSONL 은 한 줄에 상품 하나씩 있는 Open Food Facts JSONL 을 im 으로 가져온다.
+func ImportOFFJSONL(im *Importer, reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	// 상품 하나가 수 MB 인 경우도 있다.
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var product map[string]interface{}
+		decoder := json.NewDecoder(strings.NewReader(scanner.Text()))
+		decoder.UseNumber()
+		if err := decoder.Decode(&product); err != nil {
+			im.Summary.Fail(RowError{Line: line, Message: err.Error()})
+			continue
+		}
+
+		nutriments, _ := product["nutriments"].(map[string]interface{})
+		get := func(key string) string {
+			if value, ok := product[key]; ok {
+				return offJSONValue(value)
+			}
+			return offJSONValue(nutriments[key])
+		}
+
+		if err := im.Import(offProduct(get).record(line)); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// ImportOFFCSV 는 Open Food Facts 의 CSV export(tab 으로 나뉜 파일)를 im 으로 가져온다.
+func ImportOFFCSV(im *Importer, reader io.Reader) error {
+	r := csv.NewReader(reader)
+	r.Comma = '\t'
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return err
+	}
+
+	columns := make(map[string]int, len(header))
+	for idx, name := range header {
+		columns[strings.TrimSpace(name)] = idx
+	}
+
+	for line := 2; ; line++ {
+		values, err := r.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			if _, ok := err.(*csv.ParseError); ok {
+				im.Summary.Fail(RowError{Line: line, Message: err.Error()})
+				continue
+			}
+			return err
+		}
+
+		get := func(key string) string {
+			if idx, ok := columns[key]; ok && idx < len(values) {
+				return values[idx]
+			}
+			return ""
+		}
+
+		if err := im.Import(offProduct(get).record(line)); err != nil {
+			return err
+		}
+	}
+}
+
+// ImportOFFFile 은 확장자에 따라 .jsonl 또는 .csv 파일을 가져온다. .gz 로 압축된 파일도 읽는다.
+func ImportOFFFile(im *Importer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	name := strings.ToLower(path)
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+
+		reader, name = gz, strings.TrimSuffix(name, ".gz")
+	}
+
+	if strings.HasSuffix(name, ".csv") || strings.HasSuffix(name, ".tsv") {
+		return ImportOFFCSV(im, reader)
+	}
+
+	return ImportOFFJSONL(im, reader)
+}
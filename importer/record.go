@@ -0,0 +1,117 @@
+// Package importer 는 외부 파일의 food 정보를 Food, Nutrient, Brand, Category 로 가져오고 내보낸다.
+// 파일 형식마다 Record 로 바꾸는 parser 만 따로 두고, 검증과 저장은 Importer 가 함께 처리한다.
+// 건강 기록(HealthImporter)과 다른 앱의 식단 기록(DiaryImporter)도 같은 방식으로 가져온다.
+package importer
+
+import (
+	"github.com/kernelgarden/diet/gtin"
+	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/units"
+	"github.com/kernelgarden/diet/validator"
+)
+
+// Record 는 외부 파일의 food 한 줄이다. 영양소는 PerUnit 의 Unit 당 값이다.
+type Record struct {
+	// 원본 파일에서의 줄 번호. 에러를 알려줄 때 쓴다.
+	Line int
+
+	// 외부 DB의 출처와 ID. 있으면 이름 대신 이것으로 같은 food를 찾는다.
+	Source   string
+	SourceId string
+
+	Name      string
+	BrandName string
+	// gtin.Normalize 로 맞춘 바코드. 모르면 비어 있다.
+	Barcode      string
+	CategoryName string
+	Weight       float64
+	Density      float64
+	// 알코올 도수(%). Alcohol 을 모르면 도수로 계산한다.
+	Abv float64
+
+	Carbohydrate   float32
+	Protein        float32
+	SaturatedFat   float32
+	UnSaturatedFat float32
+	TransFat       float32
+	PerUnit        int32
+	Calorie        int64
+	Unit           units.Unit
+
+	Sodium         *float32
+	Sugars         *float32
+	DietaryFiber   *float32
+	Cholesterol    *float32
+	Alcohol        *float32
+	Micronutrients map[string]float32
+	// 있으면 food의 serving size를 모두 이것으로 바꾼다.
+	ServingSizes []ServingSize
+}
+
+type ServingSize struct {
+	Label string
+	Grams float64
+}
+
+type RowError struct {
+	Line    int    `json:"Line"`
+	Field   string `json:"Field"`
+	Message string `json:"Message"`
+}
+
+// Validate 는 저장하기 전에 record의 값이 올바른지 확인하고, 잘못된 field 마다 에러를 돌려준다.
+// food와 nutrient는 API 로 만들 때와 같은 validator 로 확인한다.
+func (r Record) Validate() []RowError {
+	errs := make([]RowError, 0)
+	fail := func(field, message string) {
+		errs = append(errs, RowError{Line: r.Line, Field: field, Message: message})
+	}
+
+	if normalized, err := gtin.Normalize(r.Barcode); r.Barcode != "" && (err != nil || normalized != r.Barcode) {
+		fail("Barcode", "올바르지 않은 바코드입니다.")
+	}
+	if r.CategoryName == "" {
+		fail("Category", "category가 없습니다.")
+	}
+
+	food := models.Food{Name: r.Name, Weight: r.Weight, Density: r.Density, Abv: r.Abv}
+	fieldErrs := validator.Food(food)
+	fieldErrs = append(fieldErrs, validator.Nutrient(food, r.nutrient())...)
+	fieldErrs = append(fieldErrs, validator.Micronutrients(r.Micronutrients)...)
+	for _, fieldErr := range fieldErrs {
+		fail(fieldErr.Field, fieldErr.Message)
+	}
+
+	for _, servingSize := range r.ServingSizes {
+		if servingSize.Label == "" || servingSize.Grams <= 0 {
+			fail("ServingSizes", "serving size의 이름과 무게가 필요합니다.")
+		}
+	}
+
+	return errs
+}
+
+func (r Record) nutrient() models.Nutrient {
+	return models.Nutrient{Carbohydrate: r.Carbohydrate, Protein: r.Protein, SaturatedFat: r.SaturatedFat,
+		UnSaturatedFat: r.UnSaturatedFat, TransFat: r.TransFat, PerUnit: r.PerUnit, Calorie: r.Calorie, Unit: r.Unit,
+		Sodium: r.Sodium, Sugars: r.Sugars, DietaryFiber: r.DietaryFiber, Cholesterol: r.Cholesterol, Alcohol: r.Alcohol}
+}
+
+// FromFoodJSON 은 저장된 food를 내보낼 record로 바꾼다.
+func FromFoodJSON(food models.FoodJSON) Record {
+	n := food.Nutrient
+
+	record := Record{Name: food.Food.Name, BrandName: food.Brand.Name, CategoryName: food.Category.Name,
+		Barcode: food.Food.Barcode,
+		Weight:  food.Food.Weight, Density: food.Food.Density, Abv: food.Food.Abv,
+		Carbohydrate: n.Carbohydrate, Protein: n.Protein, SaturatedFat: n.SaturatedFat, UnSaturatedFat: n.UnSaturatedFat,
+		TransFat: n.TransFat, PerUnit: n.PerUnit, Calorie: n.Calorie, Unit: n.Unit,
+		Sodium: n.Sodium, Sugars: n.Sugars, DietaryFiber: n.DietaryFiber, Cholesterol: n.Cholesterol,
+		Alcohol:        n.Alcohol,
+		Micronutrients: make(map[string]float32, len(food.Micronutrients))}
+	for _, micronutrient := range food.Micronutrients {
+		record.Micronutrients[micronutrient.Code] = micronutrient.Amount
+	}
+
+	return record
+}
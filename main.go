@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"github.com/go-xorm/xorm"
 	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/controllers"
 	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/search"
 	"log"
 	"os"
 
@@ -39,6 +41,14 @@ func main() {
 	defer db.(*xorm.Engine).Close()
 	Sync()
 
+	// 명령은 검색 색인을 쓰지 않고, import 는 끝날 때 색인을 다시 만든다.
+	if len(os.Args) > 1 {
+		CheckErr(RunCommand(os.Args[1:]))
+		return
+	}
+
+	CheckErr(models.LoadSearchIndex())
+
 	e := echo.New()
 
 	router.InitRoutes(e)
@@ -49,6 +59,7 @@ func main() {
 	e.Use(middleware.CORS())
 	e.Use(middleware.RequestID())
 	e.Use(middleware.Logger())
+	e.Use(controllers.Authenticate)
 
 	//TODO: Implement log system
 
@@ -76,6 +87,22 @@ func Sync() error {
 	CheckErr(db.Sync(new(models.Category)))
 	CheckErr(db.Sync(new(models.Food)))
 	CheckErr(db.Sync(new(models.Nutrient)))
+	CheckErr(db.Sync(new(models.Micronutrient)))
+	CheckErr(db.Sync(new(models.ServingSize)))
+	CheckErr(db.Sync(new(models.User)))
+	CheckErr(db.Sync(new(models.Profile)))
+	CheckErr(db.Sync(new(models.BodyMeasurement)))
+	CheckErr(db.Sync(new(models.MealEntry)))
+	CheckErr(db.Sync(new(models.Plan)))
+	CheckErr(db.Sync(new(models.PlanDay)))
+	CheckErr(db.Sync(new(models.PlannedMeal)))
+	CheckErr(db.Sync(new(models.Recipe)))
+	CheckErr(db.Sync(new(models.RecipeIngredient)))
+	CheckErr(db.Sync(new(models.DrinkingSession)))
+	CheckErr(db.Sync(new(models.Activity)))
+	CheckErr(db.Sync(new(models.ActivityEntry)))
+	CheckErr(db.Sync(new(search.Document)))
+	CheckErr(db.Sync(new(search.IndexVersion)))
 
 	return nil
 }
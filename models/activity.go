@@ -0,0 +1,270 @@
+package models
+
+import (
+	"github.com/kernelgarden/diet/calculator"
+	"github.com/kernelgarden/diet/factory"
+	"sort"
+	"time"
+)
+
+// Activity 는 운동 종류와 보통 강도로 했을 때의 MET 이다.
+type Activity struct {
+	Id        int64     `json:"Id" xorm:"pk autoincr"`
+	Name      string    `json:"Name" xorm:"varchar(64)"`
+	Met       float64   `json:"Met"`
+	CreatedAt time.Time `json:"-" xorm:"created"`
+	DeletedAt time.Time `json:"-" xorm:"deleted"`
+}
+
+// ActivityEntry 는 user가 한 운동 기록이다.
+// 나중에 몸무게나 운동의 MET가 바뀌어도 기록이 바뀌지 않도록 계산에 쓴 값과 소비 칼로리를 함께 저장한다.
+type ActivityEntry struct {
+	Id          int64     `json:"Id" xorm:"pk autoincr"`
+	UserId      int64     `json:"UserId" xorm:"index"`
+	ActivityId  int64     `json:"ActivityId" xorm:"index"`
+	Name        string    `json:"Name" xorm:"varchar(64)"` // catalog에 없는 운동을 가져왔으면 운동 이름이고, 이때 ActivityId 는 0 이다.
+	Minutes     float64   `json:"Minutes"`
+	Intensity   int32     `json:"Intensity"`
+	Met         float64   `json:"Met"`    // 강도를 반영한 MET
+	Weight      float64   `json:"Weight"` // 계산에 쓴 몸무게(kg)
+	Calorie     float64   `json:"Calorie"`
+	PerformedAt time.Time `json:"PerformedAt" xorm:"index"`
+	Source      string    `json:"Source" xorm:"varchar(16) index(source)"`   // 가져온 기기/앱, 직접 기록하면 비어 있음
+	SourceId    string    `json:"SourceId" xorm:"varchar(64) index(source)"` // 가져온 곳에서의 ID
+	CreatedAt   time.Time `json:"-" xorm:"created"`
+	DeletedAt   time.Time `json:"-" xorm:"deleted"`
+}
+
+type ActivityEntryJSON struct {
+	Entry    ActivityEntry `json:"Entry"`
+	Activity Activity      `json:"Activity"`
+}
+
+// EnergySummary 는 기간 동안 먹은 칼로리와 운동으로 소비한 칼로리, 그 차이이다.
+type EnergySummary struct {
+	Intake float64        `json:"Intake"`
+	Burned float64        `json:"Burned"`
+	Net    float64        `json:"Net"` // Intake - Burned
+	Days   []*DailyEnergy `json:"Days"`
+}
+
+type DailyEnergy struct {
+	Date   string  `json:"Date"`
+	Intake float64 `json:"Intake"`
+	Burned float64 `json:"Burned"`
+	Net    float64 `json:"Net"`
+}
+
+type dailyBurned struct {
+	Date   string
+	Burned float64
+}
+
+func (a *Activity) Create() (int64, error) {
+	return factory.DB().Insert(a)
+}
+
+func (Activity) Get(id int64) (*Activity, error) {
+	var a Activity
+	if has, err := factory.DB().ID(id).Get(&a); err != nil {
+		return &a, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &a, nil
+}
+
+func (Activity) GetByName(name string) (*Activity, error) {
+	var a Activity
+	if has, err := factory.DB().Where("name = ?", name).Get(&a); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &a, nil
+}
+
+func (Activity) GetAll(offset, limit int) ([]*Activity, error) {
+	activities := make([]*Activity, 0)
+	if err := factory.DB().Asc("name").Limit(limit, offset).Find(&activities); err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
+func (a *Activity) Update() error {
+	_, err := factory.DB().ID(a.Id).Update(a)
+	return err
+}
+
+func (Activity) Delete(id int64) error {
+	_, err := factory.DB().ID(id).Delete(&Activity{})
+	return err
+}
+
+// BodyWeightAt 은 user가 at 과 가장 가까운 때에 잰 몸무게를 돌려준다.
+// 측정 기록이 없으면 profile의 몸무게를 쓰고, 그것도 없으면 0이다. 지난 운동의 소비 칼로리를 계산할 때 쓴다.
+func BodyWeightAt(userId int64, at time.Time) (float64, error) {
+	weight, err := BodyMeasurement{}.WeightAt(userId, at)
+	if err != nil || weight > 0 {
+		return weight, err
+	}
+
+	profile, err := Profile{}.GetByUser(userId)
+	if err != nil || profile == nil {
+		return 0, err
+	}
+
+	return profile.Weight, nil
+}
+
+// SetCalorie 는 activity를 weight(kg)인 몸으로 entry의 시간과 강도만큼 했을 때의 소비 칼로리를 계산한다.
+// activity가 catalog에 없으면(Id 가 0) 운동 이름을 entry에 남긴다.
+func (e *ActivityEntry) SetCalorie(activity Activity, weight float64) {
+	e.ActivityId = activity.Id
+	e.Name = ""
+	if activity.Id == 0 {
+		e.Name = activity.Name
+	}
+	e.Met = calculator.ActivityMet(activity.Met, e.Intensity)
+	e.Weight = weight
+	e.Calorie = calculator.BurnedCalorie(e.Met, weight, time.Duration(e.Minutes*float64(time.Minute)))
+}
+
+func (e ActivityEntry) ToJSON() (*ActivityEntryJSON, error) {
+	if e.ActivityId == 0 {
+		return &ActivityEntryJSON{Entry: e, Activity: Activity{Name: e.Name}}, nil
+	}
+
+	// 운동 종류가 지워졌어도 기록은 보여준다.
+	var activity Activity
+	if _, err := factory.DB().Unscoped().ID(e.ActivityId).Get(&activity); err != nil {
+		return nil, err
+	}
+
+	return &ActivityEntryJSON{Entry: e, Activity: activity}, nil
+}
+
+func (e *ActivityEntry) Create() (int64, error) {
+	return factory.DB().Insert(e)
+}
+
+func (ActivityEntry) Get(id int64) (*ActivityEntry, error) {
+	var e ActivityEntry
+	if has, err := factory.DB().ID(id).Get(&e); err != nil {
+		return &e, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &e, nil
+}
+
+// GetByUser 는 [from, to) 사이에 한 user의 운동 기록을 운동한 시간 순서로 돌려준다.
+func (ActivityEntry) GetByUser(userId int64, from, to time.Time, offset, limit int) ([]*ActivityEntry, error) {
+	entries := make([]*ActivityEntry, 0)
+
+	err := factory.DB().
+		Where("user_id = ? AND performed_at >= ? AND performed_at < ?", userId, from, to).
+		Asc("performed_at").
+		Limit(limit, offset).
+		Find(&entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetBySource 는 외부에서 가져온 user의 운동 기록을 출처와 ID로 찾는다.
+func (ActivityEntry) GetBySource(userId int64, source, sourceId string) (*ActivityEntry, error) {
+	var e ActivityEntry
+	if has, err := factory.DB().Where("user_id = ? AND source = ? AND source_id = ?", userId, source, sourceId).Get(&e); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &e, nil
+}
+
+func (e ActivityEntry) EndedAt() time.Time {
+	return e.PerformedAt.Add(time.Duration(e.Minutes * float64(time.Minute)))
+}
+
+// GetOverlapping 은 운동한 시간이 [from, to) 와 겹치는 user의 운동 기록을 돌려준다.
+// 하루보다 오래 한 운동은 기록할 수 없으므로 from 하루 전부터 시작한 기록만 살펴본다.
+func (ActivityEntry) GetOverlapping(userId int64, from, to time.Time) ([]*ActivityEntry, error) {
+	entries := make([]*ActivityEntry, 0)
+	err := factory.DB().
+		Where("user_id = ? AND performed_at >= ? AND performed_at < ?", userId, from.AddDate(0, 0, -1), to).
+		Find(&entries)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*ActivityEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.EndedAt().After(from) {
+			result = append(result, entry)
+		}
+	}
+
+	return result, nil
+}
+
+// Update 는 catalog의 운동으로 바꿔서 비게 된 이름도 저장한다.
+func (e *ActivityEntry) Update() error {
+	_, err := factory.DB().ID(e.Id).MustCols("name").Update(e)
+	return err
+}
+
+func (ActivityEntry) Delete(id int64) error {
+	_, err := factory.DB().ID(id).Delete(&ActivityEntry{})
+	return err
+}
+
+// SummarizeEnergy 는 [from, to) 사이의 섭취 합계와 날짜별 섭취 합계에 운동으로 소비한 칼로리를 더해서 날짜별 순 칼로리를 구한다.
+// 먹은 기록이나 운동 기록 중 하나만 있는 날도 포함된다.
+func SummarizeEnergy(userId int64, from, to time.Time, total IntakeSummary, days []*DailyIntakeSummary) (*EnergySummary, error) {
+	burnedDays := make([]*dailyBurned, 0)
+	err := factory.DB().Table("activity_entry").
+		Select("DATE(performed_at) AS date, COALESCE(SUM(calorie), 0) AS burned").
+		Where("user_id = ? AND performed_at >= ? AND performed_at < ?", userId, from, to).
+		And(notDeleted("activity_entry")).
+		GroupBy("date").
+		Find(&burnedDays)
+	if err != nil {
+		return nil, err
+	}
+
+	byDate := make(map[string]*DailyEnergy, len(days)+len(burnedDays))
+	for _, day := range days {
+		byDate[day.Date] = &DailyEnergy{Date: day.Date, Intake: day.Calorie}
+	}
+
+	summary := EnergySummary{Intake: total.Calorie, Days: make([]*DailyEnergy, 0, len(byDate))}
+	for _, day := range burnedDays {
+		energy, ok := byDate[day.Date]
+		if !ok {
+			energy = &DailyEnergy{Date: day.Date}
+			byDate[day.Date] = energy
+		}
+		energy.Burned += day.Burned
+		summary.Burned += day.Burned
+	}
+	summary.Net = summary.Intake - summary.Burned
+
+	for _, energy := range byDate {
+		energy.Net = energy.Intake - energy.Burned
+		summary.Days = append(summary.Days, energy)
+	}
+	sort.Slice(summary.Days, func(i, j int) bool {
+		return summary.Days[i].Date < summary.Days[j].Date
+	})
+
+	return &summary, nil
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"errors"
+	"github.com/kernelgarden/diet/units"
+)
+
+const (
+	// 에탄올 1g의 칼로리(kcal)
+	KcalPerGramAlcohol = 7.0
+	// 에탄올의 밀도(g/ml)
+	EthanolDensity = 0.789
+	MaxAbv         = 100.0
+)
+
+var ErrInvalidAbv = errors.New("abv must be between 0 and 100")
+
+// AlcoholGrams 는 도수가 abv(%)인 술 quantity unit 에 든 에탄올의 양(g)을 구한다.
+// 질량 단위로 입력한 경우 부피로 바꾸기 위해 술의 밀도(g/ml)가 필요하다.
+func AlcoholGrams(abv, quantity float64, unit units.Unit, density float64) (float64, error) {
+	if abv < 0 || abv > MaxAbv {
+		return 0, ErrInvalidAbv
+	}
+
+	milliliters, err := units.Convert(quantity, unit, units.Milliliter, density)
+	if err != nil {
+		return 0, err
+	}
+
+	return milliliters * abv / 100 * EthanolDensity, nil
+}
+
+// FillAlcohol 은 알코올 양을 모르는 nutrient에 food의 도수로 계산한 PerUnit 당 알코올 양을 채운다.
+// 도수가 0 이거나 알코올 양을 이미 알고 있으면 그대로 둔다.
+func (n *Nutrient) FillAlcohol(food Food) error {
+	if food.Abv == 0 || n.Alcohol != nil {
+		return nil
+	}
+
+	grams, err := AlcoholGrams(food.Abv, float64(n.PerUnit), n.Unit, food.Density)
+	if err != nil {
+		return err
+	}
+
+	alcohol := float32(grams)
+	n.Alcohol = &alcohol
+	return nil
+}
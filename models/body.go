@@ -0,0 +1,130 @@
+package models
+
+import (
+	"github.com/kernelgarden/diet/factory"
+	"time"
+)
+
+// BodyMeasurement 는 한 번 측정한 체성분이다. 측정하지 않은 항목은 0이다.
+type BodyMeasurement struct {
+	Id         int64     `json:"Id" xorm:"pk autoincr"`
+	UserId     int64     `json:"UserId" xorm:"index"`
+	Weight     float64   `json:"Weight"`
+	BodyFat    float64   `json:"BodyFat"`
+	Waist      float64   `json:"Waist"`
+	MuscleMass float64   `json:"MuscleMass"`
+	MeasuredAt time.Time `json:"MeasuredAt" xorm:"index"`
+	Source     string    `json:"Source" xorm:"varchar(16) index(source)"`   // 가져온 기기/앱, 직접 기록하면 비어 있음
+	SourceId   string    `json:"SourceId" xorm:"varchar(64) index(source)"` // 가져온 곳에서의 ID
+	CreatedAt  time.Time `json:"-" xorm:"created"`
+	DeletedAt  time.Time `json:"-" xorm:"deleted"`
+}
+
+func (b *BodyMeasurement) Create() (int64, error) {
+	return factory.DB().Insert(b)
+}
+
+func (BodyMeasurement) Get(id int64) (*BodyMeasurement, error) {
+	var b BodyMeasurement
+	if has, err := factory.DB().ID(id).Get(&b); err != nil {
+		return &b, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &b, nil
+}
+
+// GetByUser 는 [from, to) 사이에 측정한 user의 기록을 측정 시간 순서로 돌려준다.
+func (BodyMeasurement) GetByUser(userId int64, from, to time.Time) ([]*BodyMeasurement, error) {
+	measurements := make([]*BodyMeasurement, 0)
+
+	err := factory.DB().
+		Where("user_id = ? AND measured_at >= ? AND measured_at < ?", userId, from, to).
+		Asc("measured_at").
+		Find(&measurements)
+	if err != nil {
+		return nil, err
+	}
+
+	return measurements, nil
+}
+
+// GetBySource 는 외부에서 가져온 user의 기록을 출처와 ID로 찾는다.
+func (BodyMeasurement) GetBySource(userId int64, source, sourceId string) (*BodyMeasurement, error) {
+	var b BodyMeasurement
+	if has, err := factory.DB().Where("user_id = ? AND source = ? AND source_id = ?", userId, source, sourceId).Get(&b); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &b, nil
+}
+
+// GetNearest 는 at 앞뒤 window 안에 측정한 user의 기록 중 at 과 가장 가까운 기록을 돌려준다.
+func (BodyMeasurement) GetNearest(userId int64, at time.Time, window time.Duration) (*BodyMeasurement, error) {
+	measurements, err := BodyMeasurement{}.GetByUser(userId, at.Add(-window), at.Add(window+1))
+	if err != nil {
+		return nil, err
+	}
+
+	var nearest *BodyMeasurement
+	for _, measurement := range measurements {
+		if nearest == nil || absDuration(measurement.MeasuredAt.Sub(at)) < absDuration(nearest.MeasuredAt.Sub(at)) {
+			nearest = measurement
+		}
+	}
+
+	return nearest, nil
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+
+	return d
+}
+
+func (b *BodyMeasurement) Update() error {
+	_, err := factory.DB().ID(b.Id).Update(b)
+	return err
+}
+
+// LatestWeight 는 user가 가장 최근에 측정한 몸무게를 돌려준다. 기록이 없으면 0이다.
+func (BodyMeasurement) LatestWeight(userId int64) (float64, error) {
+	var b BodyMeasurement
+	if _, err := factory.DB().Where("user_id = ? AND weight > 0", userId).Desc("measured_at").Get(&b); err != nil {
+		return 0, err
+	}
+
+	return b.Weight, nil
+}
+
+// WeightAt 은 user가 at 과 가장 가까운 때에 측정한 몸무게를 돌려준다. 기록이 없으면 0이다.
+func (BodyMeasurement) WeightAt(userId int64, at time.Time) (float64, error) {
+	var before, after BodyMeasurement
+	hasBefore, err := factory.DB().Where("user_id = ? AND weight > 0 AND measured_at <= ?", userId, at).
+		Desc("measured_at").Get(&before)
+	if err != nil {
+		return 0, err
+	}
+
+	hasAfter, err := factory.DB().Where("user_id = ? AND weight > 0 AND measured_at > ?", userId, at).
+		Asc("measured_at").Get(&after)
+	if err != nil {
+		return 0, err
+	}
+
+	if hasBefore && (!hasAfter || at.Sub(before.MeasuredAt) <= after.MeasuredAt.Sub(at)) {
+		return before.Weight, nil
+	}
+
+	return after.Weight, nil
+}
+
+func (BodyMeasurement) Delete(id int64) error {
+	_, err := factory.DB().ID(id).Delete(&BodyMeasurement{})
+	return err
+}
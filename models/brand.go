@@ -50,6 +50,17 @@ func (Brand) Delete(id int64) error {
 	return err
 }
 
+func (Brand) GetByName(name string) (*Brand, error) {
+	var b Brand
+	if has, err := factory.DB().Where("name = ?", name).Get(&b); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &b, nil
+}
+
 func (Brand) Default() (*Brand, error) {
 	var b Brand
 	if _, err := factory.DB().ID(0).Get(&b); err != nil {
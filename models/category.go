@@ -57,6 +57,17 @@ func (Category) Delete(id int64) error {
 	return err
 }
 
+func (Category) GetByName(name string) (*Category, error) {
+	var c Category
+	if has, err := factory.DB().Where("name = ?", name).Get(&c); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &c, nil
+}
+
 func (Category) Default() (*Category, error) {
 	var c Category
 	_, err := factory.DB().ID(0).Get(&c)
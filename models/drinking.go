@@ -0,0 +1,149 @@
+package models
+
+import (
+	"github.com/go-xorm/xorm"
+	"github.com/kernelgarden/diet/factory"
+	"time"
+)
+
+// DrinkingSession 은 하루 저녁 술자리처럼 함께 먹은 술과 안주의 식사 기록을 묶는다.
+// 식사 기록은 MealEntry.SessionId 로 술자리에 들어간다.
+type DrinkingSession struct {
+	Id        int64     `json:"Id" xorm:"pk autoincr"`
+	UserId    int64     `json:"UserId" xorm:"index"`
+	Name      string    `json:"Name" xorm:"varchar(64)"`
+	StartedAt time.Time `json:"StartedAt" xorm:"index"`
+	EndedAt   time.Time `json:"EndedAt"` // 아직 끝나지 않았으면 zero
+	CreatedAt time.Time `json:"-" xorm:"created"`
+	DeletedAt time.Time `json:"-" xorm:"deleted"`
+}
+
+// DrinkingSessionJSON 의 Total 에는 술과 안주가 모두 들어가고, 그 중 술의 알코올 칼로리는 Total.AlcoholCalorie 이다.
+type DrinkingSessionJSON struct {
+	Session     DrinkingSession `json:"Session"`
+	Entries     []MealEntryJSON `json:"Entries"`
+	Total       Intake          `json:"Total"`
+	DrinkCount  int             `json:"DrinkCount"`  // 알코올이 든 기록 수
+	SnackCount  int             `json:"SnackCount"`  // 안주 기록 수
+	SnackIntake Intake          `json:"SnackIntake"` // 안주로 먹은 양
+}
+
+// AlcoholSummary 는 기간 동안 마신 알코올이 칼로리에 얼마나 영향을 줬는지 나타낸다.
+type AlcoholSummary struct {
+	Alcohol        float64 `json:"Alcohol"`        // g
+	AlcoholCalorie float64 `json:"AlcoholCalorie"` // kcal
+	CalorieRatio   float64 `json:"CalorieRatio"`   // 전체 칼로리 중 알코올 칼로리의 비율
+	DrinkingDays   int     `json:"DrinkingDays"`   // 알코올을 마신 날 수
+	SessionCount   int64   `json:"SessionCount"`   // 시작한 술자리 수
+}
+
+func (d DrinkingSession) ToJSON() (*DrinkingSessionJSON, error) {
+	entries, err := d.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	result := DrinkingSessionJSON{Session: d, Entries: make([]MealEntryJSON, 0, len(entries))}
+	for _, entry := range entries {
+		entryJSON, err := entry.ToJSON()
+		if err != nil {
+			return nil, err
+		} else if entryJSON == nil {
+			// 삭제된 food를 먹은 기록은 건너뛴다.
+			continue
+		}
+
+		result.Entries = append(result.Entries, *entryJSON)
+		result.Total = result.Total.Add(entryJSON.Intake)
+		if entryJSON.Intake.Alcohol > 0 {
+			result.DrinkCount++
+		} else {
+			result.SnackCount++
+			result.SnackIntake = result.SnackIntake.Add(entryJSON.Intake)
+		}
+	}
+
+	return &result, nil
+}
+
+func (d *DrinkingSession) Create() (int64, error) {
+	return factory.DB().Insert(d)
+}
+
+func (DrinkingSession) Get(id int64) (*DrinkingSession, error) {
+	var d DrinkingSession
+	if has, err := factory.DB().ID(id).Get(&d); err != nil {
+		return &d, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &d, nil
+}
+
+// GetByUser 는 [from, to) 사이에 시작한 user의 술자리를 최근 순서로 돌려준다.
+func (DrinkingSession) GetByUser(userId int64, from, to time.Time, offset, limit int) ([]*DrinkingSession, error) {
+	sessions := make([]*DrinkingSession, 0)
+
+	err := factory.DB().
+		Where("user_id = ? AND started_at >= ? AND started_at < ?", userId, from, to).
+		Desc("started_at").
+		Limit(limit, offset).
+		Find(&sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (d *DrinkingSession) Update() error {
+	_, err := factory.DB().ID(d.Id).Update(d)
+	return err
+}
+
+// Entries 는 술자리에 들어간 식사 기록을 먹은 시간 순서로 돌려준다.
+func (d DrinkingSession) Entries() ([]*MealEntry, error) {
+	entries := make([]*MealEntry, 0)
+	if err := factory.DB().Where("session_id = ?", d.Id).Asc("eaten_at").Find(&entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Delete 는 술자리를 지운다. 술자리에 들어간 식사 기록은 지우지 않고 술자리에서만 뺀다.
+func (d DrinkingSession) Delete() error {
+	return factory.Transaction(func(session *xorm.Session) error {
+		if _, err := session.Where("session_id = ?", d.Id).MustCols("session_id").Update(&MealEntry{}); err != nil {
+			return err
+		}
+
+		_, err := session.ID(d.Id).Delete(&DrinkingSession{})
+		return err
+	})
+}
+
+// SummarizeAlcohol 은 [from, to) 사이의 합계와 날짜별 합계로 알코올 요약을 만든다.
+func SummarizeAlcohol(userId int64, from, to time.Time, total IntakeSummary, days []*DailyIntakeSummary) (*AlcoholSummary, error) {
+	summary := AlcoholSummary{Alcohol: total.Alcohol, AlcoholCalorie: total.AlcoholCalorie}
+	if total.Calorie > 0 {
+		summary.CalorieRatio = total.AlcoholCalorie / total.Calorie
+	}
+
+	for _, day := range days {
+		if day.Alcohol > 0 {
+			summary.DrinkingDays++
+		}
+	}
+
+	count, err := factory.DB().
+		Where("user_id = ? AND started_at >= ? AND started_at < ?", userId, from, to).
+		Count(&DrinkingSession{})
+	if err != nil {
+		return nil, err
+	}
+	summary.SessionCount = count
+
+	return &summary, nil
+}
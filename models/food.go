@@ -12,15 +12,23 @@ type Food struct {
 	BrandId    int64     `json:"BrandId" xorm:"index"`
 	Name       string    `json:"Name" xorm:"varchar(64)"`
 	Weight     float64   `json:"-"`
+	Density    float64   `json:"Density"`                                   // g/ml, 모르면 0
+	Abv        float64   `json:"Abv"`                                       // 알코올 도수(%), 술이 아니면 0
+	Source     string    `json:"Source" xorm:"varchar(16) index(source)"`   // 가져온 외부 DB, 직접 등록하면 비어 있음
+	SourceId   string    `json:"SourceId" xorm:"varchar(64) index(source)"` // 외부 DB에서의 ID
+	Barcode    string    `json:"Barcode" xorm:"varchar(13) index"`          // 13자리로 맞춘 상품 바코드
 	CreatedAt  time.Time `json:"-" xorm:"created"`
 	DeletedAt  time.Time `json:"-" xorm:"deleted"`
 }
 
 type FoodJSON struct {
-	Food     Food     `json:"Food"`
-	Nutrient Nutrient `json:"Nutrient"`
-	Brand    Brand    `json:"Brand"`
-	Category Category `json:"Category"`
+	Food           Food             `json:"Food"`
+	Nutrient       Nutrient         `json:"Nutrient"`
+	Micronutrients []*Micronutrient `json:"Micronutrients"`
+	ServingSizes   []*ServingSize   `json:"ServingSizes"`
+	Brand          Brand            `json:"Brand"`
+	Category       Category         `json:"Category"`
+	Recipe         *RecipeJSON      `json:"Recipe,omitempty"` // recipe로 만든 food이면 재료 구성
 }
 
 func (f Food) ToJSON() (*FoodJSON, error) {
@@ -31,6 +39,16 @@ func (f Food) ToJSON() (*FoodJSON, error) {
 		return nil, nil
 	}
 
+	micronutrients, err := Micronutrient{}.GetByNutrientId(nutrient.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	servingSizes, err := ServingSize{}.GetByFoodId(f.Id)
+	if err != nil {
+		return nil, err
+	}
+
 	// 브랜드는 없는 경우도 있을 수 있다.
 	var brand Brand
 	if _, err := factory.DB().ID(f.BrandId).Get(&brand); err != nil {
@@ -44,7 +62,17 @@ func (f Food) ToJSON() (*FoodJSON, error) {
 		return nil, nil
 	}
 
-	return &FoodJSON{Food: f, Nutrient: nutrient, Brand: brand, Category: category}, nil
+	var recipeJSON *RecipeJSON
+	if recipe, err := (Recipe{}).GetByFoodId(f.Id); err != nil {
+		return nil, err
+	} else if recipe != nil {
+		if recipeJSON, err = recipe.ToJSON(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &FoodJSON{Food: f, Nutrient: nutrient, Micronutrients: micronutrients, ServingSizes: servingSizes, Brand: brand, Category: category,
+		Recipe: recipeJSON}, nil
 }
 
 func (FoodJSON) NewFoodJSON(food Food, nutrient Nutrient, brand Brand, category Category) FoodJSON {
@@ -74,11 +102,67 @@ func (Food) Get(id int64) (*Food, error) {
 	return &f, nil
 }
 
+// GetByNameAndBrand 는 이름과 brand가 같은 food를 찾는다. brand가 없는 food는 brandId 0 으로 찾는다.
+// recipe의 food는 재료로 계산한 것이라 import 로 덮어쓰지 않도록 찾지 않는다.
+func (Food) GetByNameAndBrand(name string, brandId int64) (*Food, error) {
+	var f Food
+	if has, err := factory.DB().Where("name = ? AND brand_id = ?", name, brandId).
+		And("NOT EXISTS (SELECT 1 FROM recipe WHERE recipe.food_id = food.id AND " + notDeleted("recipe") + ")").
+		Get(&f); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &f, nil
+}
+
+// GetBySource 는 외부 DB에서 가져온 food를 출처와 그 DB에서의 ID로 찾는다.
+func (Food) GetBySource(source, sourceId string) (*Food, error) {
+	var f Food
+	if has, err := factory.DB().Where("source = ? AND source_id = ?", source, sourceId).Get(&f); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &f, nil
+}
+
+// GetByBarcode 는 gtin.Normalize 로 13자리로 맞춘 바코드로 food를 찾는다.
+// 바코드가 없는 food가 많아서 unique index 대신 등록하고 가져올 때 겹치지 않게 한다.
+// 그 전에 겹쳐서 등록된 바코드는 먼저 등록한 food를 돌려준다.
+func (Food) GetByBarcode(barcode string) (*Food, error) {
+	var f Food
+	if has, err := factory.DB().Where("barcode = ?", barcode).Asc("id").Get(&f); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &f, nil
+}
+
+// HasQuantities 는 food nutrient의 Unit 기준으로 저장한 양(식사 기록, 레시피 재료, 식단 계획)이 있는지 확인한다.
+// 있으면 Unit 을 바꿀 때 저장한 양이 다른 단위로 읽힌다.
+func (f Food) HasQuantities() (bool, error) {
+	for _, bean := range []interface{}{&MealEntry{}, &RecipeIngredient{}, &PlannedMeal{}} {
+		count, err := factory.DB().Where("food_id = ?", f.Id).Count(bean)
+		if err != nil {
+			return false, err
+		} else if count > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func (Food) GetAll(offset, limit int) ([]*Food, error) {
 	// TODO: Increase performance via goroutine
 	foods := make([]*Food, 0)
 
-	if err := factory.DB().Limit(limit, offset).Find(&foods); err != nil {
+	if err := factory.DB().Asc("id").Limit(limit, offset).Find(&foods); err != nil {
 		return nil, err
 	}
 
@@ -95,6 +179,14 @@ func (f *Food) UpdateWithSes(session *xorm.Session) error {
 	return err
 }
 
+// ReplaceWithSes 는 UpdateWithSes 와 달리 0 이거나 비어 있는 값도 그대로 저장한다. 바코드는 있을 때만 바꾼다.
+func (f *Food) ReplaceWithSes(session *xorm.Session) error {
+	_, err := session.ID(f.Id).
+		MustCols("category_id", "brand_id", "name", "weight", "density", "abv", "source", "source_id").
+		Update(f)
+	return err
+}
+
 func (Food) Delete(id int64) error {
 	_, err := factory.DB().ID(id).Delete(&Food{})
 	return err
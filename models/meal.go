@@ -0,0 +1,144 @@
+package models
+
+import (
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/units"
+	"time"
+)
+
+// 끼니 구분
+const (
+	MealSlotBreakfast int32 = iota + 1
+	MealSlotLunch
+	MealSlotDinner
+	MealSlotSnack
+)
+
+func IsValidMealSlot(slot int32) bool {
+	return slot >= MealSlotBreakfast && slot <= MealSlotSnack
+}
+
+// MealEntry 의 Quantity 는 집계를 위해 항상 food nutrient의 Unit 기준으로 저장하고,
+// user가 입력한 양과 단위는 LoggedQuantity, LoggedUnit 에 그대로 남긴다.
+// serving size로 입력한 경우 LoggedUnit 은 Serving 이고 LoggedQuantity 는 인분 수이다.
+type MealEntry struct {
+	Id             int64      `json:"Id" xorm:"pk autoincr"`
+	UserId         int64      `json:"UserId" xorm:"index"`
+	FoodId         int64      `json:"FoodId" xorm:"index"`
+	Quantity       float64    `json:"Quantity"`
+	LoggedQuantity float64    `json:"LoggedQuantity"`
+	LoggedUnit     units.Unit `json:"LoggedUnit"`
+	ServingSizeId  int64      `json:"ServingSizeId"`
+	Slot           int32      `json:"Slot"`
+	SessionId      int64      `json:"SessionId" xorm:"index"`                    // 술자리에서 먹었으면 DrinkingSession ID
+	Source         string     `json:"Source" xorm:"varchar(16) index(source)"`   // 가져온 앱, 직접 기록하면 비어 있음
+	SourceId       string     `json:"SourceId" xorm:"varchar(64) index(source)"` // 가져온 곳에서의 ID
+	EatenAt        time.Time  `json:"EatenAt" xorm:"index"`
+	CreatedAt      time.Time  `json:"-" xorm:"created"`
+	DeletedAt      time.Time  `json:"-" xorm:"deleted"`
+}
+
+type MealEntryJSON struct {
+	MealEntry MealEntry `json:"MealEntry"`
+	Food      FoodJSON  `json:"Food"`
+	Intake    Intake    `json:"Intake"`
+}
+
+func (m MealEntry) ToJSON() (*MealEntryJSON, error) {
+	food, err := Food{}.Get(m.FoodId)
+	if err != nil {
+		return nil, err
+	} else if food == nil {
+		return nil, nil
+	}
+
+	foodJSON, err := food.ToJSON()
+	if err != nil {
+		return nil, err
+	} else if foodJSON == nil {
+		return nil, nil
+	}
+
+	return &MealEntryJSON{MealEntry: m, Food: *foodJSON, Intake: foodJSON.Nutrient.Intake(m.Quantity)}, nil
+}
+
+// SetQuantity 는 unit 단위로 입력한 양을 food nutrient의 Unit 기준으로 바꿔서 저장한다.
+// unit 이 Unknown 이면 nutrient의 Unit 으로 입력한 것으로 본다.
+// servingSizeId 가 있으면 unit 은 무시하고 quantity 를 그 serving size의 개수로 본다.
+func (m *MealEntry) SetQuantity(food Food, quantity float64, unit units.Unit, servingSizeId int64) error {
+	nutrient, err := Nutrient{}.GetByFoodId(food.Id)
+	if err != nil {
+		return err
+	} else if nutrient == nil {
+		return ErrNutrientNotFound
+	}
+
+	converted, unit, err := food.ToNutrientQuantity(*nutrient, quantity, unit, servingSizeId)
+	if err != nil {
+		return err
+	}
+
+	m.FoodId = food.Id
+	m.Quantity = converted
+	m.LoggedQuantity = quantity
+	m.LoggedUnit = unit
+	m.ServingSizeId = servingSizeId
+
+	return nil
+}
+
+func (m *MealEntry) Create() (int64, error) {
+	return factory.DB().Insert(m)
+}
+
+func (MealEntry) Get(id int64) (*MealEntry, error) {
+	var m MealEntry
+	if has, err := factory.DB().ID(id).Get(&m); err != nil {
+		return &m, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &m, nil
+}
+
+// GetByUser 는 [from, to) 사이에 먹은 user의 식사 기록을 먹은 시간 순서로 돌려준다.
+func (MealEntry) GetByUser(userId int64, from, to time.Time, offset, limit int) ([]*MealEntry, error) {
+	entries := make([]*MealEntry, 0)
+
+	err := factory.DB().
+		Where("user_id = ? AND eaten_at >= ? AND eaten_at < ?", userId, from, to).
+		Asc("eaten_at").
+		Limit(limit, offset).
+		Find(&entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetBySource 는 외부에서 가져온 user의 식사 기록을 출처와 ID로 찾는다.
+func (MealEntry) GetBySource(userId int64, source, sourceId string) (*MealEntry, error) {
+	var m MealEntry
+	if has, err := factory.DB().Where("user_id = ? AND source = ? AND source_id = ?", userId, source, sourceId).Get(&m); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &m, nil
+}
+
+// Update 는 serving size나 술자리에서 빠지면 0 이 되는 값도 저장한다.
+func (m *MealEntry) Update() error {
+	_, err := factory.DB().ID(m.Id).
+		MustCols("food_id", "quantity", "logged_quantity", "logged_unit", "serving_size_id", "session_id").
+		Update(m)
+	return err
+}
+
+func (MealEntry) Delete(id int64) error {
+	_, err := factory.DB().ID(id).Delete(&MealEntry{})
+	return err
+}
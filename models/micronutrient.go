@@ -0,0 +1,100 @@
+package models
+
+import (
+	"github.com/go-xorm/xorm"
+	"github.com/kernelgarden/diet/factory"
+	"sort"
+	"time"
+)
+
+// 등록할 수 있는 비타민/무기질과 그 단위. 새 항목은 여기에 추가하면 된다.
+var MicronutrientUnits = map[string]string{
+	"vitamin_a":   "µg",
+	"vitamin_b1":  "mg",
+	"vitamin_b2":  "mg",
+	"vitamin_b6":  "mg",
+	"vitamin_b12": "µg",
+	"vitamin_c":   "mg",
+	"vitamin_d":   "µg",
+	"vitamin_e":   "mg",
+	"vitamin_k":   "µg",
+	"niacin":      "mg",
+	"folate":      "µg",
+	"calcium":     "mg",
+	"iron":        "mg",
+	"magnesium":   "mg",
+	"phosphorus":  "mg",
+	"potassium":   "mg",
+	"zinc":        "mg",
+}
+
+// MicronutrientCodes 는 등록할 수 있는 비타민/무기질 코드를 이름 순서로 돌려준다.
+func MicronutrientCodes() []string {
+	codes := make([]string, 0, len(MicronutrientUnits))
+	for code := range MicronutrientUnits {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+func IsValidMicronutrient(code string) bool {
+	_, ok := MicronutrientUnits[code]
+	return ok
+}
+
+// Micronutrient 는 nutrient의 PerUnit 당 비타민/무기질 함량이다.
+type Micronutrient struct {
+	Id         int64     `json:"-" xorm:"pk autoincr"`
+	NutrientId int64     `json:"-" xorm:"index"`
+	Code       string    `json:"Code" xorm:"varchar(32)"`
+	Amount     float32   `json:"Amount"`
+	Unit       string    `json:"Unit" xorm:"varchar(8)"`
+	CreatedAt  time.Time `json:"-" xorm:"created"`
+	DeletedAt  time.Time `json:"-" xorm:"deleted"`
+}
+
+func (Micronutrient) GetByNutrientId(nutrientId int64) ([]*Micronutrient, error) {
+	micronutrients := make([]*Micronutrient, 0)
+	if err := factory.DB().Where("nutrient_id = ?", nutrientId).Asc("code").Find(&micronutrients); err != nil {
+		return nil, err
+	}
+
+	return micronutrients, nil
+}
+
+// ReplaceMicronutrientsWithSes 는 nutrient의 비타민/무기질을 모두 amounts 로 바꾼다. amounts 에 없는 항목은 지운다.
+func ReplaceMicronutrientsWithSes(session *xorm.Session, nutrientId int64, amounts map[string]float32) error {
+	if _, err := session.Where("nutrient_id = ?", nutrientId).Delete(&Micronutrient{}); err != nil {
+		return err
+	}
+
+	return SetMicronutrientsWithSes(session, nutrientId, amounts)
+}
+
+// DeleteMicronutrientsWithSes 는 codes 에 있는 항목을 지워서 모르는 값으로 되돌린다.
+func DeleteMicronutrientsWithSes(session *xorm.Session, nutrientId int64, codes []string) error {
+	if len(codes) == 0 {
+		return nil
+	}
+
+	_, err := session.Where("nutrient_id = ?", nutrientId).In("code", codes).Delete(&Micronutrient{})
+	return err
+}
+
+// SetMicronutrientsWithSes 는 amounts 에 있는 항목만 새 값으로 바꾸고 나머지는 그대로 둔다.
+func SetMicronutrientsWithSes(session *xorm.Session, nutrientId int64, amounts map[string]float32) error {
+	for code, amount := range amounts {
+		if _, err := session.Where("nutrient_id = ? AND code = ?", nutrientId, code).Delete(&Micronutrient{}); err != nil {
+			return err
+		}
+
+		micronutrient := Micronutrient{NutrientId: nutrientId, Code: code, Amount: amount, Unit: MicronutrientUnits[code]}
+		if _, err := session.Insert(&micronutrient); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
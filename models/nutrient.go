@@ -3,6 +3,7 @@ package models
 import (
 	"github.com/go-xorm/xorm"
 	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/units"
 	"time"
 )
 
@@ -16,11 +17,91 @@ type Nutrient struct {
 	TransFat       float32   `json:"TransFat"`
 	PerUnit 	   int32     `json:"PerUnit"`
 	Calorie        int64     `json:"Calorie"`
-	Unit		   int32	 `json:"Unit"`
+	Unit		   units.Unit	 `json:"Unit"`
+	// 아래 항목은 값을 모르면 0이 아닌 null 이다. 기존 row도 null 로 추가된다.
+	Sodium         *float32  `json:"Sodium" xorm:"null"`       // mg
+	Sugars         *float32  `json:"Sugars" xorm:"null"`       // g
+	DietaryFiber   *float32  `json:"DietaryFiber" xorm:"null"` // g
+	Cholesterol    *float32  `json:"Cholesterol" xorm:"null"`  // mg
+	Alcohol        *float32  `json:"Alcohol" xorm:"null"`      // g, 에탄올
 	CreatedAt      time.Time `json:"-" xorm:"created"`
 	DeletedAt      time.Time `json:"-" xorm:"deleted"`
 }
 
+// Intake 는 nutrient를 실제로 섭취한 양만큼 환산한 값이다.
+type Intake struct {
+	Calorie        float64 `json:"Calorie"`
+	Carbohydrate   float64 `json:"Carbohydrate"`
+	Protein        float64 `json:"Protein"`
+	SaturatedFat   float64 `json:"SaturatedFat"`
+	UnSaturatedFat float64 `json:"UnSaturatedFat"`
+	TransFat       float64 `json:"TransFat"`
+	Sodium         float64 `json:"Sodium"`
+	Sugars         float64 `json:"Sugars"`
+	DietaryFiber   float64 `json:"DietaryFiber"`
+	Cholesterol    float64 `json:"Cholesterol"`
+	// 알코올 칼로리는 Calorie 에 포함되어 있고, 따로 보기 위해 한 번 더 나타낸다.
+	Alcohol        float64 `json:"Alcohol"`
+	AlcoholCalorie float64 `json:"AlcoholCalorie"`
+}
+
+func (i Intake) Add(other Intake) Intake {
+	return Intake{
+		Calorie:        i.Calorie + other.Calorie,
+		Carbohydrate:   i.Carbohydrate + other.Carbohydrate,
+		Protein:        i.Protein + other.Protein,
+		SaturatedFat:   i.SaturatedFat + other.SaturatedFat,
+		UnSaturatedFat: i.UnSaturatedFat + other.UnSaturatedFat,
+		TransFat:       i.TransFat + other.TransFat,
+		Sodium:         i.Sodium + other.Sodium,
+		Sugars:         i.Sugars + other.Sugars,
+		DietaryFiber:   i.DietaryFiber + other.DietaryFiber,
+		Cholesterol:    i.Cholesterol + other.Cholesterol,
+		Alcohol:        i.Alcohol + other.Alcohol,
+		AlcoholCalorie: i.AlcoholCalorie + other.AlcoholCalorie,
+	}
+}
+
+// 값을 모르는 영양소는 합계에서 0으로 취급한다.
+func optionalValue(value *float32) float64 {
+	if value == nil {
+		return 0
+	}
+
+	return float64(*value)
+}
+
+// Intake 는 nutrient의 Unit 기준 quantity 만큼 먹었을 때의 영양소를 계산한다.
+func (n Nutrient) Intake(quantity float64) Intake {
+	if n.PerUnit == 0 {
+		return Intake{}
+	}
+
+	ratio := quantity / float64(n.PerUnit)
+	alcohol := optionalValue(n.Alcohol) * ratio
+
+	return Intake{
+		Calorie:        float64(n.Calorie) * ratio,
+		Carbohydrate:   float64(n.Carbohydrate) * ratio,
+		Protein:        float64(n.Protein) * ratio,
+		SaturatedFat:   float64(n.SaturatedFat) * ratio,
+		UnSaturatedFat: float64(n.UnSaturatedFat) * ratio,
+		TransFat:       float64(n.TransFat) * ratio,
+		Sodium:         optionalValue(n.Sodium) * ratio,
+		Sugars:         optionalValue(n.Sugars) * ratio,
+		DietaryFiber:   optionalValue(n.DietaryFiber) * ratio,
+		Cholesterol:    optionalValue(n.Cholesterol) * ratio,
+		Alcohol:        alcohol,
+		AlcoholCalorie: alcohol * KcalPerGramAlcohol,
+	}
+}
+
+// ToUnitQuantity 는 unit 단위의 quantity 를 nutrient의 Unit 기준 양으로 바꾼다.
+// density 는 food의 밀도(g/ml)로, 질량과 부피 사이를 바꿀 때만 쓴다.
+func (n Nutrient) ToUnitQuantity(quantity float64, unit units.Unit, density float64) (float64, error) {
+	return units.Convert(quantity, unit, n.Unit, density)
+}
+
 func (n *Nutrient) Create() (int64, error) {
 	return factory.DB().Insert(n)
 }
@@ -40,6 +121,29 @@ func (Nutrient) Get(id int64) (*Nutrient, error) {
 	return &n, nil
 }
 
+func (Nutrient) GetByFoodId(foodId int64) (*Nutrient, error) {
+	var n Nutrient
+	if has, err := factory.DB().Where("food_id = ?", foodId).Get(&n); err != nil {
+		return &n, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &n, nil
+}
+
+// GetByFoodIdWithSes 는 transaction 안에서 방금 만들거나 바꾼 food의 nutrient도 찾는다.
+func (Nutrient) GetByFoodIdWithSes(session *xorm.Session, foodId int64) (*Nutrient, error) {
+	var n Nutrient
+	if has, err := session.Where("food_id = ?", foodId).Get(&n); err != nil {
+		return &n, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &n, nil
+}
+
 func (Nutrient) GetAll(offset, limit int) ([]*Nutrient, error) {
 	// TODO: Increase performance via goroutine
 	nutrients := make([]*Nutrient, 0)
@@ -61,6 +165,15 @@ func (n *Nutrient) UpdateWithSes(session *xorm.Session) error {
 	return err
 }
 
+// ReplaceWithSes 는 UpdateWithSes 와 달리 0 이나 null 인 값도 그대로 저장한다.
+func (n *Nutrient) ReplaceWithSes(session *xorm.Session) error {
+	_, err := session.ID(n.Id).
+		MustCols("carbohydrate", "protein", "saturated_fat", "un_saturated_fat", "trans_fat", "per_unit", "calorie", "unit",
+			"sodium", "sugars", "dietary_fiber", "cholesterol", "alcohol").
+		Update(n)
+	return err
+}
+
 func (Nutrient) Delete(id int64) error {
 	_, err := factory.DB().ID(id).Delete(&Nutrient{})
 	return err
@@ -0,0 +1,301 @@
+package models
+
+import (
+	"github.com/go-xorm/xorm"
+	"github.com/kernelgarden/diet/factory"
+	"time"
+)
+
+// MacroTarget 은 하루 동안의 칼로리와 탄단지 목표량이다. 목표와 실제의 차이를 나타낼 때도 쓴다.
+type MacroTarget struct {
+	Calorie      float64 `json:"Calorie"`
+	Carbohydrate float64 `json:"Carbohydrate"`
+	Protein      float64 `json:"Protein"`
+	Fat          float64 `json:"Fat"`
+}
+
+func (t MacroTarget) Sub(other MacroTarget) MacroTarget {
+	return MacroTarget{
+		Calorie:      t.Calorie - other.Calorie,
+		Carbohydrate: t.Carbohydrate - other.Carbohydrate,
+		Protein:      t.Protein - other.Protein,
+		Fat:          t.Fat - other.Fat,
+	}
+}
+
+func (t MacroTarget) Scale(factor float64) MacroTarget {
+	return MacroTarget{
+		Calorie:      t.Calorie * factor,
+		Carbohydrate: t.Carbohydrate * factor,
+		Protein:      t.Protein * factor,
+		Fat:          t.Fat * factor,
+	}
+}
+
+func (i Intake) Macro() MacroTarget {
+	return MacroTarget{
+		Calorie:      i.Calorie,
+		Carbohydrate: i.Carbohydrate,
+		Protein:      i.Protein,
+		Fat:          i.SaturatedFat + i.UnSaturatedFat + i.TransFat,
+	}
+}
+
+// Plan 은 StartDate 부터 Days 일 동안의 식단 계획이다.
+// 템플릿은 시작 날짜 없이 저장해 두었다가 복사해서 쓴다.
+type Plan struct {
+	Id         int64     `json:"Id" xorm:"pk autoincr"`
+	UserId     int64     `json:"UserId" xorm:"index"`
+	Name       string    `json:"Name" xorm:"varchar(64)"`
+	StartDate  time.Time `json:"StartDate"`
+	Days       int32     `json:"Days"`
+	IsTemplate bool      `json:"IsTemplate"`
+	CreatedAt  time.Time `json:"-" xorm:"created"`
+	DeletedAt  time.Time `json:"-" xorm:"deleted"`
+}
+
+type PlanDay struct {
+	Id          int64 `json:"Id" xorm:"pk autoincr"`
+	PlanId      int64 `json:"PlanId" xorm:"index"`
+	DayIndex    int32 `json:"DayIndex"`
+	MacroTarget `xorm:"extends"`
+	CreatedAt   time.Time `json:"-" xorm:"created"`
+	DeletedAt   time.Time `json:"-" xorm:"deleted"`
+}
+
+type PlannedMeal struct {
+	Id        int64     `json:"Id" xorm:"pk autoincr"`
+	PlanId    int64     `json:"PlanId" xorm:"index"`
+	DayIndex  int32     `json:"DayIndex"`
+	Slot      int32     `json:"Slot"`
+	FoodId    int64     `json:"FoodId"`
+	Quantity  float64   `json:"Quantity"`
+	CreatedAt time.Time `json:"-" xorm:"created"`
+	DeletedAt time.Time `json:"-" xorm:"deleted"`
+}
+
+type PlanDayJSON struct {
+	PlanDay PlanDay        `json:"PlanDay"`
+	Meals   []*PlannedMeal `json:"Meals"`
+	Planned Intake         `json:"Planned"`
+}
+
+type PlanJSON struct {
+	Plan Plan          `json:"Plan"`
+	Days []PlanDayJSON `json:"Days"`
+}
+
+func (p Plan) ToJSON() (*PlanJSON, error) {
+	days, err := p.PlanDays()
+	if err != nil {
+		return nil, err
+	}
+
+	meals, err := p.Meals()
+	if err != nil {
+		return nil, err
+	}
+
+	return newPlanJSON(p, days, meals)
+}
+
+// PreviewJSON 은 저장하지 않은 plan을 targets 와 meals 로 저장한 것처럼 보여준다.
+func (p Plan) PreviewJSON(targets []MacroTarget, meals []PlannedMeal) (*PlanJSON, error) {
+	days := make([]*PlanDay, len(targets))
+	for idx, target := range targets {
+		days[idx] = &PlanDay{PlanId: p.Id, DayIndex: int32(idx), MacroTarget: target}
+	}
+
+	mealList := make([]*PlannedMeal, len(meals))
+	for idx := range meals {
+		mealList[idx] = &meals[idx]
+	}
+
+	return newPlanJSON(p, days, mealList)
+}
+
+func newPlanJSON(p Plan, days []*PlanDay, meals []*PlannedMeal) (*PlanJSON, error) {
+	dayJSONList := make([]PlanDayJSON, len(days))
+	for idx, day := range days {
+		dayJSONList[idx] = PlanDayJSON{PlanDay: *day, Meals: make([]*PlannedMeal, 0)}
+	}
+
+	for _, meal := range meals {
+		if meal.DayIndex < 0 || int(meal.DayIndex) >= len(dayJSONList) {
+			continue
+		}
+
+		nutrient, err := Nutrient{}.GetByFoodId(meal.FoodId)
+		if err != nil {
+			return nil, err
+		}
+
+		dayJSON := &dayJSONList[meal.DayIndex]
+		dayJSON.Meals = append(dayJSON.Meals, meal)
+		if nutrient != nil {
+			dayJSON.Planned = dayJSON.Planned.Add(nutrient.Intake(meal.Quantity))
+		}
+	}
+
+	return &PlanJSON{Plan: p, Days: dayJSONList}, nil
+}
+
+// CreatePlan 은 plan과 날짜별 목표, 계획한 식단을 함께 저장한다. targets 의 순서가 DayIndex 이다.
+func CreatePlan(plan *Plan, targets []MacroTarget, meals []PlannedMeal) error {
+	return factory.Transaction(func(session *xorm.Session) error {
+		if _, err := plan.CreateWithSes(session); err != nil {
+			return err
+		}
+
+		for idx, target := range targets {
+			newDay := PlanDay{PlanId: plan.Id, DayIndex: int32(idx), MacroTarget: target}
+			if _, err := newDay.CreateWithSes(session); err != nil {
+				return err
+			}
+		}
+
+		for idx := range meals {
+			meals[idx].Id = 0
+			meals[idx].PlanId = plan.Id
+			if _, err := meals[idx].CreateWithSes(session); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (p *Plan) CreateWithSes(session *xorm.Session) (int64, error) {
+	return session.Insert(p)
+}
+
+func (d *PlanDay) CreateWithSes(session *xorm.Session) (int64, error) {
+	return session.Insert(d)
+}
+
+func (m *PlannedMeal) Create() (int64, error) {
+	return factory.DB().Insert(m)
+}
+
+func (m *PlannedMeal) CreateWithSes(session *xorm.Session) (int64, error) {
+	return session.Insert(m)
+}
+
+func (Plan) Get(id int64) (*Plan, error) {
+	var p Plan
+	if has, err := factory.DB().ID(id).Get(&p); err != nil {
+		return &p, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &p, nil
+}
+
+func (Plan) GetByUser(userId int64, isTemplate bool, offset, limit int) ([]*Plan, error) {
+	plans := make([]*Plan, 0)
+
+	err := factory.DB().
+		Where("user_id = ? AND is_template = ?", userId, isTemplate).
+		Desc("id").
+		Limit(limit, offset).
+		Find(&plans)
+	if err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+func (p Plan) PlanDays() ([]*PlanDay, error) {
+	days := make([]*PlanDay, 0)
+	if err := factory.DB().Where("plan_id = ?", p.Id).Asc("day_index").Find(&days); err != nil {
+		return nil, err
+	}
+
+	return days, nil
+}
+
+func (p Plan) Meals() ([]*PlannedMeal, error) {
+	meals := make([]*PlannedMeal, 0)
+	if err := factory.DB().Where("plan_id = ?", p.Id).Asc("day_index", "slot").Find(&meals); err != nil {
+		return nil, err
+	}
+
+	return meals, nil
+}
+
+// Copy 는 plan의 목표와 식단을 그대로 가진 새 plan을 만든다.
+func (p Plan) Copy(userId int64, name string, startDate time.Time, isTemplate bool) (*Plan, error) {
+	days, err := p.PlanDays()
+	if err != nil {
+		return nil, err
+	}
+
+	meals, err := p.Meals()
+	if err != nil {
+		return nil, err
+	}
+
+	newPlan := Plan{UserId: userId, Name: name, StartDate: startDate, Days: p.Days, IsTemplate: isTemplate}
+
+	err = factory.Transaction(func(session *xorm.Session) error {
+		if _, err := newPlan.CreateWithSes(session); err != nil {
+			return err
+		}
+
+		for _, day := range days {
+			newDay := PlanDay{PlanId: newPlan.Id, DayIndex: day.DayIndex, MacroTarget: day.MacroTarget}
+			if _, err := newDay.CreateWithSes(session); err != nil {
+				return err
+			}
+		}
+
+		for _, meal := range meals {
+			newMeal := PlannedMeal{PlanId: newPlan.Id, DayIndex: meal.DayIndex, Slot: meal.Slot,
+				FoodId: meal.FoodId, Quantity: meal.Quantity}
+			if _, err := newMeal.CreateWithSes(session); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &newPlan, nil
+}
+
+func (Plan) Delete(id int64) error {
+	return factory.Transaction(func(session *xorm.Session) error {
+		if _, err := session.Where("plan_id = ?", id).Delete(&PlannedMeal{}); err != nil {
+			return err
+		}
+
+		if _, err := session.Where("plan_id = ?", id).Delete(&PlanDay{}); err != nil {
+			return err
+		}
+
+		_, err := session.ID(id).Delete(&Plan{})
+		return err
+	})
+}
+
+func (PlannedMeal) Get(id int64) (*PlannedMeal, error) {
+	var m PlannedMeal
+	if has, err := factory.DB().ID(id).Get(&m); err != nil {
+		return &m, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &m, nil
+}
+
+func (PlannedMeal) Delete(id int64) error {
+	_, err := factory.DB().ID(id).Delete(&PlannedMeal{})
+	return err
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"github.com/kernelgarden/diet/calculator"
+	"github.com/kernelgarden/diet/factory"
+	"time"
+)
+
+// Profile 은 칼로리 목표를 계산하기 위한 user의 신체 정보와 목표이다.
+type Profile struct {
+	Id            int64     `json:"Id" xorm:"pk autoincr"`
+	UserId        int64     `json:"UserId" xorm:"unique"`
+	Sex           int32     `json:"Sex"`
+	BirthDate     time.Time `json:"BirthDate"`
+	Height        float64   `json:"Height"`
+	Weight        float64   `json:"Weight"`
+	ActivityLevel int32     `json:"ActivityLevel"`
+	GoalWeight    float64   `json:"GoalWeight"`
+	GoalRate      float64   `json:"GoalRate"`
+	Formula       int32     `json:"Formula"`
+	CreatedAt     time.Time `json:"-" xorm:"created"`
+	DeletedAt     time.Time `json:"-" xorm:"deleted"`
+}
+
+type ProfileTarget struct {
+	BMR    float64     `json:"BMR"`
+	TDEE   float64     `json:"TDEE"`
+	Target MacroTarget `json:"Target"`
+}
+
+func (p *Profile) Create() (int64, error) {
+	return factory.DB().Insert(p)
+}
+
+func (Profile) GetByUser(userId int64) (*Profile, error) {
+	var p Profile
+	if has, err := factory.DB().Where("user_id = ?", userId).Get(&p); err != nil {
+		return &p, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &p, nil
+}
+
+// Update 는 profile을 바꾼다. 체중 유지(0)도 목표이므로 GoalRate 는 0이어도 저장한다.
+func (p *Profile) Update() error {
+	_, err := factory.DB().ID(p.Id).MustCols("goal_rate").Update(p)
+	return err
+}
+
+// Target 은 now 기준 나이로 BMR, TDEE 와 하루 칼로리/탄단지 목표를 계산한다.
+// 몸무게는 체성분 기록이 있으면 가장 최근에 측정한 값을 쓴다.
+// 생년월일을 모르는 등 목표를 계산할 수 없으면 calculator.ErrInvalidBody 를 돌려준다.
+func (p Profile) Target(now time.Time) (*ProfileTarget, error) {
+	if p.BirthDate.IsZero() {
+		return nil, calculator.ErrInvalidBody
+	}
+
+	latestWeight, err := BodyMeasurement{}.LatestWeight(p.UserId)
+	if err != nil {
+		return nil, err
+	} else if latestWeight > 0 {
+		p.Weight = latestWeight
+	}
+
+	body := calculator.Body{Sex: p.Sex, Age: calculator.Age(p.BirthDate, now), Height: p.Height, Weight: p.Weight}
+
+	bmr, err := calculator.BMR(p.Formula, body)
+	if err != nil {
+		return nil, err
+	}
+
+	tdee, err := calculator.TDEE(bmr, p.ActivityLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	calorie := calculator.DailyCalorie(tdee, p.GoalRate, p.Sex)
+	macros := calculator.MacroTargets(calorie, p.Weight)
+
+	target := MacroTarget{Calorie: calorie, Carbohydrate: macros.Carbohydrate, Protein: macros.Protein, Fat: macros.Fat}
+
+	return &ProfileTarget{BMR: bmr, TDEE: tdee, Target: target}, nil
+}
+
+// DailyTarget 은 profile이 있으면 user의 하루 목표를, 없으면 nil을 돌려준다.
+func DailyTarget(userId int64, now time.Time) (*MacroTarget, error) {
+	profile, err := Profile{}.GetByUser(userId)
+	if err != nil || profile == nil {
+		return nil, err
+	}
+
+	target, err := profile.Target(now)
+	if err == calculator.ErrInvalidBody {
+		// 아직 profile을 다 채우지 않은 경우
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &target.Target, nil
+}
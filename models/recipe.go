@@ -0,0 +1,347 @@
+package models
+
+import (
+	"errors"
+	"github.com/go-xorm/xorm"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/units"
+	"math"
+	"time"
+)
+
+var ErrRecipeYieldUnknown = errors.New("recipe yield is unknown")
+
+// recipe의 1인분 serving size 이름
+const recipeServingLabel = "1인분"
+
+// Recipe 는 여러 food를 재료로 만든 음식이다.
+// 재료의 nutrient를 합한 영양 정보를 FoodId 의 food로 저장해 두므로 다른 food처럼 검색하고 기록할 수 있다.
+// 재료의 영양 정보가 바뀌어도 recipe를 다시 저장하기 전에는 반영되지 않는다.
+type Recipe struct {
+	Id            int64     `json:"Id" xorm:"pk autoincr"`
+	FoodId        int64     `json:"FoodId" xorm:"index"`
+	UserId        int64     `json:"UserId" xorm:"index"`
+	Servings      int32     `json:"Servings"`      // 몇 인분인지
+	YieldGrams    float64   `json:"YieldGrams"`    // 완성된 음식의 무게(g)
+	ServingSizeId int64     `json:"ServingSizeId"` // 1인분 serving size
+	CreatedAt     time.Time `json:"-" xorm:"created"`
+	DeletedAt     time.Time `json:"-" xorm:"deleted"`
+}
+
+// RecipeIngredient 의 Quantity 는 MealEntry 와 같이 재료 food nutrient의 Unit 기준으로 저장한다.
+// Grams 는 재료의 무게이고, 무게로 바꿀 수 없으면 0이다.
+type RecipeIngredient struct {
+	Id             int64      `json:"Id" xorm:"pk autoincr"`
+	RecipeId       int64      `json:"RecipeId" xorm:"index"`
+	FoodId         int64      `json:"FoodId"`
+	Quantity       float64    `json:"Quantity"`
+	LoggedQuantity float64    `json:"LoggedQuantity"`
+	LoggedUnit     units.Unit `json:"LoggedUnit"`
+	ServingSizeId  int64      `json:"ServingSizeId"`
+	Grams          float64    `json:"Grams"`
+	CreatedAt      time.Time  `json:"-" xorm:"created"`
+	DeletedAt      time.Time  `json:"-" xorm:"deleted"`
+}
+
+type RecipeIngredientJSON struct {
+	Ingredient RecipeIngredient `json:"Ingredient"`
+	FoodName   string           `json:"FoodName"`
+	Intake     Intake           `json:"Intake"`
+}
+
+type RecipeJSON struct {
+	Recipe      Recipe                 `json:"Recipe"`
+	Ingredients []RecipeIngredientJSON `json:"Ingredients"`
+	Total       Intake                 `json:"Total"`
+}
+
+func (r Recipe) ToJSON() (*RecipeJSON, error) {
+	ingredients, err := r.Ingredients()
+	if err != nil {
+		return nil, err
+	}
+
+	result := RecipeJSON{Recipe: r, Ingredients: make([]RecipeIngredientJSON, 0, len(ingredients))}
+	for _, ingredient := range ingredients {
+		ingredientJSON := RecipeIngredientJSON{Ingredient: *ingredient}
+
+		// 재료 food가 지워졌어도 이름만 빠진 채로 보여준다.
+		food, err := Food{}.Get(ingredient.FoodId)
+		if err != nil {
+			return nil, err
+		} else if food != nil {
+			ingredientJSON.FoodName = food.Name
+		}
+
+		nutrient, err := Nutrient{}.GetByFoodId(ingredient.FoodId)
+		if err != nil {
+			return nil, err
+		} else if nutrient != nil {
+			ingredientJSON.Intake = nutrient.Intake(ingredient.Quantity)
+		}
+
+		result.Ingredients = append(result.Ingredients, ingredientJSON)
+		result.Total = result.Total.Add(ingredientJSON.Intake)
+	}
+
+	return &result, nil
+}
+
+// SetQuantity 는 MealEntry.SetQuantity 와 같이 입력한 양을 재료 food nutrient의 Unit 기준으로 바꿔서 저장한다.
+func (i *RecipeIngredient) SetQuantity(food Food, quantity float64, unit units.Unit, servingSizeId int64) error {
+	nutrient, err := Nutrient{}.GetByFoodId(food.Id)
+	if err != nil {
+		return err
+	} else if nutrient == nil {
+		return ErrNutrientNotFound
+	}
+
+	converted, unit, err := food.ToNutrientQuantity(*nutrient, quantity, unit, servingSizeId)
+	if err != nil {
+		return err
+	}
+
+	// 개수 단위이거나 밀도를 몰라서 무게로 바꿀 수 없으면 recipe의 완성 무게를 따로 받아야 한다.
+	grams, err := units.Convert(converted, nutrient.Unit, units.Gram, food.Density)
+	if err != nil {
+		grams = 0
+	}
+
+	i.FoodId = food.Id
+	i.Quantity = converted
+	i.LoggedQuantity = quantity
+	i.LoggedUnit = unit
+	i.ServingSizeId = servingSizeId
+	i.Grams = grams
+
+	return nil
+}
+
+// recipeNutrient 는 재료의 영양소를 모두 더해서 완성된 음식 100g 기준의 nutrient와 비타민/무기질을 계산한다.
+func recipeNutrient(ingredients []RecipeIngredient, yieldGrams float64) (Nutrient, map[string]float32, error) {
+	var total Intake
+	var hasSodium, hasSugars, hasDietaryFiber, hasCholesterol, hasAlcohol bool
+	micronutrients := make(map[string]float64)
+
+	for _, ingredient := range ingredients {
+		nutrient, err := Nutrient{}.GetByFoodId(ingredient.FoodId)
+		if err != nil {
+			return Nutrient{}, nil, err
+		} else if nutrient == nil {
+			return Nutrient{}, nil, ErrNutrientNotFound
+		}
+
+		total = total.Add(nutrient.Intake(ingredient.Quantity))
+		hasSodium = hasSodium || nutrient.Sodium != nil
+		hasSugars = hasSugars || nutrient.Sugars != nil
+		hasDietaryFiber = hasDietaryFiber || nutrient.DietaryFiber != nil
+		hasCholesterol = hasCholesterol || nutrient.Cholesterol != nil
+		hasAlcohol = hasAlcohol || nutrient.Alcohol != nil
+
+		if nutrient.PerUnit == 0 {
+			continue
+		}
+
+		amounts, err := Micronutrient{}.GetByNutrientId(nutrient.Id)
+		if err != nil {
+			return Nutrient{}, nil, err
+		}
+
+		for _, amount := range amounts {
+			micronutrients[amount.Code] += float64(amount.Amount) * ingredient.Quantity / float64(nutrient.PerUnit)
+		}
+	}
+
+	ratio := 100 / yieldGrams
+	per100 := func(value float64) float32 {
+		return float32(value * ratio)
+	}
+	optional := func(has bool, value float64) *float32 {
+		if !has {
+			return nil
+		}
+
+		v := per100(value)
+		return &v
+	}
+
+	nutrient := Nutrient{
+		Carbohydrate:   per100(total.Carbohydrate),
+		Protein:        per100(total.Protein),
+		SaturatedFat:   per100(total.SaturatedFat),
+		UnSaturatedFat: per100(total.UnSaturatedFat),
+		TransFat:       per100(total.TransFat),
+		PerUnit:        100,
+		Calorie:        int64(math.Round(total.Calorie * ratio)),
+		Unit:           units.Gram,
+		Sodium:         optional(hasSodium, total.Sodium),
+		Sugars:         optional(hasSugars, total.Sugars),
+		DietaryFiber:   optional(hasDietaryFiber, total.DietaryFiber),
+		Cholesterol:    optional(hasCholesterol, total.Cholesterol),
+		Alcohol:        optional(hasAlcohol, total.Alcohol),
+	}
+
+	amounts := make(map[string]float32, len(micronutrients))
+	for code, amount := range micronutrients {
+		amounts[code] = per100(amount)
+	}
+
+	return nutrient, amounts, nil
+}
+
+// RecipeNutrient 는 재료로 완성된 음식 100g 기준의 nutrient와 비타민/무기질을 계산한다.
+// recipe.YieldGrams 가 0이면 재료 무게의 합을 완성된 무게로 채우고, 무게를 모르는 재료가 있으면 ErrRecipeYieldUnknown 을 돌려준다.
+func RecipeNutrient(recipe *Recipe, ingredients []RecipeIngredient) (Nutrient, map[string]float32, error) {
+	if recipe.YieldGrams <= 0 {
+		recipe.YieldGrams = 0
+		for _, ingredient := range ingredients {
+			if ingredient.Grams <= 0 {
+				return Nutrient{}, nil, ErrRecipeYieldUnknown
+			}
+			recipe.YieldGrams += ingredient.Grams
+		}
+	}
+	if recipe.YieldGrams <= 0 {
+		return Nutrient{}, nil, ErrRecipeYieldUnknown
+	}
+
+	return recipeNutrient(ingredients, recipe.YieldGrams)
+}
+
+// SaveRecipe 는 RecipeNutrient 로 계산한 영양 정보와 recipe, 그 food를 함께 저장한다.
+// food.Id 가 0이면 새로 만들고, 아니면 food와 nutrient, 1인분 serving size, 재료를 모두 새 값으로 바꾼다.
+func SaveRecipe(food *Food, recipe *Recipe, ingredients []RecipeIngredient, nutrient Nutrient,
+	micronutrients map[string]float32) error {
+	food.Weight = recipe.YieldGrams
+	servingGrams := recipe.YieldGrams / float64(recipe.Servings)
+
+	return factory.Transaction(func(session *xorm.Session) error {
+		if food.Id == 0 {
+			if _, err := food.CreateWithSes(session); err != nil {
+				return err
+			}
+		} else if err := food.UpdateWithSes(session); err != nil {
+			return err
+		}
+
+		var oldNutrient Nutrient
+		has, err := session.Where("food_id = ?", food.Id).Get(&oldNutrient)
+		if err != nil {
+			return err
+		}
+
+		nutrient.FoodId = food.Id
+		if has {
+			nutrient.Id = oldNutrient.Id
+			if err := nutrient.ReplaceWithSes(session); err != nil {
+				return err
+			}
+		} else if _, err := nutrient.CreateWithSes(session); err != nil {
+			return err
+		}
+
+		// 재료가 바뀌면서 빠진 비타민/무기질이 남지 않도록 모두 바꾼다.
+		if err := ReplaceMicronutrientsWithSes(session, nutrient.Id, micronutrients); err != nil {
+			return err
+		}
+
+		// 이미 기록한 식사가 serving size를 참조하고 있으므로 지우지 않고 무게만 바꾼다.
+		if recipe.ServingSizeId != 0 {
+			servingSize := ServingSize{Grams: servingGrams}
+			if _, err := session.ID(recipe.ServingSizeId).Cols("grams").Update(&servingSize); err != nil {
+				return err
+			}
+		} else {
+			servingSize := ServingSize{FoodId: food.Id, Label: recipeServingLabel, Grams: servingGrams}
+			if _, err := servingSize.CreateWithSes(session); err != nil {
+				return err
+			}
+			recipe.ServingSizeId = servingSize.Id
+		}
+
+		recipe.FoodId = food.Id
+		if recipe.Id == 0 {
+			if _, err := session.Insert(recipe); err != nil {
+				return err
+			}
+		} else if _, err := session.ID(recipe.Id).AllCols().Update(recipe); err != nil {
+			return err
+		}
+
+		if _, err := session.Where("recipe_id = ?", recipe.Id).Delete(&RecipeIngredient{}); err != nil {
+			return err
+		}
+
+		for idx := range ingredients {
+			ingredients[idx].Id = 0
+			ingredients[idx].RecipeId = recipe.Id
+			if _, err := session.Insert(&ingredients[idx]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (Recipe) Get(id int64) (*Recipe, error) {
+	var r Recipe
+	if has, err := factory.DB().ID(id).Get(&r); err != nil {
+		return &r, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &r, nil
+}
+
+func (Recipe) GetByFoodId(foodId int64) (*Recipe, error) {
+	var r Recipe
+	if has, err := factory.DB().Where("food_id = ?", foodId).Get(&r); err != nil {
+		return &r, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &r, nil
+}
+
+func (Recipe) GetByUser(userId int64, offset, limit int) ([]*Recipe, error) {
+	recipes := make([]*Recipe, 0)
+
+	err := factory.DB().
+		Where("user_id = ?", userId).
+		Desc("id").
+		Limit(limit, offset).
+		Find(&recipes)
+	if err != nil {
+		return nil, err
+	}
+
+	return recipes, nil
+}
+
+func (r Recipe) Ingredients() ([]*RecipeIngredient, error) {
+	ingredients := make([]*RecipeIngredient, 0)
+	if err := factory.DB().Where("recipe_id = ?", r.Id).Asc("id").Find(&ingredients); err != nil {
+		return nil, err
+	}
+
+	return ingredients, nil
+}
+
+// Delete 는 recipe와 재료, recipe의 food를 함께 지운다.
+func (r Recipe) Delete() error {
+	return factory.Transaction(func(session *xorm.Session) error {
+		if _, err := session.Where("recipe_id = ?", r.Id).Delete(&RecipeIngredient{}); err != nil {
+			return err
+		}
+
+		if _, err := session.ID(r.Id).Delete(&Recipe{}); err != nil {
+			return err
+		}
+
+		_, err := session.ID(r.FoodId).Delete(&Food{})
+		return err
+	})
+}
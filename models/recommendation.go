@@ -0,0 +1,257 @@
+package models
+
+import (
+	"fmt"
+	"github.com/go-xorm/xorm"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/recommend"
+	"github.com/kernelgarden/diet/search"
+	"github.com/kernelgarden/diet/units"
+	"sort"
+)
+
+// 추천 후보로 불러올 최대 food 수. 칼로리 대비 단백질이 많은 food부터 불러온다.
+const maxRecommendationCandidates = 500
+
+// 후보에 함께 넣을 최근에 먹은 food 수
+const maxRecentCandidates = 100
+
+// 추천 후보 중 남은 칼로리 안에서 먹을 수 있는 양이 있을 만한 food만 고르는 조건.
+// 기본 양(PerUnit)이 남은 칼로리보다 많아도 serving size가 있으면 더 작은 양을 먹을 수 있으므로 후보에 넣는다.
+var recommendationCondition = "nutrient.per_unit > 0 AND nutrient.calorie > 0 AND (nutrient.calorie <= ? OR EXISTS " +
+	"(SELECT 1 FROM serving_size WHERE serving_size.food_id = food.id AND " + notDeleted("serving_size") + "))"
+
+type RecommendationFilter struct {
+	CategoryId int64
+	BrandId    int64
+	// 후보에서 뺄 food와 category
+	ExcludeFoodIds     []int64
+	ExcludeCategoryIds []int64
+}
+
+func toInterfaces(ids []int64) []interface{} {
+	result := make([]interface{}, len(ids))
+	for idx, id := range ids {
+		result[idx] = id
+	}
+
+	return result
+}
+
+func (f RecommendationFilter) query(maxCalorie float64) *xorm.Session {
+	return f.apply(searchDocumentQuery().And(recommendationCondition, maxCalorie))
+}
+
+// apply 는 session 에 filter의 조건을 더한다.
+func (f RecommendationFilter) apply(session *xorm.Session) *xorm.Session {
+	if f.CategoryId != 0 {
+		session = session.And("food.category_id = ?", f.CategoryId)
+	}
+	if f.BrandId != 0 {
+		session = session.And("food.brand_id = ?", f.BrandId)
+	}
+	if len(f.ExcludeFoodIds) > 0 {
+		session = session.NotIn("food.id", toInterfaces(f.ExcludeFoodIds)...)
+	}
+	if len(f.ExcludeCategoryIds) > 0 {
+		session = session.NotIn("food.category_id", toInterfaces(f.ExcludeCategoryIds)...)
+	}
+
+	return session
+}
+
+// RecommendationCandidates 는 maxCalorie 안에서 먹을 수 있는 양이 있을 만한 food와 그 양들을 추천 후보로 불러온다.
+// 칼로리 대비 단백질이 많은 food와 boosts 에 있는 최근에 먹은 food를 후보로 삼는다.
+func RecommendationCandidates(filter RecommendationFilter, maxCalorie float64, boosts map[int64]search.Boost) ([]recommend.Candidate, error) {
+	docs := make([]search.Document, 0)
+	err := filter.query(maxCalorie).
+		OrderBy("nutrient.protein / nutrient.calorie DESC, food.id ASC").
+		Limit(maxRecommendationCandidates).
+		Find(&docs)
+	if err != nil {
+		return nil, err
+	}
+
+	if docs, err = appendRecentDocuments(docs, filter, maxCalorie, boosts); err != nil {
+		return nil, err
+	}
+
+	return candidates(docs, boosts)
+}
+
+// 식단 후보는 칼로리 대비 탄수화물, 단백질, 지방이 많은 food를 번갈아 넣어서
+// 앞에서부터 잘라도 어느 한 영양소에 치우친 목표를 맞출 수 있게 한다.
+var planCandidateOrders = []string{
+	"nutrient.carbohydrate / nutrient.calorie DESC",
+	"nutrient.protein / nutrient.calorie DESC",
+	"(nutrient.saturated_fat + nutrient.un_saturated_fat + nutrient.trans_fat) / nutrient.calorie DESC",
+}
+
+// 영양소마다 불러올 식단 후보 수
+const maxPlanCandidatesPerMacro = 100
+
+// PlanCandidates 는 RecommendationCandidates 와 같지만, 칼로리 대비 탄수화물, 단백질, 지방이 많은 food를
+// 번갈아 넣은 순서로 후보를 불러온다.
+func PlanCandidates(filter RecommendationFilter, maxCalorie float64, boosts map[int64]search.Boost) ([]recommend.Candidate, error) {
+	lists := make([][]search.Document, len(planCandidateOrders))
+	for idx, order := range planCandidateOrders {
+		lists[idx] = make([]search.Document, 0)
+		err := filter.query(maxCalorie).
+			OrderBy(order + ", food.id ASC").
+			Limit(maxPlanCandidatesPerMacro).
+			Find(&lists[idx])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	docs := make([]search.Document, 0, len(lists)*maxPlanCandidatesPerMacro)
+	for rank := 0; rank < maxPlanCandidatesPerMacro; rank++ {
+		for _, list := range lists {
+			if rank < len(list) {
+				docs = append(docs, list[rank])
+			}
+		}
+	}
+
+	docs, err := appendRecentDocuments(docs, filter, maxCalorie, boosts)
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates(docs, boosts)
+}
+
+// appendRecentDocuments 는 boosts 에 있는 최근에 먹은 food 중 filter 에 맞는 것을 docs 뒤에 더한다.
+func appendRecentDocuments(docs []search.Document, filter RecommendationFilter, maxCalorie float64,
+	boosts map[int64]search.Boost) ([]search.Document, error) {
+	recentIds := recentFoodIds(boosts)
+	if len(recentIds) == 0 {
+		return docs, nil
+	}
+
+	recentDocs := make([]search.Document, 0)
+	if err := filter.query(maxCalorie).In("food.id", toInterfaces(recentIds)...).Find(&recentDocs); err != nil {
+		return nil, err
+	}
+
+	return append(docs, recentDocs...), nil
+}
+
+// FoodCandidates 는 칼로리를 알 수 있는 food 중 filter 에 맞는 foodIds 의 food를 후보로 불러온다.
+func FoodCandidates(filter RecommendationFilter, foodIds []int64, boosts map[int64]search.Boost) ([]recommend.Candidate, error) {
+	if len(foodIds) == 0 {
+		return make([]recommend.Candidate, 0), nil
+	}
+
+	docs := make([]search.Document, 0)
+	err := filter.apply(searchDocumentQuery().And("nutrient.per_unit > 0 AND nutrient.calorie > 0")).
+		In("food.id", toInterfaces(foodIds)...).
+		Find(&docs)
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates(docs, boosts)
+}
+
+// candidates 는 검색 document마다 먹을 수 있는 양을 붙여서 후보로 만든다. 같은 food는 한 번만 넣는다.
+func candidates(docs []search.Document, boosts map[int64]search.Boost) ([]recommend.Candidate, error) {
+	result := make([]recommend.Candidate, 0, len(docs))
+	foodIds := make([]interface{}, 0, len(docs))
+	indexes := make(map[int64]int, len(docs))
+	for _, doc := range docs {
+		if _, ok := indexes[doc.Id]; ok {
+			continue
+		}
+
+		indexes[doc.Id] = len(result)
+		foodIds = append(foodIds, doc.Id)
+		result = append(result, recommend.Candidate{FoodId: doc.Id, Name: doc.Name, BrandName: doc.BrandName,
+			CategoryName: doc.CategoryName, EatCount: boosts[doc.Id].EatCount})
+	}
+
+	if len(result) == 0 {
+		return result, nil
+	}
+
+	foods := make([]*Food, 0)
+	if err := factory.DB().In("id", foodIds...).Find(&foods); err != nil {
+		return nil, err
+	}
+	densities := make(map[int64]float64, len(foods))
+	for _, food := range foods {
+		densities[food.Id] = food.Density
+	}
+
+	servingSizes := make([]*ServingSize, 0)
+	if err := factory.DB().In("food_id", foodIds...).Asc("grams").Find(&servingSizes); err != nil {
+		return nil, err
+	}
+	servingSizesByFood := make(map[int64][]*ServingSize)
+	for _, servingSize := range servingSizes {
+		servingSizesByFood[servingSize.FoodId] = append(servingSizesByFood[servingSize.FoodId], servingSize)
+	}
+
+	nutrients := make([]*Nutrient, 0)
+	if err := factory.DB().In("food_id", foodIds...).Find(&nutrients); err != nil {
+		return nil, err
+	}
+	for _, nutrient := range nutrients {
+		idx, ok := indexes[nutrient.FoodId]
+		if !ok {
+			continue
+		}
+
+		result[idx].Portions = portions(*nutrient, densities[nutrient.FoodId], servingSizesByFood[nutrient.FoodId])
+	}
+
+	return result, nil
+}
+
+// recentFoodIds 는 boosts 중 많이 먹은 food의 ID를 maxRecentCandidates 개까지 돌려준다.
+func recentFoodIds(boosts map[int64]search.Boost) []int64 {
+	foodIds := make([]int64, 0, len(boosts))
+	for foodId := range boosts {
+		foodIds = append(foodIds, foodId)
+	}
+	sort.Slice(foodIds, func(i, j int) bool {
+		if boosts[foodIds[i]].EatCount != boosts[foodIds[j]].EatCount {
+			return boosts[foodIds[i]].EatCount > boosts[foodIds[j]].EatCount
+		}
+		return foodIds[i] < foodIds[j]
+	})
+
+	if len(foodIds) > maxRecentCandidates {
+		foodIds = foodIds[:maxRecentCandidates]
+	}
+
+	return foodIds
+}
+
+func toRecommendMacro(intake Intake) recommend.Macro {
+	macro := intake.Macro()
+	return recommend.Macro{Calorie: macro.Calorie, Carbohydrate: macro.Carbohydrate, Protein: macro.Protein, Fat: macro.Fat}
+}
+
+// portions 는 nutrient의 기본 양(PerUnit)과 각 serving size 1인분을 추천할 양으로 만든다.
+// nutrient의 Unit 으로 바꿀 수 없는 serving size는 뺀다.
+func portions(nutrient Nutrient, density float64, servingSizes []*ServingSize) []recommend.Portion {
+	result := []recommend.Portion{{
+		Label:    fmt.Sprintf("%d%s", nutrient.PerUnit, nutrient.Unit.String()),
+		Quantity: float64(nutrient.PerUnit),
+		Macro:    toRecommendMacro(nutrient.Intake(float64(nutrient.PerUnit))),
+	}}
+
+	for _, servingSize := range servingSizes {
+		quantity, err := nutrient.ToUnitQuantity(servingSize.Grams, units.Gram, density)
+		if err != nil {
+			continue
+		}
+
+		result = append(result, recommend.Portion{Label: servingSize.Label, ServingSizeId: servingSize.Id,
+			Quantity: quantity, Macro: toRecommendMacro(nutrient.Intake(quantity))})
+	}
+
+	return result
+}
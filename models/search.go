@@ -0,0 +1,133 @@
+package models
+
+import (
+	"github.com/go-xorm/xorm"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/search"
+	"time"
+)
+
+// 검색 document의 칼로리는 단위가 다른 food끼리도 비교할 수 있게 nutrient Unit 기준 100 당 값으로 바꾼다.
+const searchDocumentColumns = "food.id AS id, food.name AS name, food.brand_id AS brand_id, " +
+	"COALESCE(brand.name, '') AS brand_name, food.category_id AS category_id, " +
+	"COALESCE(category.name, '') AS category_name, " +
+	"COALESCE(nutrient.calorie * 100 / NULLIF(nutrient.per_unit, 0), 0) AS calorie"
+
+func searchDocumentQuery() *xorm.Session {
+	return factory.DB().Table("food").
+		Join("INNER", "nutrient", "nutrient.food_id = food.id AND "+notDeleted("nutrient")).
+		Join("LEFT", "brand", "brand.id = food.brand_id AND "+notDeleted("brand")).
+		Join("LEFT", "category", "category.id = food.category_id AND "+notDeleted("category")).
+		Where(notDeleted("food")).
+		Select(searchDocumentColumns)
+}
+
+func searchDocuments(condition string, args ...interface{}) ([]search.Document, error) {
+	docs := make([]search.Document, 0)
+	if err := searchDocumentQuery().And(condition, args...).Find(&docs); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+// RebuildSearchIndex 는 모든 food로 검색 색인과 자동 완성 trie를 다시 만든다.
+func RebuildSearchIndex() error {
+	docs, err := searchDocuments("1 = 1")
+	if err != nil {
+		return err
+	}
+
+	factory.Suggester().Rebuild(docs)
+
+	return factory.SearchIndex().Rebuild(docs)
+}
+
+// LoadSearchIndex 는 서버를 시작할 때 메모리에 두는 자동 완성 trie를 채운다.
+// 검색 색인은 메모리에 두거나 저장된 색인이 비었거나 예전 버전일 때만 다시 만들고,
+// 그 뒤로는 food가 바뀔 때마다 Reindex 로 고친다.
+func LoadSearchIndex() error {
+	docs, err := searchDocuments("1 = 1")
+	if err != nil {
+		return err
+	}
+
+	factory.Suggester().Rebuild(docs)
+
+	if persistent, ok := factory.SearchIndex().(search.PersistentIndex); ok {
+		if current, err := persistent.IsCurrent(); err != nil {
+			return err
+		} else if current {
+			return nil
+		}
+	}
+
+	return factory.SearchIndex().Rebuild(docs)
+}
+
+// ReindexFood 는 food 하나의 검색 document를 다시 만든다. 삭제된 food는 색인에서 뺀다.
+func ReindexFood(foodId int64) error {
+	docs, err := searchDocuments("food.id = ?", foodId)
+	if err != nil {
+		return err
+	} else if len(docs) == 0 {
+		factory.Suggester().Remove(foodId)
+		return factory.SearchIndex().Remove(foodId)
+	}
+
+	factory.Suggester().Put(docs...)
+
+	return factory.SearchIndex().Put(docs...)
+}
+
+// ReindexBrand 는 brand 이름이 바뀌거나 brand가 삭제됐을 때 그 brand의 food들을 다시 색인한다.
+func ReindexBrand(brandId int64) error {
+	docs, err := searchDocuments("food.brand_id = ?", brandId)
+	if err != nil {
+		return err
+	}
+
+	factory.Suggester().Put(docs...)
+
+	return factory.SearchIndex().Put(docs...)
+}
+
+// ReindexCategory 는 category 이름이 바뀌거나 category가 삭제됐을 때 그 category의 food들을 다시 색인한다.
+func ReindexCategory(categoryId int64) error {
+	docs, err := searchDocuments("food.category_id = ?", categoryId)
+	if err != nil {
+		return err
+	}
+
+	factory.Suggester().Put(docs...)
+
+	return factory.SearchIndex().Put(docs...)
+}
+
+type foodBoost struct {
+	FoodId      int64
+	EatCount    int64
+	LastEatenAt time.Time
+}
+
+// FoodBoosts 는 since 이후에 user가 먹은 food 별로 먹은 횟수와 마지막으로 먹은 시간을 구한다.
+func FoodBoosts(userId int64, since time.Time) (map[int64]search.Boost, error) {
+	rows := make([]foodBoost, 0)
+
+	err := factory.DB().Table("meal_entry").
+		Where("user_id = ? AND eaten_at >= ?", userId, since).
+		And(notDeleted("meal_entry")).
+		GroupBy("food_id").
+		Select("food_id, COUNT(id) AS eat_count, MAX(eaten_at) AS last_eaten_at").
+		Find(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	boosts := make(map[int64]search.Boost, len(rows))
+	for _, row := range rows {
+		boosts[row.FoodId] = search.Boost{EatCount: row.EatCount, LastEatenAt: row.LastEatenAt}
+	}
+
+	return boosts, nil
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"errors"
+	"github.com/go-xorm/xorm"
+	"github.com/kernelgarden/diet/factory"
+	"github.com/kernelgarden/diet/units"
+	"time"
+)
+
+var ErrServingSizeNotFound = errors.New("serving size not found")
+
+// ServingSize 는 "1조각", "1공기" 처럼 food를 먹는 1회 분량과 그 무게(g)이다.
+type ServingSize struct {
+	Id        int64     `json:"Id" xorm:"pk autoincr"`
+	FoodId    int64     `json:"FoodId" xorm:"index"`
+	Label     string    `json:"Label" xorm:"varchar(32)"`
+	Grams     float64   `json:"Grams"`
+	CreatedAt time.Time `json:"-" xorm:"created"`
+	DeletedAt time.Time `json:"-" xorm:"deleted"`
+}
+
+func (s *ServingSize) Create() (int64, error) {
+	return factory.DB().Insert(s)
+}
+
+func (s *ServingSize) CreateWithSes(session *xorm.Session) (int64, error) {
+	return session.Insert(s)
+}
+
+func (ServingSize) Get(id int64) (*ServingSize, error) {
+	var s ServingSize
+	if has, err := factory.DB().ID(id).Get(&s); err != nil {
+		return &s, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &s, nil
+}
+
+func (ServingSize) GetByFoodId(foodId int64) ([]*ServingSize, error) {
+	servingSizes := make([]*ServingSize, 0)
+	if err := factory.DB().Where("food_id = ?", foodId).Asc("grams").Find(&servingSizes); err != nil {
+		return nil, err
+	}
+
+	return servingSizes, nil
+}
+
+// SetServingSizesWithSes 는 food의 serving size를 servingSizes 로 바꾼다.
+// 식사 기록과 레시피 재료가 serving size ID를 참조하므로 Label 이 같으면 ID는 그대로 두고 무게만 바꾸고,
+// 새 Label 만 추가하고 없어진 Label 만 지운다.
+func SetServingSizesWithSes(session *xorm.Session, foodId int64, servingSizes []ServingSize) error {
+	existing := make([]*ServingSize, 0)
+	if err := session.Where("food_id = ?", foodId).Find(&existing); err != nil {
+		return err
+	}
+
+	byLabel := make(map[string]*ServingSize, len(existing))
+	for _, servingSize := range existing {
+		byLabel[servingSize.Label] = servingSize
+	}
+
+	for idx := range servingSizes {
+		servingSizes[idx].FoodId = foodId
+
+		old, ok := byLabel[servingSizes[idx].Label]
+		if !ok {
+			servingSizes[idx].Id = 0
+			if _, err := session.Insert(&servingSizes[idx]); err != nil {
+				return err
+			}
+			continue
+		}
+
+		delete(byLabel, old.Label)
+		servingSizes[idx].Id = old.Id
+		if old.Grams != servingSizes[idx].Grams {
+			if _, err := session.ID(old.Id).Cols("grams").Update(&servingSizes[idx]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, gone := range byLabel {
+		if _, err := session.ID(gone.Id).Delete(&ServingSize{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetServingSize 는 food에 등록된 serving size만 돌려주고, 없으면 ErrServingSizeNotFound 를 돌려준다.
+func (f Food) GetServingSize(id int64) (*ServingSize, error) {
+	servingSize, err := ServingSize{}.Get(id)
+	if err != nil {
+		return nil, err
+	} else if servingSize == nil || servingSize.FoodId != f.Id {
+		return nil, ErrServingSizeNotFound
+	}
+
+	return servingSize, nil
+}
+
+// ToNutrientQuantity 는 unit 단위로 입력한 양을 nutrient의 Unit 기준 양으로 바꾸고, 기록할 입력 단위를 함께 돌려준다.
+// unit 이 Unknown 이면 nutrient의 Unit 으로 입력한 것으로 본다.
+// servingSizeId 가 있으면 unit 은 무시하고 quantity 를 그 serving size의 개수로 본다.
+func (f Food) ToNutrientQuantity(nutrient Nutrient, quantity float64, unit units.Unit, servingSizeId int64) (float64, units.Unit, error) {
+	// serving size는 무게(g)로 바꾼 뒤에 nutrient의 Unit 으로 다시 바꾼다.
+	amount, amountUnit := quantity, unit
+	if servingSizeId != 0 {
+		servingSize, err := f.GetServingSize(servingSizeId)
+		if err != nil {
+			return 0, unit, err
+		}
+
+		amount, amountUnit, unit = quantity*servingSize.Grams, units.Gram, units.Serving
+	} else if unit == units.Unknown {
+		amountUnit, unit = nutrient.Unit, nutrient.Unit
+	}
+
+	converted, err := nutrient.ToUnitQuantity(amount, amountUnit, f.Density)
+	if err != nil {
+		return 0, unit, err
+	}
+
+	return converted, unit, nil
+}
+
+func (ServingSize) Delete(id int64) error {
+	_, err := factory.DB().ID(id).Delete(&ServingSize{})
+	return err
+}
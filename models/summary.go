@@ -0,0 +1,74 @@
+package models
+
+import (
+	"github.com/go-xorm/xorm"
+	"github.com/kernelgarden/diet/factory"
+	"strconv"
+	"time"
+)
+
+// 식사 기록의 영양소 합계는 food 별로 불러오지 않고 SQL에서 바로 집계한다.
+var intakeColumns = "COALESCE(SUM(nutrient.calorie * meal_entry.quantity / NULLIF(nutrient.per_unit, 0)), 0) AS calorie, " +
+	"COALESCE(SUM(nutrient.carbohydrate * meal_entry.quantity / NULLIF(nutrient.per_unit, 0)), 0) AS carbohydrate, " +
+	"COALESCE(SUM(nutrient.protein * meal_entry.quantity / NULLIF(nutrient.per_unit, 0)), 0) AS protein, " +
+	"COALESCE(SUM(nutrient.saturated_fat * meal_entry.quantity / NULLIF(nutrient.per_unit, 0)), 0) AS saturated_fat, " +
+	"COALESCE(SUM(nutrient.un_saturated_fat * meal_entry.quantity / NULLIF(nutrient.per_unit, 0)), 0) AS un_saturated_fat, " +
+	"COALESCE(SUM(nutrient.trans_fat * meal_entry.quantity / NULLIF(nutrient.per_unit, 0)), 0) AS trans_fat, " +
+	"COALESCE(SUM(nutrient.sodium * meal_entry.quantity / NULLIF(nutrient.per_unit, 0)), 0) AS sodium, " +
+	"COALESCE(SUM(nutrient.sugars * meal_entry.quantity / NULLIF(nutrient.per_unit, 0)), 0) AS sugars, " +
+	"COALESCE(SUM(nutrient.dietary_fiber * meal_entry.quantity / NULLIF(nutrient.per_unit, 0)), 0) AS dietary_fiber, " +
+	"COALESCE(SUM(nutrient.cholesterol * meal_entry.quantity / NULLIF(nutrient.per_unit, 0)), 0) AS cholesterol, " +
+	"COALESCE(SUM(nutrient.alcohol * meal_entry.quantity / NULLIF(nutrient.per_unit, 0)), 0) AS alcohol, " +
+	"COALESCE(SUM(nutrient.alcohol * meal_entry.quantity / NULLIF(nutrient.per_unit, 0)), 0) * " + strconv.FormatFloat(KcalPerGramAlcohol, 'f', -1, 64) + " AS alcohol_calorie, " +
+	"COUNT(meal_entry.id) AS entry_count"
+
+type IntakeSummary struct {
+	Intake     `xorm:"extends"`
+	EntryCount int64 `json:"EntryCount"`
+}
+
+type DailyIntakeSummary struct {
+	Date          string `json:"Date"`
+	IntakeSummary `xorm:"extends"`
+}
+
+func notDeleted(table string) string {
+	return "(" + table + ".deleted_at IS NULL OR " + table + ".deleted_at = '0001-01-01 00:00:00')"
+}
+
+func mealIntakeQuery(userId int64, from, to time.Time) *xorm.Session {
+	return factory.DB().Table("meal_entry").
+		Join("INNER", "food", "food.id = meal_entry.food_id").
+		Join("INNER", "nutrient", "nutrient.food_id = meal_entry.food_id").
+		Where("meal_entry.user_id = ? AND meal_entry.eaten_at >= ? AND meal_entry.eaten_at < ?", userId, from, to).
+		And(notDeleted("meal_entry")).
+		And(notDeleted("food")).
+		And(notDeleted("nutrient"))
+}
+
+// SummarizeIntake 는 [from, to) 사이에 user가 먹은 영양소의 합계를 구한다.
+func SummarizeIntake(userId int64, from, to time.Time) (*IntakeSummary, error) {
+	var summary IntakeSummary
+	if _, err := mealIntakeQuery(userId, from, to).Select(intakeColumns).Get(&summary); err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// SummarizeDailyIntake 는 [from, to) 사이에 user가 먹은 영양소의 합계를 날짜별로 구한다.
+// 기록이 없는 날은 포함되지 않는다.
+func SummarizeDailyIntake(userId int64, from, to time.Time) ([]*DailyIntakeSummary, error) {
+	summaries := make([]*DailyIntakeSummary, 0)
+
+	err := mealIntakeQuery(userId, from, to).
+		Select("DATE(meal_entry.eaten_at) AS date, " + intakeColumns).
+		GroupBy("date").
+		Asc("date").
+		Find(&summaries)
+	if err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"github.com/kernelgarden/diet/factory"
+	"golang.org/x/crypto/bcrypt"
+	"time"
+)
+
+// user의 권한. 숫자가 클수록 상위 권한이며 하위 권한을 모두 포함한다.
+const (
+	RoleViewer int32 = iota + 1
+	RoleContributor
+	RoleModerator
+	RoleAdmin
+)
+
+type User struct {
+	Id           int64     `json:"Id" xorm:"pk autoincr"`
+	Email        string    `json:"Email" xorm:"varchar(128) unique"`
+	Name         string    `json:"Name" xorm:"varchar(64)"`
+	PasswordHash string    `json:"-" xorm:"varchar(128)"`
+	Role         int32     `json:"Role" xorm:"default 1"`
+	CreatedAt    time.Time `json:"-" xorm:"created"`
+	DeletedAt    time.Time `json:"-" xorm:"deleted"`
+}
+
+func (u *User) Create() (int64, error) {
+	return factory.DB().Insert(u)
+}
+
+func (User) Get(id int64) (*User, error) {
+	var u User
+	if has, err := factory.DB().ID(id).Get(&u); err != nil {
+		return &u, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &u, nil
+}
+
+func (User) GetByEmail(email string) (*User, error) {
+	var u User
+	if has, err := factory.DB().Where("email = ?", email).Get(&u); err != nil {
+		return &u, err
+	} else if !has {
+		return nil, nil
+	}
+
+	return &u, nil
+}
+
+func (u *User) Update() error {
+	_, err := factory.DB().ID(u.Id).Update(u)
+	return err
+}
+
+func (u *User) SetPassword(password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+func (u User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+func (u User) HasRole(role int32) bool {
+	return u.Role >= role
+}
+
+func IsValidRole(role int32) bool {
+	return role >= RoleViewer && role <= RoleAdmin
+}
@@ -1,5 +1,9 @@
 package models
 
+import "errors"
+
+var ErrNutrientNotFound = errors.New("nutrient not found")
+
 func SortOrders() {
 
 }
@@ -0,0 +1,336 @@
+package planner
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	ErrInfeasible = errors.New("no plan satisfies the targets")
+	// 살펴볼 수 있는 부분 문제를 다 쓰도록 해를 찾지 못했다. 해가 없다는 뜻은 아니다.
+	ErrSearchLimit = errors.New("plan search stopped before finding a plan")
+	errUnbounded   = errors.New("linear program is unbounded")
+)
+
+const (
+	epsilon = 1e-9
+	// 한 번의 simplex에서 허용하는 최대 pivot 횟수
+	maxPivots = 10000
+)
+
+// 제약식의 종류
+const (
+	lessEqual = iota
+	greaterEqual
+	equal
+)
+
+type constraint struct {
+	coef []float64
+	kind int
+	rhs  float64
+}
+
+// problem 은 lower <= x <= upper 범위에서 제약식을 만족하면서 cost·x 를 최소로 만드는 문제이다.
+// upper 가 음수이면 위쪽 범위가 없다. integer 인 변수는 branch and bound 로 정수 해를 찾는다.
+type problem struct {
+	cost    []float64
+	rows    []constraint
+	lower   []float64
+	upper   []float64
+	integer []bool
+}
+
+func (p problem) size() int {
+	return len(p.cost)
+}
+
+// solveRelaxed 는 정수 조건 없이 two-phase simplex로 문제를 푼다.
+func (p problem) solveRelaxed() ([]float64, float64, error) {
+	n := p.size()
+
+	// x = lower + y 로 바꿔서 모든 변수가 0 이상이 되게 하고, 위쪽 범위는 제약식으로 넣는다.
+	rows := make([]constraint, 0, len(p.rows)+n)
+	for _, row := range p.rows {
+		rhs := row.rhs
+		for j := 0; j < n; j++ {
+			rhs -= row.coef[j] * p.lower[j]
+		}
+		rows = append(rows, constraint{coef: row.coef, kind: row.kind, rhs: rhs})
+	}
+	for j := 0; j < n; j++ {
+		if p.upper[j] < 0 {
+			continue
+		}
+		if p.upper[j] < p.lower[j]-epsilon {
+			return nil, 0, ErrInfeasible
+		}
+
+		coef := make([]float64, n)
+		coef[j] = 1
+		rows = append(rows, constraint{coef: coef, kind: lessEqual, rhs: p.upper[j] - p.lower[j]})
+	}
+
+	y, err := simplex(p.cost, rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	x := make([]float64, n)
+	objective := 0.0
+	for j := 0; j < n; j++ {
+		x[j] = p.lower[j] + y[j]
+		objective += p.cost[j] * x[j]
+	}
+
+	return x, objective, nil
+}
+
+// simplex 는 y >= 0 에서 rows 를 만족하면서 cost·y 를 최소로 만드는 y를 찾는다.
+// 순환하지 않도록 들어오고 나갈 변수는 Bland 규칙으로 고른다.
+func simplex(cost []float64, rows []constraint) ([]float64, error) {
+	n, m := len(cost), len(rows)
+
+	// 열 순서: 원래 변수, slack/surplus, artificial, 우변
+	slackCount, artificialCount := 0, 0
+	for idx := range rows {
+		if rows[idx].rhs < 0 {
+			coef := make([]float64, n)
+			for j := range coef {
+				coef[j] = -rows[idx].coef[j]
+			}
+			kind := rows[idx].kind
+			if kind == lessEqual {
+				kind = greaterEqual
+			} else if kind == greaterEqual {
+				kind = lessEqual
+			}
+			rows[idx] = constraint{coef: coef, kind: kind, rhs: -rows[idx].rhs}
+		}
+
+		if rows[idx].kind != equal {
+			slackCount++
+		}
+		if rows[idx].kind != lessEqual {
+			artificialCount++
+		}
+	}
+
+	artificialStart := n + slackCount
+	width := artificialStart + artificialCount
+	tableau := make([][]float64, m)
+	basis := make([]int, m)
+
+	slack, artificial := n, artificialStart
+	for i, row := range rows {
+		tableau[i] = make([]float64, width+1)
+		copy(tableau[i], row.coef)
+		tableau[i][width] = row.rhs
+
+		switch row.kind {
+		case lessEqual:
+			tableau[i][slack] = 1
+			basis[i] = slack
+			slack++
+		case greaterEqual:
+			tableau[i][slack] = -1
+			slack++
+			fallthrough
+		case equal:
+			tableau[i][artificial] = 1
+			basis[i] = artificial
+			artificial++
+		}
+	}
+
+	// 1단계: artificial 변수의 합을 최소로 만들어서 가능한 해를 찾는다.
+	if artificialCount > 0 {
+		phaseOne := make([]float64, width)
+		for j := artificialStart; j < width; j++ {
+			phaseOne[j] = 1
+		}
+
+		value, err := pivotToOptimum(tableau, basis, phaseOne, width)
+		if err != nil {
+			return nil, err
+		} else if value > 1e-7 {
+			return nil, ErrInfeasible
+		}
+
+		// 0 으로 남은 artificial 변수를 basis에서 빼낸다. 빼낼 수 없는 row는 중복된 제약식이다.
+		for i := range basis {
+			if basis[i] < artificialStart {
+				continue
+			}
+			for j := 0; j < artificialStart; j++ {
+				if math.Abs(tableau[i][j]) > epsilon {
+					pivot(tableau, basis, i, j)
+					break
+				}
+			}
+		}
+	}
+
+	// 2단계: artificial 변수를 빼고 원래 목적 함수를 최소로 만든다.
+	phaseTwo := make([]float64, width)
+	copy(phaseTwo, cost)
+	if _, err := pivotToOptimum(tableau, basis, phaseTwo, artificialStart); err != nil {
+		return nil, err
+	}
+
+	y := make([]float64, n)
+	for i, column := range basis {
+		if column < n {
+			y[column] = tableau[i][width]
+		}
+	}
+
+	return y, nil
+}
+
+// pivotToOptimum 은 columns 보다 앞의 열만 basis에 넣으면서 cost 를 최소로 만들고 그 값을 돌려준다.
+func pivotToOptimum(tableau [][]float64, basis []int, cost []float64, columns int) (float64, error) {
+	width := len(cost)
+
+	for iteration := 0; iteration < maxPivots; iteration++ {
+		entering := -1
+		for j := 0; j < columns && entering < 0; j++ {
+			if reducedCost(tableau, basis, cost, j) < -epsilon {
+				entering = j
+			}
+		}
+
+		if entering < 0 {
+			value := 0.0
+			for i, column := range basis {
+				value += cost[column] * tableau[i][width]
+			}
+			return value, nil
+		}
+
+		leaving, best := -1, math.Inf(1)
+		for i := range tableau {
+			if tableau[i][entering] <= epsilon {
+				continue
+			}
+
+			ratio := tableau[i][width] / tableau[i][entering]
+			if ratio < best-epsilon || (ratio < best+epsilon && leaving >= 0 && basis[i] < basis[leaving]) {
+				leaving, best = i, ratio
+			}
+		}
+
+		if leaving < 0 {
+			return 0, errUnbounded
+		}
+
+		pivot(tableau, basis, leaving, entering)
+	}
+
+	return 0, errors.New("simplex did not converge")
+}
+
+func reducedCost(tableau [][]float64, basis []int, cost []float64, column int) float64 {
+	value := cost[column]
+	for i, basic := range basis {
+		value -= cost[basic] * tableau[i][column]
+	}
+
+	return value
+}
+
+func pivot(tableau [][]float64, basis []int, row, column int) {
+	pivotRow := tableau[row]
+	factor := pivotRow[column]
+	for j := range pivotRow {
+		pivotRow[j] /= factor
+	}
+
+	for i := range tableau {
+		if i == row || tableau[i][column] == 0 {
+			continue
+		}
+
+		multiplier := tableau[i][column]
+		for j := range tableau[i] {
+			tableau[i][j] -= multiplier * pivotRow[j]
+		}
+	}
+
+	basis[row] = column
+}
+
+// solve 는 integer 변수가 정수인 해를 branch and bound 로 찾는다.
+// maxNodes 개의 부분 문제를 풀 때까지 찾은 해 중 가장 좋은 해를 돌려준다.
+// 그때까지 찾은 해가 없으면 ErrSearchLimit 을, 모든 부분 문제를 살펴봤는데 해가 없으면 ErrInfeasible 을 돌려준다.
+func (p problem) solve(maxNodes int) ([]float64, error) {
+	type node struct {
+		lower []float64
+		upper []float64
+	}
+
+	var best []float64
+	bestObjective := math.Inf(1)
+
+	stack := []node{{lower: p.lower, upper: p.upper}}
+	for visited := 0; len(stack) > 0 && visited < maxNodes; visited++ {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		sub := p
+		sub.lower, sub.upper = current.lower, current.upper
+
+		x, objective, err := sub.solveRelaxed()
+		if err == ErrInfeasible {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if objective >= bestObjective-epsilon {
+			continue
+		}
+
+		// 가장 소수 부분이 큰 변수로 나눈다.
+		branch, fraction := -1, 0.0
+		for j := range x {
+			if !p.integer[j] {
+				continue
+			}
+
+			if f := math.Abs(x[j] - math.Round(x[j])); f > 1e-6 && f > fraction {
+				branch, fraction = j, f
+			}
+		}
+
+		if branch < 0 {
+			for j := range x {
+				if p.integer[j] {
+					x[j] = math.Round(x[j])
+				}
+			}
+			best, bestObjective = x, objective
+			continue
+		}
+
+		down := node{lower: current.lower, upper: append([]float64(nil), current.upper...)}
+		down.upper[branch] = math.Floor(x[branch])
+		up := node{lower: append([]float64(nil), current.lower...), upper: current.upper}
+		up.lower[branch] = math.Ceil(x[branch])
+
+		// 가까운 쪽으로 반올림한 부분 문제를 먼저 푼다.
+		if x[branch]-math.Floor(x[branch]) < 0.5 {
+			stack = append(stack, up, down)
+		} else {
+			stack = append(stack, down, up)
+		}
+	}
+
+	if best == nil && len(stack) > 0 {
+		return nil, ErrSearchLimit
+	} else if best == nil {
+		return nil, ErrInfeasible
+	}
+
+	return best, nil
+}
@@ -0,0 +1,271 @@
+// Package planner 는 하루 칼로리와 탄단지 목표를 허용 오차 안에서 맞추는 식단을 정수 계획법으로 만든다.
+// 날마다 food별 먹을 양(portion 수)을 변수로 두고, 목표와의 차이가 가장 작으면서
+// 즐겨 먹는 food와 여러 가지 food를 고르도록 simplex와 branch and bound 로 푼다.
+package planner
+
+import (
+	"github.com/kernelgarden/diet/recommend"
+	"math"
+	"sort"
+)
+
+const (
+	// 하루에 한 food를 먹을 수 있는 최대 portion 수
+	maxPortionsPerDay = 3
+	// 하루 식단을 풀 때 살펴보는 최대 부분 문제 수
+	maxNodesPerDay = 300
+	// 하루 식단에 쓰는 최대 food 수
+	MaxFoods = 30
+
+	// 목적 함수의 가중치. 목표와의 차이는 목표 대비 비율로 계산한다.
+	weightCalorie = 2.0
+	weightMacro   = 1.0
+	// food 하나를 더 먹을 때마다 붙는 비용. 적은 종류로 목표를 맞추도록 한다.
+	costPortion = 0.05
+	// 이미 식단에 나온 food를 다시 고를 때 붙는 비용
+	costRepeat = 0.05
+	// 즐겨 먹는 food는 비용을 줄여서 먼저 고르도록 한다.
+	discountFavorite = 0.04
+)
+
+// Fixed 는 꼭 넣어야 하는 food이다. DayIndex 날에 Count portion 만큼 넣는다.
+// Slot 은 planner가 쓰지 않고 Item 에 그대로 넘겨준다.
+type Fixed struct {
+	DayIndex int
+	FoodId   int64
+	Count    float64
+	Slot     int32
+}
+
+type Options struct {
+	Days   int
+	Target recommend.Macro // 하루 목표
+	// 목표 대비 허용 오차 비율 (0.1 이면 ±10%)
+	Tolerance float64
+	Favorites map[int64]bool
+	// 한 주(7일)에 같은 food가 나올 수 있는 최대 일 수. 0 이면 제한하지 않는다.
+	MaxRepeatsPerWeek int
+	MustInclude       []Fixed
+}
+
+type Item struct {
+	FoodId  int64             `json:"FoodId"`
+	Portion recommend.Portion `json:"Portion"`
+	Count   float64           `json:"Count"`
+	Fixed   bool              `json:"Fixed"`
+	Slot    int32             `json:"Slot"`
+}
+
+type Day struct {
+	DayIndex int             `json:"DayIndex"`
+	Items    []Item          `json:"Items"`
+	Total    recommend.Macro `json:"Total"`
+}
+
+// Portion 은 식단에 쓸 candidate의 양이다. serving size가 있으면 첫 번째 serving size를, 없으면 기본 양을 쓴다.
+func Portion(candidate recommend.Candidate) (recommend.Portion, bool) {
+	for _, portion := range candidate.Portions {
+		if portion.ServingSizeId != 0 && portion.Macro.Calorie > 0 {
+			return portion, true
+		}
+	}
+
+	if len(candidate.Portions) == 0 || candidate.Portions[0].Macro.Calorie <= 0 {
+		return recommend.Portion{}, false
+	}
+
+	return candidate.Portions[0], true
+}
+
+func addMacro(total, macro recommend.Macro, count float64) recommend.Macro {
+	return recommend.Macro{
+		Calorie:      total.Calorie + macro.Calorie*count,
+		Carbohydrate: total.Carbohydrate + macro.Carbohydrate*count,
+		Protein:      total.Protein + macro.Protein*count,
+		Fat:          total.Fat + macro.Fat*count,
+	}
+}
+
+func macroValues(macro recommend.Macro) [4]float64 {
+	return [4]float64{macro.Calorie, macro.Carbohydrate, macro.Protein, macro.Fat}
+}
+
+// Generate 는 options.Days 일 동안의 식단을 날짜 순서대로 만든다.
+// candidates 는 식단에 쓸 수 있는 food이고, 꼭 넣어야 하는 food도 candidates 에 있어야 한다.
+// 꼭 넣어야 하는 food는 정한 날에 정한 양만 넣고, 다른 날에는 다른 food와 같이 고를 수 있다.
+// 목표를 허용 오차 안에서 맞출 수 없는 날이 있으면 ErrInfeasible 을,
+// 정해진 만큼 살펴봐도 식단을 찾지 못한 날이 있으면 ErrSearchLimit 을 돌려준다.
+func Generate(candidates []recommend.Candidate, options Options) ([]Day, error) {
+	portions := make(map[int64]recommend.Portion, len(candidates))
+	foods := make([]recommend.Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if portion, ok := Portion(candidate); ok {
+			if _, exists := portions[candidate.FoodId]; !exists {
+				foods = append(foods, candidate)
+			}
+			portions[candidate.FoodId] = portion
+		}
+	}
+
+	fixedByDay := make(map[int][]Fixed)
+	for _, fixed := range options.MustInclude {
+		if _, ok := portions[fixed.FoodId]; !ok {
+			return nil, ErrInfeasible
+		}
+		fixedByDay[fixed.DayIndex] = append(fixedByDay[fixed.DayIndex], fixed)
+	}
+
+	// 주마다 food가 나온 날 수와 식단 전체에서 나온 횟수
+	weekAppearances := make(map[int64]int)
+	appearances := make(map[int64]int)
+
+	days := make([]Day, 0, options.Days)
+	for dayIndex := 0; dayIndex < options.Days; dayIndex++ {
+		if dayIndex%7 == 0 {
+			weekAppearances = make(map[int64]int)
+		}
+
+		day := Day{DayIndex: dayIndex, Items: make([]Item, 0)}
+		fixedToday := make(map[int64]bool)
+		for _, fixed := range fixedByDay[dayIndex] {
+			fixedToday[fixed.FoodId] = true
+			portion := portions[fixed.FoodId]
+			day.Items = append(day.Items, Item{FoodId: fixed.FoodId, Portion: portion, Count: fixed.Count, Fixed: true,
+				Slot: fixed.Slot})
+			day.Total = addMacro(day.Total, portion.Macro, fixed.Count)
+		}
+
+		available := make([]recommend.Candidate, 0, len(foods))
+		for _, food := range foods {
+			if fixedToday[food.FoodId] {
+				continue
+			}
+			if options.MaxRepeatsPerWeek > 0 && weekAppearances[food.FoodId] >= options.MaxRepeatsPerWeek {
+				continue
+			}
+			available = append(available, food)
+		}
+
+		items, err := solveDay(available, portions, day.Total, options, appearances)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			day.Items = append(day.Items, item)
+			day.Total = addMacro(day.Total, item.Portion.Macro, item.Count)
+		}
+
+		seen := make(map[int64]bool)
+		for _, item := range day.Items {
+			if !seen[item.FoodId] {
+				seen[item.FoodId] = true
+				weekAppearances[item.FoodId]++
+				appearances[item.FoodId]++
+			}
+		}
+
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+// solveDay 는 fixed 만큼 이미 먹은 날에 목표를 맞추도록 foods 중에서 먹을 양을 정한다.
+// 변수는 food별 portion 수와 칼로리, 탄단지마다 목표보다 많은 양과 적은 양이다.
+func solveDay(foods []recommend.Candidate, portions map[int64]recommend.Portion, fixed recommend.Macro, options Options,
+	appearances map[int64]int) ([]Item, error) {
+	targets := macroValues(options.Target)
+	fixedValues := macroValues(fixed)
+	weights := [4]float64{weightCalorie, weightMacro, weightMacro, weightMacro}
+
+	n := len(foods) + 2*len(targets)
+	p := problem{cost: make([]float64, n), lower: make([]float64, n), upper: make([]float64, n), integer: make([]bool, n)}
+
+	for j, food := range foods {
+		cost := costPortion + costRepeat*float64(appearances[food.FoodId])
+		if options.Favorites[food.FoodId] {
+			cost -= discountFavorite
+		}
+		p.cost[j] = cost
+		p.upper[j] = maxPortionsPerDay
+		p.integer[j] = true
+	}
+
+	// 칼로리, 탄단지마다 portion 합 - 많은 양 + 적은 양 = 목표 - 이미 먹은 양
+	for m, target := range targets {
+		over, under := len(foods)+2*m, len(foods)+2*m+1
+		scale := math.Max(target, 1)
+		allowed := options.Tolerance * scale
+
+		p.cost[over] = weights[m] / scale
+		p.cost[under] = weights[m] / scale
+		p.upper[over] = allowed
+		p.upper[under] = allowed
+
+		coef := make([]float64, n)
+		for j, food := range foods {
+			coef[j] = macroValues(portions[food.FoodId].Macro)[m]
+		}
+		coef[over] = -1
+		coef[under] = 1
+
+		p.rows = append(p.rows, constraint{coef: coef, kind: equal, rhs: target - fixedValues[m]})
+	}
+
+	x, err := p.solve(maxNodesPerDay)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0)
+	for j, food := range foods {
+		if x[j] > 0.5 {
+			items = append(items, Item{FoodId: food.FoodId, Portion: portions[food.FoodId], Count: x[j]})
+		}
+	}
+
+	return items, nil
+}
+
+// SelectFoods 는 candidates 중 식단에 쓸 food를 중복 없이 limit 개까지 고른다.
+// 꼭 넣어야 하는 food와 즐겨 먹는 food를 먼저 고르고, 그 다음은 최근에 많이 먹은 순서, 나머지는 candidates 순서를 따른다.
+func SelectFoods(candidates []recommend.Candidate, options Options, limit int) []recommend.Candidate {
+	required := make(map[int64]bool, len(options.MustInclude))
+	for _, fixed := range options.MustInclude {
+		required[fixed.FoodId] = true
+	}
+
+	rank := func(candidate recommend.Candidate) int {
+		switch {
+		case required[candidate.FoodId]:
+			return 0
+		case options.Favorites[candidate.FoodId]:
+			return 1
+		default:
+			return 2
+		}
+	}
+
+	selected := make([]recommend.Candidate, len(candidates))
+	copy(selected, candidates)
+	sort.SliceStable(selected, func(i, j int) bool {
+		if rank(selected[i]) != rank(selected[j]) {
+			return rank(selected[i]) < rank(selected[j])
+		}
+		return selected[i].EatCount > selected[j].EatCount
+	})
+
+	// 꼭 넣어야 하는 food는 limit 을 넘어도 뺄 수 없다.
+	seen := make(map[int64]bool, len(selected))
+	result := make([]recommend.Candidate, 0, limit)
+	for _, candidate := range selected {
+		if seen[candidate.FoodId] || (len(result) >= limit && !required[candidate.FoodId]) {
+			continue
+		}
+		seen[candidate.FoodId] = true
+		result = append(result, candidate)
+	}
+
+	return result
+}
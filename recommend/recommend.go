@@ -0,0 +1,172 @@
+// Package recommend 는 하루에 남은 칼로리와 탄단지 안에서 먹을 수 있는 food와 그 양을 골라 순위를 매긴다.
+// 먹고 싶은 것을 먹으면서도 목표를 지킬 수 있도록, 남은 양에 맞는 정도와 그 이유를 함께 돌려준다.
+package recommend
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// 한 끼로 먹기 적당한 최대 칼로리. 남은 칼로리가 많아도 한 번에 이보다 많이 먹는 양은 낮게 평가한다.
+const mealCalorie = 700.0
+
+// 최근에 먹은 횟수가 이 이상이면 익숙한 정도를 모두 채운 것으로 본다.
+const familiarEatCount = 10
+
+// 점수 항목의 가중치
+const (
+	weightFill     = 1.0
+	weightProtein  = 1.0
+	weightOver     = 1.0
+	weightFamiliar = 0.3
+)
+
+type Macro struct {
+	Calorie      float64 `json:"Calorie"`
+	Carbohydrate float64 `json:"Carbohydrate"`
+	Protein      float64 `json:"Protein"`
+	Fat          float64 `json:"Fat"`
+}
+
+// Portion 은 food를 먹는 양 하나이다. serving size가 아니면 ServingSizeId 는 0이다.
+type Portion struct {
+	Label         string  `json:"Label"`
+	ServingSizeId int64   `json:"ServingSizeId"`
+	Quantity      float64 `json:"Quantity"` // nutrient Unit 기준 양
+	Macro         Macro   `json:"Macro"`
+}
+
+type Candidate struct {
+	FoodId       int64
+	Name         string
+	BrandName    string
+	CategoryName string
+	Portions     []Portion
+	// 최근에 먹은 횟수
+	EatCount int64
+}
+
+type Recommendation struct {
+	FoodId       int64    `json:"FoodId"`
+	Name         string   `json:"Name"`
+	BrandName    string   `json:"BrandName"`
+	CategoryName string   `json:"CategoryName"`
+	Portion      Portion  `json:"Portion"`
+	Score        float64  `json:"Score"`
+	Reasons      []string `json:"Reasons"`
+}
+
+// Rank 는 food마다 budget 안에 들어가는 가장 좋은 양을 골라서 점수가 높은 순서로 limit 개까지 돌려준다.
+// budget 안에 들어가는 양이 없는 food는 빠진다.
+func Rank(budget Macro, candidates []Candidate, limit int) []Recommendation {
+	recommendations := make([]Recommendation, 0)
+	if budget.Calorie <= 0 {
+		return recommendations
+	}
+
+	for _, candidate := range candidates {
+		best, bestScore, found := Portion{}, 0.0, false
+		for _, portion := range candidate.Portions {
+			if !fits(budget, portion) {
+				continue
+			}
+
+			if score := Score(budget, portion, candidate.EatCount); !found || score > bestScore {
+				best, bestScore, found = portion, score, true
+			}
+		}
+
+		if !found {
+			continue
+		}
+
+		recommendations = append(recommendations, Recommendation{FoodId: candidate.FoodId, Name: candidate.Name,
+			BrandName: candidate.BrandName, CategoryName: candidate.CategoryName, Portion: best, Score: bestScore,
+			Reasons: Explain(budget, best, candidate.EatCount)})
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].Score != recommendations[j].Score {
+			return recommendations[i].Score > recommendations[j].Score
+		}
+		return recommendations[i].FoodId < recommendations[j].FoodId
+	})
+
+	if limit > 0 && len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+
+	return recommendations
+}
+
+func fits(budget Macro, portion Portion) bool {
+	return portion.Macro.Calorie > 0 && portion.Macro.Calorie <= budget.Calorie
+}
+
+// fill 은 한 끼로 적당한 칼로리에 portion이 얼마나 가까운지를 0~1 로 나타낸다.
+func fill(budget Macro, portion Portion) float64 {
+	ideal := math.Min(budget.Calorie, mealCalorie)
+	return math.Max(0, 1-math.Abs(1-portion.Macro.Calorie/ideal))
+}
+
+// proteinShare 는 남은 단백질 중 portion이 채우는 비율이다. 단백질이 남지 않았으면 0이다.
+func proteinShare(budget Macro, portion Portion) float64 {
+	if budget.Protein <= 0 {
+		return 0
+	}
+
+	return math.Min(portion.Macro.Protein/budget.Protein, 1)
+}
+
+// over 는 amount 중 남은 양 remaining 을 넘는 비율이다.
+func over(remaining, amount float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+
+	return math.Max(0, amount-math.Max(remaining, 0)) / amount
+}
+
+// Score 는 portion이 한 끼로 알맞은 양인지, 남은 단백질을 채우는지, 탄수화물과 지방이 남은 양을 넘지 않는지,
+// 자주 먹는 음식인지를 더해서 계산한다.
+func Score(budget Macro, portion Portion, eatCount int64) float64 {
+	familiar := math.Min(float64(eatCount), familiarEatCount) / familiarEatCount
+
+	return weightFill*fill(budget, portion) +
+		weightProtein*proteinShare(budget, portion) -
+		weightOver*over(budget.Carbohydrate, portion.Macro.Carbohydrate) -
+		weightOver*over(budget.Fat, portion.Macro.Fat) +
+		weightFamiliar*familiar
+}
+
+// Explain 은 portion을 추천하는 이유를 사람이 읽을 수 있는 문장으로 만든다.
+func Explain(budget Macro, portion Portion, eatCount int64) []string {
+	macro := portion.Macro
+	reasons := []string{
+		fmt.Sprintf("%s에 %.0fkcal로, 남은 %.0fkcal의 %.0f%%입니다.",
+			portion.Label, macro.Calorie, budget.Calorie, macro.Calorie/budget.Calorie*100),
+	}
+
+	if share := proteinShare(budget, portion); share >= 0.2 {
+		reasons = append(reasons, fmt.Sprintf("단백질 %.1fg으로 남은 단백질의 %.0f%%를 채웁니다.", macro.Protein, share*100))
+	}
+
+	carbohydrateOver := macro.Carbohydrate - math.Max(budget.Carbohydrate, 0)
+	fatOver := macro.Fat - math.Max(budget.Fat, 0)
+	if carbohydrateOver > 0 {
+		reasons = append(reasons, fmt.Sprintf("탄수화물이 남은 양보다 %.1fg 많습니다.", carbohydrateOver))
+	}
+	if fatOver > 0 {
+		reasons = append(reasons, fmt.Sprintf("지방이 남은 양보다 %.1fg 많습니다.", fatOver))
+	}
+	if carbohydrateOver <= 0 && fatOver <= 0 {
+		reasons = append(reasons, "탄수화물과 지방이 남은 양을 넘지 않습니다.")
+	}
+
+	if eatCount > 0 {
+		reasons = append(reasons, fmt.Sprintf("최근에 %d번 먹은 음식입니다.", eatCount))
+	}
+
+	return reasons
+}
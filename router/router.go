@@ -2,6 +2,7 @@ package router
 
 import (
 	"github.com/kernelgarden/diet/controllers"
+	"github.com/kernelgarden/diet/models"
 	"github.com/labstack/echo"
 	"github.com/pangpanglabs/echoswagger"
 )
@@ -14,8 +15,25 @@ func InitRoutes(e *echo.Echo) {
 		Description: "프로그래머는 다이어트를 어떻게 하는가에 대한 API 문서이다.",
 		Version:     "0.1",
 	})
+	r.AddSecurityAPIKey("Authorization", "로그인 시 발급받은 토큰 (Bearer {token})", echoswagger.SecurityInHeader)
 
-	controllers.BrandApiController{}.Init(r.Group("Brand", "/api/brands"))
-	controllers.CategoryApiController{}.Init(r.Group("Category", "/api/categories"))
-	controllers.FoodApiController{}.Init(r.Group("Food", "/api/foods"))
+	controllers.UserApiController{}.Init(r.Group("User", "/api/users"))
+	// 카탈로그는 누구나 조회할 수 있지만, 변경은 신뢰할 수 있는 user만 가능하다.
+	catalog := controllers.Permission{Write: models.RoleContributor, Delete: models.RoleModerator}
+
+	controllers.BrandApiController{Permission: catalog}.Init(r.Group("Brand", "/api/brands"))
+	controllers.CategoryApiController{Permission: catalog}.Init(r.Group("Category", "/api/categories"))
+	controllers.FoodApiController{Permission: catalog}.Init(r.Group("Food", "/api/foods"))
+	controllers.ActivityApiController{Permission: catalog}.Init(r.Group("Activity", "/api/activities"))
+
+	controllers.ProfileApiController{}.Init(r.Group("Profile", "/api/profile"))
+	controllers.BodyApiController{}.Init(r.Group("Body", "/api/body"))
+	controllers.MealApiController{Permission: catalog}.Init(r.Group("Meal", "/api/meals"))
+	controllers.SummaryApiController{}.Init(r.Group("Summary", "/api/summary"))
+	controllers.PlanApiController{}.Init(r.Group("Plan", "/api/plans"))
+	controllers.RecipeApiController{Permission: catalog}.Init(r.Group("Recipe", "/api/recipes"))
+	controllers.RecommendationApiController{}.Init(r.Group("Recommendation", "/api/recommendations"))
+	controllers.DrinkingApiController{}.Init(r.Group("Drinking", "/api/drinking-sessions"))
+	controllers.ActivityEntryApiController{}.Init(r.Group("ActivityEntry", "/api/activity-entries"))
+	controllers.HealthApiController{}.Init(r.Group("Health", "/api/health"))
 }
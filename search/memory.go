@@ -0,0 +1,58 @@
+package search
+
+import "sync"
+
+// MemoryIndex 는 프로세스 메모리에 document를 두는 색인이다.
+// 서버를 여러 대 띄우면 서버마다 색인이 따로 생기므로 SQLIndex 를 쓴다.
+type MemoryIndex struct {
+	mutex sync.RWMutex
+	docs  map[int64]analyzed
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{docs: make(map[int64]analyzed)}
+}
+
+func (m *MemoryIndex) Put(docs ...Document) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, doc := range docs {
+		m.docs[doc.Id] = analyze(doc)
+	}
+
+	return nil
+}
+
+func (m *MemoryIndex) Remove(id int64) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.docs, id)
+
+	return nil
+}
+
+func (m *MemoryIndex) Search(query Query) (*Result, error) {
+	m.mutex.RLock()
+	docs := make([]analyzed, 0, len(m.docs))
+	for _, doc := range m.docs {
+		docs = append(docs, doc)
+	}
+	m.mutex.RUnlock()
+
+	return rank(docs, query), nil
+}
+
+func (m *MemoryIndex) Rebuild(docs []Document) error {
+	newDocs := make(map[int64]analyzed, len(docs))
+	for _, doc := range docs {
+		newDocs[doc.Id] = analyze(doc)
+	}
+
+	m.mutex.Lock()
+	m.docs = newDocs
+	m.mutex.Unlock()
+
+	return nil
+}
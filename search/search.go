@@ -0,0 +1,288 @@
+// Package search 는 food 검색에 쓰는 색인과 검색 순위를 정의한다.
+// 색인은 Index 를 구현하는 저장소(메모리, SQL)를 바꿔 끼울 수 있고,
+// 어떤 저장소를 쓰더라도 순위는 Score 로 똑같이 계산한다.
+// 검색어는 tokenizer 로 나눠서 초성 검색, 입력 중인 글자, 자모 단위 오타를 허용한다.
+package search
+
+import (
+	"github.com/kernelgarden/diet/tokenizer"
+	"sort"
+	"strings"
+)
+
+// Document 는 food 하나를 검색하는 데 필요한 정보이다.
+type Document struct {
+	Id           int64   `json:"Id" xorm:"pk"`
+	Name         string  `json:"Name" xorm:"varchar(64)"`
+	BrandId      int64   `json:"BrandId" xorm:"index"`
+	BrandName    string  `json:"BrandName" xorm:"varchar(64)"`
+	CategoryId   int64   `json:"CategoryId" xorm:"index"`
+	CategoryName string  `json:"CategoryName" xorm:"varchar(64)"`
+	Calorie      float64 `json:"Calorie"` // nutrient Unit 기준 100 당 칼로리(kcal)
+
+	// SQLIndex 가 LIKE 로 후보를 찾을 때 쓰는 이름의 초성과 자모. Put 할 때 채운다.
+	// 겹받침과 이중 모음도 나누므로 음절 하나가 자모 5개까지 되어서 이름 길이(64)의 5배이다.
+	NameChosung string `json:"-" xorm:"varchar(64)"`
+	NameJamo    string `json:"-" xorm:"varchar(320)"`
+}
+
+func (Document) TableName() string {
+	return "search_document"
+}
+
+// Query 의 0 인 조건은 적용하지 않는다.
+type Query struct {
+	Text       string
+	CategoryId int64
+	BrandId    int64
+	MinCalorie float64
+	MaxCalorie float64
+	Offset     int
+	Limit      int
+}
+
+type Hit struct {
+	Id    int64   `json:"Id"`
+	Score float64 `json:"Score"`
+}
+
+type Result struct {
+	Total int   `json:"Total"`
+	Hits  []Hit `json:"Hits"`
+}
+
+type Index interface {
+	// Put 은 같은 Id 의 document가 있으면 새 document로 바꾼다.
+	Put(docs ...Document) error
+	Remove(id int64) error
+	Search(query Query) (*Result, error)
+	// Rebuild 는 색인을 비우고 docs 로 다시 만든다.
+	Rebuild(docs []Document) error
+}
+
+// PersistentIndex 는 서버를 다시 시작해도 남아 있는 색인이다.
+type PersistentIndex interface {
+	Index
+	// IsCurrent 는 색인이 채워져 있고 지금의 Version 으로 만든 것인지 확인한다.
+	IsCurrent() (bool, error)
+}
+
+// Version 은 Document 를 분석하는 방법이 바뀌면 올려서 저장된 색인을 다시 만들게 한다.
+const Version = 1
+
+// Terms 는 검색어를 tokenizer 로 나눈 소문자 단어들이다.
+func Terms(text string) []string {
+	return tokenizer.Tokenize(text)
+}
+
+// Filter 는 검색어를 제외한 조건에 doc 이 맞는지 확인한다.
+func (q Query) Filter(doc Document) bool {
+	if q.CategoryId != 0 && doc.CategoryId != q.CategoryId {
+		return false
+	}
+	if q.BrandId != 0 && doc.BrandId != q.BrandId {
+		return false
+	}
+	if q.MinCalorie != 0 && doc.Calorie < q.MinCalorie {
+		return false
+	}
+	if q.MaxCalorie != 0 && doc.Calorie > q.MaxCalorie {
+		return false
+	}
+
+	return true
+}
+
+// 검색어가 어디에 어떻게 맞았는지에 따른 점수
+const (
+	scoreNameExact    = 10
+	scoreNamePrefix   = 6
+	scoreWordPrefix   = 4
+	scoreNameContains = 3
+	scoreFuzzy        = 2
+	scoreBrand        = 2
+	scoreCategory     = 1
+)
+
+// analyzed 는 순위를 매길 때마다 다시 계산하지 않도록 document의 이름을 미리 나눠 둔 것이다.
+type analyzed struct {
+	doc      Document
+	name     string
+	nameJamo string
+	// 띄어쓰기 없이 이어 붙인 이름의 초성
+	chosung      string
+	wordJamo     []string
+	wordChosung  []string
+	brand        string
+	brandJamo    string
+	category     string
+	categoryJamo string
+}
+
+func analyze(doc Document) analyzed {
+	words := tokenizer.Tokenize(doc.Name)
+
+	a := analyzed{doc: doc, name: strings.Join(words, " "), wordJamo: make([]string, len(words)),
+		wordChosung: make([]string, len(words))}
+	a.nameJamo = tokenizer.Decompose(a.name)
+	for idx, word := range words {
+		a.wordJamo[idx] = tokenizer.Decompose(word)
+		a.wordChosung[idx] = tokenizer.Chosung(word)
+	}
+	a.chosung = strings.Join(a.wordChosung, "")
+
+	a.brand = tokenizer.Normalize(doc.BrandName)
+	a.brandJamo = tokenizer.Decompose(a.brand)
+	a.category = tokenizer.Normalize(doc.CategoryName)
+	a.categoryJamo = tokenizer.Decompose(a.category)
+
+	return a
+}
+
+// Score 는 doc 이 terms 에 얼마나 맞는지 계산한다.
+// 하나라도 맞지 않는 term 이 있으면 0 을 돌려주고, terms 가 없으면 모든 doc 이 1 로 맞는다.
+func Score(doc Document, terms []string) float64 {
+	return analyze(doc).score(terms)
+}
+
+func (a analyzed) score(terms []string) float64 {
+	if len(terms) == 0 {
+		return 1
+	}
+
+	var total float64
+	for _, term := range terms {
+		var score float64
+		if tokenizer.IsChosung(term) {
+			score = a.chosungScore(term)
+		} else {
+			termJamo := tokenizer.Decompose(term)
+
+			score = a.nameScore(term, termJamo)
+			if strings.Contains(a.brandJamo, termJamo) {
+				score += scoreBrand
+			}
+			if strings.Contains(a.categoryJamo, termJamo) {
+				score += scoreCategory
+			}
+		}
+
+		if score == 0 {
+			return 0
+		}
+		total += score
+	}
+
+	if strings.Join(terms, " ") == a.name {
+		total += scoreNameExact
+	}
+
+	return total
+}
+
+// 이름은 자모 단위로 비교해서 "닭가ㅅ" 처럼 입력 중인 글자도 맞도록 한다.
+func (a analyzed) nameScore(term, termJamo string) float64 {
+	switch {
+	case a.name == term:
+		return scoreNameExact
+	case strings.HasPrefix(a.nameJamo, termJamo):
+		return scoreNamePrefix
+	}
+
+	for _, word := range a.wordJamo {
+		if strings.HasPrefix(word, termJamo) {
+			return scoreWordPrefix
+		}
+	}
+
+	if strings.Contains(a.nameJamo, termJamo) {
+		return scoreNameContains
+	}
+
+	for _, word := range a.wordJamo {
+		if tokenizer.FuzzyMatch(termJamo, word) {
+			return scoreFuzzy
+		}
+	}
+
+	return 0
+}
+
+// 초성 검색어는 이름의 초성에만 맞춰 본다. ("ㄷㄱㅅ" -> 닭가슴살)
+func (a analyzed) chosungScore(term string) float64 {
+	term = strings.Join(strings.Fields(term), "")
+
+	switch {
+	case a.chosung == term:
+		return scoreNameExact
+	case strings.HasPrefix(a.chosung, term):
+		return scoreNamePrefix
+	}
+
+	for _, word := range a.wordChosung {
+		if strings.HasPrefix(word, term) {
+			return scoreWordPrefix
+		}
+	}
+
+	if strings.Contains(a.chosung, term) {
+		return scoreNameContains
+	}
+
+	return 0
+}
+
+// Rank 는 docs 중 query 에 맞는 것을 점수가 높은 순서로 정렬하고 query 의 페이지만 돌려준다.
+func Rank(docs []Document, query Query) *Result {
+	analyzedDocs := make([]analyzed, len(docs))
+	for idx, doc := range docs {
+		analyzedDocs[idx] = analyze(doc)
+	}
+
+	return rank(analyzedDocs, query)
+}
+
+// 점수가 같으면 이름이 짧은 것, 그 다음은 Id 순서이다.
+func rank(docs []analyzed, query Query) *Result {
+	terms := Terms(query.Text)
+
+	type ranked struct {
+		doc   Document
+		score float64
+	}
+
+	matched := make([]ranked, 0)
+	for _, a := range docs {
+		if !query.Filter(a.doc) {
+			continue
+		}
+
+		if score := a.score(terms); score > 0 {
+			matched = append(matched, ranked{doc: a.doc, score: score})
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].score != matched[j].score {
+			return matched[i].score > matched[j].score
+		}
+		if len(matched[i].doc.Name) != len(matched[j].doc.Name) {
+			return len(matched[i].doc.Name) < len(matched[j].doc.Name)
+		}
+		return matched[i].doc.Id < matched[j].doc.Id
+	})
+
+	from, to := query.Offset, len(matched)
+	if from < 0 {
+		from = 0
+	}
+	if query.Limit > 0 && from+query.Limit < to {
+		to = from + query.Limit
+	}
+
+	result := Result{Total: len(matched), Hits: make([]Hit, 0)}
+	for idx := from; idx < to; idx++ {
+		result.Hits = append(result.Hits, Hit{Id: matched[idx].doc.Id, Score: matched[idx].score})
+	}
+
+	return &result
+}
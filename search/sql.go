@@ -0,0 +1,196 @@
+package search
+
+import (
+	"github.com/go-xorm/xorm"
+	"github.com/kernelgarden/diet/tokenizer"
+	"strings"
+)
+
+// SQLIndex 는 search_document 테이블에 document를 두는 색인이다.
+// LIKE 로 후보를 줄인 뒤 순위는 MemoryIndex 와 같은 Rank 로 매긴다.
+// 후보는 자모나 초성이 맞는 것으로 넓게 찾으므로 초성이 맞는 오타까지는 찾지만,
+// 초성 자체를 틀린 오타는 MemoryIndex 와 달리 찾지 못한다.
+type SQLIndex struct {
+	db *xorm.Engine
+}
+
+func NewSQLIndex(db *xorm.Engine) *SQLIndex {
+	return &SQLIndex{db: db}
+}
+
+// IndexVersion 은 search_document 를 만든 Version 이다. 행은 하나만 둔다.
+type IndexVersion struct {
+	Id      int64 `xorm:"pk"`
+	Version int
+}
+
+func (IndexVersion) TableName() string {
+	return "search_index_version"
+}
+
+const indexVersionId = 1
+
+func (s *SQLIndex) IsCurrent() (bool, error) {
+	var version IndexVersion
+	if has, err := s.db.ID(indexVersionId).Get(&version); err != nil || !has || version.Version != Version {
+		return false, err
+	}
+
+	count, err := s.db.Count(&Document{})
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func fillAnalyzed(doc *Document) {
+	doc.NameChosung = strings.Replace(tokenizer.Chosung(tokenizer.Normalize(doc.Name)), " ", "", -1)
+	doc.NameJamo = tokenizer.Decompose(tokenizer.Normalize(doc.Name))
+}
+
+func (s *SQLIndex) Put(docs ...Document) error {
+	for idx := range docs {
+		fillAnalyzed(&docs[idx])
+
+		if _, err := s.db.ID(docs[idx].Id).Delete(&Document{}); err != nil {
+			return err
+		}
+
+		if _, err := s.db.Insert(&docs[idx]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLIndex) Remove(id int64) error {
+	_, err := s.db.ID(id).Delete(&Document{})
+	return err
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// 검색어가 있으면 순위를 매길 후보를 이만큼까지만 읽는다.
+// 점수가 같으면 이름이 짧은 것이 앞서므로 이름이 짧은 것부터 읽는다.
+const maxCandidates = 1000
+
+// 점수가 같을 때 Rank 와 같은 순서. Go 의 len 처럼 byte 길이로 비교한다.
+const rankOrder = "LENGTH(name), id"
+
+// where 는 query 의 조건에 맞는 document를 찾는 session 이다.
+func (s *SQLIndex) where(query Query, terms []string) *xorm.Session {
+	session := s.db.Where("1 = 1")
+	if query.CategoryId != 0 {
+		session = session.And("category_id = ?", query.CategoryId)
+	}
+	if query.BrandId != 0 {
+		session = session.And("brand_id = ?", query.BrandId)
+	}
+	if query.MinCalorie != 0 {
+		session = session.And("calorie >= ?", query.MinCalorie)
+	}
+	if query.MaxCalorie != 0 {
+		session = session.And("calorie <= ?", query.MaxCalorie)
+	}
+	for _, term := range terms {
+		like := "%" + likeEscaper.Replace(term) + "%"
+		if tokenizer.IsChosung(term) {
+			session = session.And("name_chosung LIKE ?", like)
+			continue
+		}
+
+		jamoLike := "%" + likeEscaper.Replace(tokenizer.Decompose(term)) + "%"
+		chosungLike := "%" + likeEscaper.Replace(tokenizer.Chosung(term)) + "%"
+		session = session.And("(name_jamo LIKE ? OR name_chosung LIKE ? OR LOWER(brand_name) LIKE ? OR LOWER(category_name) LIKE ?)",
+			jamoLike, chosungLike, like, like)
+	}
+
+	return session
+}
+
+// Search 는 검색어가 없으면 모든 document의 점수가 같으므로 페이지를 DB 에서 바로 자른다.
+// 검색어가 있으면 후보를 maxCandidates 개까지 읽어서 순위를 매기고,
+// 후보가 더 많으면 Total 은 조건에 맞는 후보의 수이다.
+func (s *SQLIndex) Search(query Query) (*Result, error) {
+	terms := Terms(query.Text)
+
+	total, err := s.where(query, terms).Count(&Document{})
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]Document, 0)
+	if len(terms) == 0 {
+		offset := query.Offset
+		if offset < 0 {
+			offset = 0
+		}
+
+		session := s.where(query, terms).OrderBy(rankOrder)
+		if query.Limit > 0 {
+			session = session.Limit(query.Limit, offset)
+		} else if offset > 0 {
+			// LIMIT 없이 OFFSET 만 쓸 수는 없다.
+			session = session.Limit(int(total), offset)
+		}
+		if err := session.Find(&docs); err != nil {
+			return nil, err
+		}
+
+		result := Result{Total: int(total), Hits: make([]Hit, 0, len(docs))}
+		for _, doc := range docs {
+			result.Hits = append(result.Hits, Hit{Id: doc.Id, Score: 1})
+		}
+
+		return &result, nil
+	}
+
+	if err := s.where(query, terms).OrderBy(rankOrder).Limit(maxCandidates).Find(&docs); err != nil {
+		return nil, err
+	}
+
+	result := Rank(docs, query)
+	if total > maxCandidates {
+		result.Total = int(total)
+	}
+
+	return result, nil
+}
+
+// Rebuild 는 색인을 지우고 다시 채우는 동안 검색 결과가 비거나, 중간에 실패해서 일부만 남지 않도록
+// 하나의 transaction 안에서 바꾼다.
+func (s *SQLIndex) Rebuild(docs []Document) error {
+	session := s.db.NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := session.Where("1 = 1").Delete(&Document{}); err != nil {
+		session.Rollback()
+		return err
+	}
+
+	for idx := range docs {
+		fillAnalyzed(&docs[idx])
+
+		if _, err := session.Insert(&docs[idx]); err != nil {
+			session.Rollback()
+			return err
+		}
+	}
+
+	if _, err := session.ID(indexVersionId).Delete(&IndexVersion{}); err != nil {
+		session.Rollback()
+		return err
+	}
+	if _, err := session.Insert(&IndexVersion{Id: indexVersionId, Version: Version}); err != nil {
+		session.Rollback()
+		return err
+	}
+
+	return session.Commit()
+}
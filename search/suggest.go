@@ -0,0 +1,210 @@
+package search
+
+import (
+	"github.com/kernelgarden/diet/tokenizer"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 이름의 어디에서 prefix 가 맞았는지. 값이 클수록 앞에 보여준다.
+const (
+	matchWord = iota + 1
+	matchName
+)
+
+type trieNode struct {
+	children map[rune]*trieNode
+	// 이 node 까지의 prefix 로 찾을 수 있는 food와 가장 좋은 match 종류
+	matches map[int64]int
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[rune]*trieNode), matches: make(map[int64]int)}
+}
+
+// Boost 는 user가 food를 얼마나 자주, 최근에 먹었는지이다.
+type Boost struct {
+	EatCount    int64
+	LastEatenAt time.Time
+}
+
+type Suggestion struct {
+	Id           int64   `json:"Id"`
+	Name         string  `json:"Name"`
+	BrandName    string  `json:"BrandName"`
+	CategoryName string  `json:"CategoryName"`
+	Calorie      float64 `json:"Calorie"`
+	EatCount     int64   `json:"EatCount"`
+}
+
+// Suggester 는 food 이름의 자모와 초성을 prefix trie 로 만들어 입력 중인 글자로 food를 찾는다.
+// 이름 전체와 각 단어의 시작을 모두 넣어서 "스테" 로도 "닭가슴살 스테이크" 를 찾을 수 있다.
+type Suggester struct {
+	mutex sync.RWMutex
+	root  *trieNode
+	docs  map[int64]Document
+	keys  map[int64][]string
+}
+
+func NewSuggester() *Suggester {
+	return &Suggester{root: newTrieNode(), docs: make(map[int64]Document), keys: make(map[int64][]string)}
+}
+
+// suggestKeys 는 doc 을 찾을 수 있는 trie key와 match 종류이다.
+func suggestKeys(doc Document) map[string]int {
+	words := tokenizer.Tokenize(doc.Name)
+	keys := make(map[string]int)
+
+	keys[tokenizer.Decompose(strings.Join(words, " "))] = matchName
+	keys[tokenizer.Chosung(strings.Join(words, ""))] = matchName
+	for idx := 1; idx < len(words); idx++ {
+		rest := words[idx:]
+		for _, key := range []string{tokenizer.Decompose(strings.Join(rest, " ")), tokenizer.Chosung(strings.Join(rest, ""))} {
+			if _, ok := keys[key]; !ok {
+				keys[key] = matchWord
+			}
+		}
+	}
+
+	return keys
+}
+
+func (s *Suggester) put(doc Document) {
+	s.remove(doc.Id)
+
+	keys := suggestKeys(doc)
+	for key, match := range keys {
+		node := s.root
+		for _, r := range key {
+			child, ok := node.children[r]
+			if !ok {
+				child = newTrieNode()
+				node.children[r] = child
+			}
+			node = child
+
+			if node.matches[doc.Id] < match {
+				node.matches[doc.Id] = match
+			}
+		}
+
+		s.keys[doc.Id] = append(s.keys[doc.Id], key)
+	}
+	s.docs[doc.Id] = doc
+}
+
+func (s *Suggester) remove(id int64) {
+	for _, key := range s.keys[id] {
+		node := s.root
+		for _, r := range key {
+			child, ok := node.children[r]
+			if !ok {
+				break
+			}
+
+			delete(child.matches, id)
+			if len(child.matches) == 0 {
+				// 이 아래로는 다른 food가 없다.
+				delete(node.children, r)
+				break
+			}
+			node = child
+		}
+	}
+
+	delete(s.keys, id)
+	delete(s.docs, id)
+}
+
+func (s *Suggester) Put(docs ...Document) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, doc := range docs {
+		s.put(doc)
+	}
+}
+
+func (s *Suggester) Remove(id int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.remove(id)
+}
+
+func (s *Suggester) Rebuild(docs []Document) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.root = newTrieNode()
+	s.docs = make(map[int64]Document, len(docs))
+	s.keys = make(map[int64][]string, len(docs))
+	for _, doc := range docs {
+		s.put(doc)
+	}
+}
+
+// Suggest 는 prefix 로 시작하는 food를 최대 limit 개 돌려준다.
+// boosts 에 있는 food(user가 먹은 적 있는 food)를 먼저, 자주 먹은 순서로 보여주고
+// 나머지는 이름 전체가 prefix 로 시작하는 것, 이름이 짧은 것 순서로 보여준다.
+func (s *Suggester) Suggest(prefix string, limit int, boosts map[int64]Boost) []Suggestion {
+	var key string
+	if tokenizer.IsChosung(prefix) {
+		key = strings.Join(strings.Fields(prefix), "")
+	} else {
+		key = tokenizer.Decompose(tokenizer.Normalize(prefix))
+	}
+
+	suggestions := make([]Suggestion, 0)
+	if key == "" {
+		return suggestions
+	}
+
+	type candidate struct {
+		doc   Document
+		match int
+		boost Boost
+	}
+
+	s.mutex.RLock()
+	node := s.root
+	for _, r := range key {
+		if node = node.children[r]; node == nil {
+			break
+		}
+	}
+
+	candidates := make([]candidate, 0)
+	if node != nil {
+		for id, match := range node.matches {
+			candidates = append(candidates, candidate{doc: s.docs[id], match: match, boost: boosts[id]})
+		}
+	}
+	s.mutex.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch {
+		case a.boost.EatCount != b.boost.EatCount:
+			return a.boost.EatCount > b.boost.EatCount
+		case !a.boost.LastEatenAt.Equal(b.boost.LastEatenAt):
+			return a.boost.LastEatenAt.After(b.boost.LastEatenAt)
+		case a.match != b.match:
+			return a.match > b.match
+		case len(a.doc.Name) != len(b.doc.Name):
+			return len(a.doc.Name) < len(b.doc.Name)
+		default:
+			return a.doc.Id < b.doc.Id
+		}
+	})
+
+	for idx := 0; idx < len(candidates) && idx < limit; idx++ {
+		doc := candidates[idx].doc
+		suggestions = append(suggestions, Suggestion{Id: doc.Id, Name: doc.Name, BrandName: doc.BrandName,
+			CategoryName: doc.CategoryName, Calorie: doc.Calorie, EatCount: candidates[idx].boost.EatCount})
+	}
+
+	return suggestions
+}
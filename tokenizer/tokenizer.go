@@ -0,0 +1,218 @@
+// Package tokenizer 는 한글과 영문이 섞인 food 이름을 검색하기 위해
+// 단어 나누기, 자모 분해, 초성 추출, 자모 단위 편집 거리를 제공한다.
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	syllableBase = 0xAC00
+	syllableLast = 0xD7A3
+	jungCount    = 21
+	jongCount    = 28
+)
+
+// 한글 음절을 이루는 초성, 중성, 종성을 호환용 자모로 나타낸 것
+var (
+	choJamo  = []rune("ㄱㄲㄴㄷㄸㄹㅁㅂㅃㅅㅆㅇㅈㅉㅊㅋㅌㅍㅎ")
+	jungJamo = []rune("ㅏㅐㅑㅒㅓㅔㅕㅖㅗㅘㅙㅚㅛㅜㅝㅞㅟㅠㅡㅢㅣ")
+	jongJamo = []rune(" ㄱㄲㄳㄴㄵㄶㄷㄹㄺㄻㄼㄽㄾㄿㅀㅁㅂㅄㅅㅆㅇㅈㅊㅋㅌㅍㅎ")
+)
+
+// 겹받침과 이중 모음은 두벌식 자판에서 두 번 누르므로 나눠서 비교해야 오타 하나가 편집 한 번이 된다.
+var compoundJamo = map[rune][]rune{
+	'ㄳ': []rune("ㄱㅅ"), 'ㄵ': []rune("ㄴㅈ"), 'ㄶ': []rune("ㄴㅎ"), 'ㄺ': []rune("ㄹㄱ"),
+	'ㄻ': []rune("ㄹㅁ"), 'ㄼ': []rune("ㄹㅂ"), 'ㄽ': []rune("ㄹㅅ"), 'ㄾ': []rune("ㄹㅌ"),
+	'ㄿ': []rune("ㄹㅍ"), 'ㅀ': []rune("ㄹㅎ"), 'ㅄ': []rune("ㅂㅅ"),
+	'ㅘ': []rune("ㅗㅏ"), 'ㅙ': []rune("ㅗㅐ"), 'ㅚ': []rune("ㅗㅣ"), 'ㅝ': []rune("ㅜㅓ"),
+	'ㅞ': []rune("ㅜㅔ"), 'ㅟ': []rune("ㅜㅣ"), 'ㅢ': []rune("ㅡㅣ"),
+}
+
+func isSyllable(r rune) bool {
+	return r >= syllableBase && r <= syllableLast
+}
+
+func isJamo(r rune) bool {
+	return r >= 'ㄱ' && r <= 'ㅣ'
+}
+
+func isHangul(r rune) bool {
+	return isSyllable(r) || isJamo(r)
+}
+
+// IsConsonant 는 r 이 호환용 자음(ㄱ~ㅎ)인지 확인한다.
+func IsConsonant(r rune) bool {
+	return r >= 'ㄱ' && r <= 'ㅎ'
+}
+
+// IsChosung 은 s 가 "ㄷㄱㅅ" 처럼 자음만으로 이루어진 초성 검색어인지 확인한다.
+func IsChosung(s string) bool {
+	hasConsonant := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			continue
+		} else if !IsConsonant(r) {
+			return false
+		}
+		hasConsonant = true
+	}
+
+	return hasConsonant
+}
+
+func appendJamo(jamo []rune, r rune) []rune {
+	if parts, ok := compoundJamo[r]; ok {
+		return append(jamo, parts...)
+	}
+
+	return append(jamo, r)
+}
+
+// Decompose 는 한글 음절을 자모로 나누고 나머지 글자는 소문자로 바꾼다.
+// 겹받침과 이중 모음도 나눈다. ("닭" -> "ㄷㅏㄹㄱ")
+func Decompose(s string) string {
+	jamo := make([]rune, 0, len(s))
+	for _, r := range strings.ToLower(s) {
+		if !isSyllable(r) {
+			jamo = appendJamo(jamo, r)
+			continue
+		}
+
+		idx := r - syllableBase
+		jamo = append(jamo, choJamo[idx/(jungCount*jongCount)])
+		jamo = appendJamo(jamo, jungJamo[idx%(jungCount*jongCount)/jongCount])
+		if jong := idx % jongCount; jong != 0 {
+			jamo = appendJamo(jamo, jongJamo[jong])
+		}
+	}
+
+	return string(jamo)
+}
+
+// Chosung 은 한글 음절을 초성으로 바꾸고 나머지 글자는 소문자로 남긴다. ("닭가슴살" -> "ㄷㄱㅅㅅ")
+func Chosung(s string) string {
+	chosung := make([]rune, 0, len(s))
+	for _, r := range strings.ToLower(s) {
+		if isSyllable(r) {
+			r = choJamo[(r-syllableBase)/(jungCount*jongCount)]
+		}
+		chosung = append(chosung, r)
+	}
+
+	return string(chosung)
+}
+
+// Tokenize 는 s 를 소문자 단어들로 나눈다.
+// 글자나 숫자가 아닌 것에서 나누고, 한글과 영문/숫자가 붙어 있으면 그 사이에서도 나눈다.
+// ("닭가슴살Chicken-200g" -> ["닭가슴살", "chicken", "200g"])
+func Tokenize(s string) []string {
+	tokens := make([]string, 0)
+	token := make([]rune, 0)
+	flush := func() {
+		if len(token) > 0 {
+			tokens = append(tokens, string(token))
+			token = token[:0]
+		}
+	}
+
+	for _, r := range strings.ToLower(s) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+
+		if len(token) > 0 && isHangul(token[len(token)-1]) != isHangul(r) {
+			flush()
+		}
+		token = append(token, r)
+	}
+	flush()
+
+	return tokens
+}
+
+// Normalize 는 s 를 Tokenize 한 단어들을 공백 하나로 이어 붙인다.
+func Normalize(s string) string {
+	return strings.Join(Tokenize(s), " ")
+}
+
+// Distance 는 a 와 b 의 글자 단위 Levenshtein 거리이다.
+// 한글은 Decompose 한 뒤에 비교해야 자모 하나의 오타가 편집 한 번이 된다.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
+
+// PrefixDistance 는 term 과 word 앞부분 사이의 가장 작은 Distance 이다.
+// 입력 중인 검색어("닭가슴")가 긴 이름("닭가슴살")의 앞부분과 비슷한지 볼 때 쓴다.
+func PrefixDistance(term, word string) int {
+	rt, rw := []rune(term), []rune(word)
+
+	best := Distance(term, word)
+	for k := len(rt) - 1; k <= len(rt)+1; k++ {
+		if k < 0 || k > len(rw) {
+			continue
+		}
+		if d := Distance(term, string(rw[:k])); d < best {
+			best = d
+		}
+	}
+
+	return best
+}
+
+// MaxEdits 는 자모 length 개 길이의 검색어에 허용하는 오타 수이다.
+// 짧은 검색어에 오타를 허용하면 엉뚱한 food가 너무 많이 맞는다.
+func MaxEdits(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// FuzzyMatch 는 term 이 word 의 앞부분과 허용된 오타 수 안에서 같은지 확인한다.
+// 두 값 모두 Decompose 한 값이어야 한다.
+func FuzzyMatch(term, word string) bool {
+	maxEdits := MaxEdits(len([]rune(term)))
+	if maxEdits == 0 {
+		return strings.HasPrefix(word, term)
+	}
+
+	return PrefixDistance(term, word) <= maxEdits
+}
@@ -0,0 +1,141 @@
+// Package units 는 nutrient와 식사 기록에 쓰는 양의 단위와 단위 간 변환을 정의한다.
+package units
+
+import (
+	"errors"
+	"strings"
+)
+
+type Unit int32
+
+// 값은 DB에 그대로 저장되므로 순서를 바꾸면 안 된다.
+const (
+	// 단위를 정하기 전에 저장된 값. 그램으로 취급한다.
+	Unknown Unit = iota
+	Gram
+	Milligram
+	Kilogram
+	Ounce
+	Pound
+	Milliliter
+	Liter
+	Cup
+	Tablespoon
+	Teaspoon
+	Piece
+	Serving
+)
+
+type Kind int
+
+const (
+	KindMass Kind = iota + 1
+	KindVolume
+	KindCount
+)
+
+var (
+	ErrUnknownUnit     = errors.New("unknown unit")
+	ErrIncompatible    = errors.New("incompatible units")
+	ErrDensityRequired = errors.New("density is required to convert between mass and volume")
+)
+
+type definition struct {
+	symbol string
+	kind   Kind
+	// 같은 종류의 기준 단위(g, ml, 개)로 바꿀 때 곱하는 값
+	factor float64
+}
+
+// 컵, 큰술, 작은술은 한국 계량 기준(200ml, 15ml, 5ml)을 따른다.
+var definitions = map[Unit]definition{
+	Gram:       {"g", KindMass, 1},
+	Milligram:  {"mg", KindMass, 0.001},
+	Kilogram:   {"kg", KindMass, 1000},
+	Ounce:      {"oz", KindMass, 28.349523125},
+	Pound:      {"lb", KindMass, 453.59237},
+	Milliliter: {"ml", KindVolume, 1},
+	Liter:      {"l", KindVolume, 1000},
+	Cup:        {"cup", KindVolume, 200},
+	Tablespoon: {"tbsp", KindVolume, 15},
+	Teaspoon:   {"tsp", KindVolume, 5},
+	Piece:      {"piece", KindCount, 1},
+	Serving:    {"serving", KindCount, 1},
+}
+
+func (u Unit) normalize() Unit {
+	if u == Unknown {
+		return Gram
+	}
+
+	return u
+}
+
+func IsValid(u Unit) bool {
+	_, ok := definitions[u.normalize()]
+	return ok
+}
+
+func (u Unit) Kind() Kind {
+	return definitions[u.normalize()].kind
+}
+
+func (u Unit) String() string {
+	if d, ok := definitions[u.normalize()]; ok {
+		return d.symbol
+	}
+
+	return "unknown"
+}
+
+// Parse 는 "g", "ml" 같은 단위 기호를 Unit 으로 바꾼다.
+func Parse(symbol string) (Unit, error) {
+	symbol = strings.ToLower(strings.TrimSpace(symbol))
+	for unit, d := range definitions {
+		if d.symbol == symbol {
+			return unit, nil
+		}
+	}
+
+	return Unknown, ErrUnknownUnit
+}
+
+// Convert 는 amount 를 from 단위에서 to 단위로 바꾼다.
+// 질량과 부피 사이의 변환에는 food의 밀도(g/ml)가 필요하다.
+// 개수 단위는 같은 단위끼리만 바꿀 수 있다.
+func Convert(amount float64, from, to Unit, density float64) (float64, error) {
+	from, to = from.normalize(), to.normalize()
+	if from == to {
+		return amount, nil
+	}
+
+	fromDef, ok := definitions[from]
+	if !ok {
+		return 0, ErrUnknownUnit
+	}
+
+	toDef, ok := definitions[to]
+	if !ok {
+		return 0, ErrUnknownUnit
+	}
+
+	if fromDef.kind == KindCount || toDef.kind == KindCount {
+		return 0, ErrIncompatible
+	}
+
+	base := amount * fromDef.factor
+
+	if fromDef.kind != toDef.kind {
+		if density <= 0 {
+			return 0, ErrDensityRequired
+		}
+
+		if fromDef.kind == KindVolume {
+			base = base * density
+		} else {
+			base = base / density
+		}
+	}
+
+	return base / toDef.factor, nil
+}
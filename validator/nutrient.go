@@ -0,0 +1,181 @@
+package validator
+
+import (
+	"fmt"
+	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/units"
+	"math"
+	"sort"
+)
+
+// Atwater 계수 (kcal/g). 식이섬유는 대부분 소화되지 않아서 탄수화물보다 작다.
+const (
+	kcalPerGramCarbohydrate = 4.0
+	kcalPerGramFiber        = 2.0
+	kcalPerGramProtein      = 4.0
+	kcalPerGramFat          = 9.0
+)
+
+const (
+	// 표시 값은 반올림되고 당알코올처럼 계수가 다른 성분도 있으므로 계산한 칼로리와 이 비율만큼은 달라도 된다.
+	AtwaterTolerance = 0.2
+	// 칼로리가 작으면 반올림 차이가 비율로는 커지므로 이만큼은 항상 달라도 된다.
+	atwaterMinDifference = 10.0 // kcal
+	// g 단위로 표시한 값의 반올림 차이
+	gramTolerance = 0.5
+	// 지방 중 트랜스지방의 최대 비율. 부분 경화유로 만든 쇼트닝도 지방의 절반 정도이다.
+	maxTransFatRatio = 0.6
+)
+
+func Food(food models.Food) Errors {
+	var errs Errors
+
+	errs.checkName(food.Name)
+	if food.Weight < 0 {
+		errs.add("Weight", "음수일 수 없습니다.")
+	}
+	if food.Density < 0 {
+		errs.add("Density", "음수일 수 없습니다.")
+	}
+	if food.Abv < 0 || food.Abv > models.MaxAbv {
+		errs.add("Abv", "0에서 100 사이여야 합니다.")
+	}
+
+	return errs
+}
+
+// Nutrient 는 food의 nutrient 값이 올바르고 서로 맞는지 확인한다.
+// 칼로리가 탄수화물, 단백질, 지방, 알코올로 계산한 값과 AtwaterTolerance 이상 다르거나,
+// 당류와 식이섬유가 탄수화물보다 많거나, 영양소가 기준 양(PerUnit)의 무게보다 많으면 잘못된 것이다.
+// 트랜스지방은 포화지방, 불포화지방과 겹치지 않는 따로인 성분이지만 지방의 maxTransFatRatio 보다 많을 수는 없다.
+// 도수만 있고 알코올 양을 모르는 술은 도수로 계산한 알코올 양으로 검증한다. 저장할 값은 호출한 쪽에서 FillAlcohol 로 채운다.
+func Nutrient(food models.Food, n models.Nutrient) Errors {
+	var errs Errors
+
+	if n.PerUnit <= 0 {
+		errs.add("PerUnit", "0보다 커야 합니다.")
+	}
+	if !units.IsValid(n.Unit) {
+		errs.add("Unit", "알 수 없는 단위입니다.")
+	}
+	if n.Calorie < 0 {
+		errs.add("Calorie", "음수일 수 없습니다.")
+	}
+
+	for _, gram := range []struct {
+		field string
+		value float32
+	}{
+		{"Carbohydrate", n.Carbohydrate}, {"Protein", n.Protein}, {"SaturatedFat", n.SaturatedFat},
+		{"UnSaturatedFat", n.UnSaturatedFat}, {"TransFat", n.TransFat},
+	} {
+		if gram.value < 0 {
+			errs.add(gram.field, "음수일 수 없습니다.")
+		}
+	}
+	for _, optional := range []struct {
+		field string
+		value *float32
+	}{
+		{"Sodium", n.Sodium}, {"Sugars", n.Sugars}, {"DietaryFiber", n.DietaryFiber},
+		{"Cholesterol", n.Cholesterol}, {"Alcohol", n.Alcohol},
+	} {
+		if optional.value != nil && *optional.value < 0 {
+			errs.add(optional.field, "음수일 수 없습니다.")
+		}
+	}
+
+	// 값이 잘못되었으면 서로 비교하는 것은 의미가 없다.
+	if len(errs) > 0 || food.Abv < 0 || food.Abv > models.MaxAbv {
+		return errs
+	}
+
+	// n 은 복사한 값이므로 채운 알코올 양은 호출한 쪽에 남지 않는다.
+	switch err := n.FillAlcohol(food); err {
+	case nil:
+	case units.ErrDensityRequired:
+		errs.add("Density", "무게 단위인 술은 도수로 알코올 양을 계산하려면 밀도가 필요합니다.")
+		return errs
+	default:
+		errs.add("Alcohol", "도수로 알코올 양을 계산할 수 없는 단위이므로 알코올 양을 직접 입력해야 합니다.")
+		return errs
+	}
+
+	carbohydrate, fiber := float64(n.Carbohydrate), value(n.DietaryFiber)
+	// 포화지방, 불포화지방, 트랜스지방은 서로 겹치지 않으므로 더한 값이 지방이다.
+	fat := float64(n.SaturatedFat) + float64(n.UnSaturatedFat) + float64(n.TransFat)
+
+	// 탄수화물에는 당류와 식이섬유가 포함된다.
+	if value(n.Sugars) > carbohydrate+gramTolerance {
+		errs.add("Sugars", "탄수화물보다 많을 수 없습니다.")
+	}
+	if fiber > carbohydrate+gramTolerance {
+		errs.add("DietaryFiber", "탄수화물보다 많을 수 없습니다.")
+	}
+	if float64(n.TransFat) > fat*maxTransFatRatio+gramTolerance {
+		errs.add("TransFat", fmt.Sprintf("포화지방, 불포화지방, 트랜스지방을 더한 지방의 %.0f%%보다 많을 수 없습니다.", maxTransFatRatio*100))
+	}
+
+	if mass, ok := perUnitGrams(food, n); ok {
+		// 나트륨과 콜레스테롤은 mg 이다.
+		total := carbohydrate + float64(n.Protein) + fat + value(n.Alcohol) +
+			(value(n.Sodium)+value(n.Cholesterol))/1000
+		if total > mass+gramTolerance {
+			errs.add("PerUnit", fmt.Sprintf("영양소의 합(%.1fg)이 기준 양의 무게(%.1fg)보다 많습니다.", total, mass))
+		}
+	}
+
+	digestible := math.Max(carbohydrate-fiber, 0)
+	expected := digestible*kcalPerGramCarbohydrate + math.Min(fiber, carbohydrate)*kcalPerGramFiber +
+		float64(n.Protein)*kcalPerGramProtein + fat*kcalPerGramFat + value(n.Alcohol)*models.KcalPerGramAlcohol
+	allowed := math.Max(expected*AtwaterTolerance, atwaterMinDifference)
+	if math.Abs(float64(n.Calorie)-expected) > allowed {
+		errs.add("Calorie", fmt.Sprintf("탄수화물, 단백질, 지방, 알코올로 계산한 칼로리(%.0fkcal)와 너무 다릅니다.", expected))
+	}
+
+	return errs
+}
+
+// Micronutrients 는 비타민/무기질 코드와 함량을 확인한다. field 이름은 "Micronutrients.코드" 이다.
+func Micronutrients(amounts map[string]float32) Errors {
+	var errs Errors
+
+	codes := make([]string, 0, len(amounts))
+	for code := range amounts {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		field := "Micronutrients." + code
+		if !models.IsValidMicronutrient(code) {
+			errs.add(field, "알 수 없는 비타민/무기질입니다.")
+		} else if amounts[code] < 0 {
+			errs.add(field, "음수일 수 없습니다.")
+		}
+	}
+
+	return errs
+}
+
+// perUnitGrams 는 nutrient 기준 양의 무게(g)이다. 개수 단위이거나 밀도를 몰라서 무게를 알 수 없으면 false 이다.
+func perUnitGrams(food models.Food, n models.Nutrient) (float64, bool) {
+	if n.Unit.Kind() == units.KindCount {
+		return 0, false
+	}
+
+	grams, err := units.Convert(float64(n.PerUnit), n.Unit, units.Gram, food.Density)
+	if err != nil {
+		return 0, false
+	}
+
+	return grams, true
+}
+
+func value(v *float32) float64 {
+	if v == nil {
+		return 0
+	}
+
+	return float64(*v)
+}
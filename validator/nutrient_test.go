@@ -0,0 +1,94 @@
+package validator
+
+import (
+	"github.com/kernelgarden/diet/models"
+	"github.com/kernelgarden/diet/units"
+	"testing"
+)
+
+func float(v float32) *float32 {
+	return &v
+}
+
+func TestNutrient(t *testing.T) {
+	food := models.Food{Name: "음식"}
+	beer := models.Food{Name: "맥주", Abv: 5}
+
+	tests := []struct {
+		name     string
+		food     models.Food
+		nutrient models.Nutrient
+		want     string
+	}{
+		{"rice", food, models.Nutrient{Carbohydrate: 28, Protein: 2.7, UnSaturatedFat: 0.3, PerUnit: 100, Calorie: 130,
+			Unit: units.Gram}, ""},
+		{"beer by volume", beer, models.Nutrient{Carbohydrate: 3.6, Protein: 0.5, PerUnit: 100, Calorie: 43,
+			Unit: units.Milliliter}, ""},
+		{"beer by weight without density", beer, models.Nutrient{Carbohydrate: 3.6, Protein: 0.5, PerUnit: 100, Calorie: 43,
+			Unit: units.Gram}, "Density"},
+		{"beer by piece", beer, models.Nutrient{Carbohydrate: 12, Protein: 1.6, PerUnit: 1, Calorie: 150,
+			Unit: units.Piece}, "Alcohol"},
+		{"beer by piece with alcohol", beer, models.Nutrient{Carbohydrate: 12, Protein: 1.6, PerUnit: 1, Calorie: 150,
+			Unit: units.Piece, Alcohol: float(13)}, ""},
+		{"trans fat adds to fat", food, models.Nutrient{SaturatedFat: 20, UnSaturatedFat: 10, TransFat: 10, PerUnit: 100,
+			Calorie: 360, Unit: units.Gram}, ""},
+		{"trans fat over fat ratio", food, models.Nutrient{UnSaturatedFat: 1, TransFat: 10, PerUnit: 100, Calorie: 99,
+			Unit: units.Gram}, "TransFat"},
+		{"sugars over carbohydrate", food, models.Nutrient{Carbohydrate: 10, PerUnit: 100, Calorie: 40, Unit: units.Gram,
+			Sugars: float(12)}, "Sugars"},
+		{"fiber over carbohydrate", food, models.Nutrient{Carbohydrate: 10, PerUnit: 100, Calorie: 30, Unit: units.Gram,
+			DietaryFiber: float(12)}, "DietaryFiber"},
+		{"calorie mismatch", food, models.Nutrient{Carbohydrate: 28, Protein: 2.7, UnSaturatedFat: 0.3, PerUnit: 100,
+			Calorie: 300, Unit: units.Gram}, "Calorie"},
+		{"small calorie difference", food, models.Nutrient{Carbohydrate: 1, PerUnit: 100, Calorie: 10, Unit: units.Gram}, ""},
+		{"over per unit weight", food, models.Nutrient{Carbohydrate: 80, Protein: 30, PerUnit: 100, Calorie: 440,
+			Unit: units.Gram}, "PerUnit"},
+		{"count unit has no weight", food, models.Nutrient{Carbohydrate: 200, PerUnit: 1, Calorie: 800, Unit: units.Piece}, ""},
+		{"zero per unit", food, models.Nutrient{Unit: units.Gram}, "PerUnit"},
+		{"unknown unit", food, models.Nutrient{PerUnit: 100, Unit: units.Unit(-1)}, "Unit"},
+		{"negative values", food, models.Nutrient{Protein: -1, PerUnit: 100, Calorie: -1, Unit: units.Gram,
+			Sodium: float(-1)}, "Calorie,Protein,Sodium"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fields(Nutrient(tt.food, tt.nutrient)); got != tt.want {
+				t.Errorf("Nutrient() fields = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNutrientKeepsArgument(t *testing.T) {
+	nutrient := models.Nutrient{Carbohydrate: 3.6, Protein: 0.5, PerUnit: 100, Calorie: 43, Unit: units.Milliliter}
+	if errs := Nutrient(models.Food{Name: "맥주", Abv: 5}, nutrient); len(errs) > 0 {
+		t.Fatalf("Nutrient() = %v, want no errors", errs)
+	}
+
+	// 알코올 양은 검증할 때만 계산하고 저장할 값은 호출한 쪽에서 채운다.
+	if nutrient.Alcohol != nil {
+		t.Errorf("Alcohol = %v, want nil", *nutrient.Alcohol)
+	}
+}
+
+func TestMicronutrients(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts map[string]float32
+		want    string
+	}{
+		{"empty", nil, ""},
+		{"valid", map[string]float32{"vitamin_c": 10, "calcium": 0}, ""},
+		{"unknown code", map[string]float32{"vitamin_z": 1}, "Micronutrients.vitamin_z"},
+		{"sorted by code", map[string]float32{"zinc": -1, "iron": -1, "vitamin_c": 1},
+			"Micronutrients.iron,Micronutrients.zinc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fields(Micronutrients(tt.amounts)); got != tt.want {
+				t.Errorf("Micronutrients() fields = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// Package validator 는 저장하기 전에 입력한 값이 올바르고 서로 맞는지 확인하고, 잘못된 field 마다 이유를 돌려준다.
+// field 이름은 API 입력의 JSON 이름과 같다.
+package validator
+
+import (
+	"github.com/kernelgarden/diet/constant"
+	"github.com/kernelgarden/diet/models"
+	"net/url"
+)
+
+// food, brand 이름의 최대 길이
+const maxNameLength = 64
+
+type Errors []constant.FieldError
+
+func (e *Errors) add(field, message string) {
+	*e = append(*e, constant.FieldError{Field: field, Message: message})
+}
+
+func (e *Errors) checkName(name string) {
+	if name == "" {
+		e.add("Name", "이름이 없습니다.")
+	} else if len([]rune(name)) > maxNameLength {
+		e.add("Name", "이름은 64자를 넘을 수 없습니다.")
+	}
+}
+
+// Brand 는 brand의 이름과 이미지 주소를 확인한다. import 로 만든 brand처럼 이미지 주소는 없어도 된다.
+func Brand(brand models.Brand) Errors {
+	var errs Errors
+
+	errs.checkName(brand.Name)
+	if brand.ImgSrc != "" {
+		if u, err := url.Parse(brand.ImgSrc); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.add("ImgUrl", "http 나 https 주소여야 합니다.")
+		}
+	}
+
+	return errs
+}
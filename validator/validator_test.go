@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"github.com/kernelgarden/diet/models"
+	"strings"
+	"testing"
+)
+
+// fields 는 에러가 난 field 이름을 순서대로 이어 붙인다.
+func fields(errs Errors) string {
+	names := make([]string, 0, len(errs))
+	for _, err := range errs {
+		names = append(names, err.Field)
+	}
+
+	return strings.Join(names, ",")
+}
+
+func TestFood(t *testing.T) {
+	tests := []struct {
+		name string
+		food models.Food
+		want string
+	}{
+		{"valid", models.Food{Name: "쌀밥", Weight: 210}, ""},
+		{"empty name", models.Food{}, "Name"},
+		{"64 runes", models.Food{Name: strings.Repeat("가", 64)}, ""},
+		{"65 runes", models.Food{Name: strings.Repeat("가", 65)}, "Name"},
+		{"negative weight and density", models.Food{Name: "쌀밥", Weight: -1, Density: -1}, "Weight,Density"},
+		{"max abv", models.Food{Name: "에탄올", Abv: models.MaxAbv}, ""},
+		{"abv over 100", models.Food{Name: "맥주", Abv: 101}, "Abv"},
+		{"negative abv", models.Food{Name: "맥주", Abv: -1}, "Abv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fields(Food(tt.food)); got != tt.want {
+				t.Errorf("Food() fields = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrand(t *testing.T) {
+	tests := []struct {
+		name  string
+		brand models.Brand
+		want  string
+	}{
+		{"without image", models.Brand{Name: "오뚜기"}, ""},
+		{"https image", models.Brand{Name: "오뚜기", ImgSrc: "https://example.com/logo.png"}, ""},
+		{"http image", models.Brand{Name: "오뚜기", ImgSrc: "http://example.com/logo.png"}, ""},
+		{"other scheme", models.Brand{Name: "오뚜기", ImgSrc: "ftp://example.com/logo.png"}, "ImgUrl"},
+		{"relative path", models.Brand{Name: "오뚜기", ImgSrc: "logo.png"}, "ImgUrl"},
+		{"no host", models.Brand{Name: "오뚜기", ImgSrc: "https://"}, "ImgUrl"},
+		{"empty name", models.Brand{ImgSrc: "https://example.com/logo.png"}, "Name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fields(Brand(tt.brand)); got != tt.want {
+				t.Errorf("Brand() fields = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}